@@ -0,0 +1,15 @@
+package email
+
+import (
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/errs"
+)
+
+// attachmentsUnsupported refuses emails with attachments for providers that
+// cannot send them yet, so a failover chain moves on to one that can.
+func attachmentsUnsupported(provider string, email dto.Email) error {
+	if len(email.Attachments) == 0 {
+		return nil
+	}
+	return errs.New(provider, errs.ErrUnsupported, "attachments are not supported")
+}
@@ -2,12 +2,21 @@ package email
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	brevo "github.com/getbrevo/brevo-go/lib"
 	logger "github.com/lugondev/go-log"
 	"github.com/lugondev/send-sen/config"
 	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/errs"
+	"github.com/lugondev/send-sen/internal/attach"
+	"github.com/lugondev/send-sen/internal/httpopt"
 	"github.com/samber/lo"
 )
 
@@ -20,7 +29,11 @@ type BrevoAdapter struct {
 }
 
 // NewBrevoAdapter creates a new instance of BrevoAdapter.
-func NewBrevoAdapter(cfg config.BrevoConfig, logger logger.Logger) (*BrevoAdapter, error) {
+func NewBrevoAdapter(cfg config.BrevoConfig, logger logger.Logger, opts ...Option) (*BrevoAdapter, error) {
+	o := httpopt.New(opts, defaultTimeout)
+	if o.BaseURL != "" {
+		cfg.BaseURL = o.BaseURL
+	}
 	if cfg.APIKey == "" {
 		return nil, fmt.Errorf("brevo API key is required")
 	}
@@ -31,6 +44,10 @@ func NewBrevoAdapter(cfg config.BrevoConfig, logger logger.Logger) (*BrevoAdapte
 	brevoCfg := brevo.NewConfiguration()
 	// Configure API key authorization
 	brevoCfg.AddDefaultHeader("api-key", cfg.APIKey)
+	if cfg.BaseURL != "" {
+		brevoCfg.BasePath = strings.TrimRight(cfg.BaseURL, "/")
+	}
+	brevoCfg.HTTPClient = o.HTTPClient(cfg.Timeout)
 	// Create new API client
 	apiClient := brevo.NewAPIClient(brevoCfg)
 
@@ -46,6 +63,12 @@ func NewBrevoAdapter(cfg config.BrevoConfig, logger logger.Logger) (*BrevoAdapte
 
 // SendEmail sends an email using the Brevo API.
 func (a *BrevoAdapter) SendEmail(ctx context.Context, email dto.Email) error {
+	_, err := a.SendEmailWithResult(ctx, email)
+	return err
+}
+
+// SendEmailWithResult sends an email using the Brevo API and returns the message ID assigned by Brevo.
+func (a *BrevoAdapter) SendEmailWithResult(ctx context.Context, email dto.Email) (dto.SendResult, error) {
 	a.logger.Info(ctx, "Attempting to send email via Brevo", map[string]any{
 		"subject": email.Subject,
 		"to":      email.To,
@@ -54,6 +77,7 @@ func (a *BrevoAdapter) SendEmail(ctx context.Context, email dto.Email) error {
 	})
 
 	// Create a new email
+	text, htmlContent := emailContent(email)
 	sendSmtpEmail := brevo.SendSmtpEmail{
 		// Set sender information
 		Sender: &brevo.SendSmtpEmailSender{
@@ -70,22 +94,104 @@ func (a *BrevoAdapter) SendEmail(ctx context.Context, email dto.Email) error {
 		// Set email subject
 		Subject: email.Subject,
 		// Set email content (HTML)
-		HtmlContent: email.Html,
+		HtmlContent: htmlContent,
 		// Optional: Set plain text content for email clients that don't support HTML
-		TextContent: email.Body,
+		TextContent: text,
+		Tags:        email.Tags,
+	}
+	if email.From != "" {
+		name, address, err := parseAddress(email.From)
+		if err != nil {
+			return dto.SendResult{}, err
+		}
+		sendSmtpEmail.Sender = &brevo.SendSmtpEmailSender{Name: name, Email: address}
+	}
+	if email.ReplyTo != "" {
+		name, address, err := parseAddress(email.ReplyTo)
+		if err != nil {
+			return dto.SendResult{}, err
+		}
+		sendSmtpEmail.ReplyTo = &brevo.SendSmtpEmailReplyTo{Name: name, Email: address}
+	}
+	if len(email.Headers) > 0 {
+		sendSmtpEmail.Headers = make(map[string]interface{}, len(email.Headers))
+		for name, value := range email.Headers {
+			sendSmtpEmail.Headers[name] = value
+		}
+	}
+	if len(email.Metadata) > 0 {
+		sendSmtpEmail.Params = make(map[string]interface{}, len(email.Metadata))
+		for key, value := range email.Metadata {
+			sendSmtpEmail.Params[key] = value
+		}
+	}
+
+	// Brevo takes attachments base64 encoded and derives their type from the
+	// file name; it has no content IDs, so inline images arrive as attachments.
+	for _, attachment := range email.Attachments {
+		content, err := attach.Content(attachment, 0)
+		if err != nil {
+			return dto.SendResult{}, err
+		}
+		sendSmtpEmail.Attachment = append(sendSmtpEmail.Attachment, brevo.SendSmtpEmailAttachment{
+			Content: base64.StdEncoding.EncodeToString(content),
+			Name:    attachment.Filename,
+		})
 	}
 
 	// Send the email
 	result, response, err := a.client.TransactionalEmailsApi.SendTransacEmail(ctx, sendSmtpEmail)
 	// Check if there was an error
 	if err != nil {
-		fmt.Printf("Error sending email: %s\n", err.Error())
-		return err
+		err = brevoError(ctx, response, err)
+		a.logger.Error(ctx, "Failed to send email via Brevo API", map[string]any{"error": err})
+		return dto.SendResult{}, err
 	}
 
-	// Print the result
-	fmt.Printf("Email sent successfully! Message ID: %s\n", result.MessageId)
-	fmt.Printf("API Response Status: %s\n", response.Status)
+	a.logger.Info(ctx, "Email sent successfully via Brevo", map[string]any{
+		"subject":    email.Subject,
+		"to":         email.To,
+		"message_id": result.MessageId,
+		"status":     response.Status,
+	})
+	return dto.SendResult{
+		Provider:  "brevo",
+		MessageID: result.MessageId,
+		Accepted:  email.To,
+		Timestamp: time.Now(),
+		Status:    response.Status,
+	}, nil
+}
+
+// brevoErrorResponse is the Brevo API error body.
+type brevoErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
 
-	return nil
+// brevoError maps a failed Brevo SDK call of a send with context ctx onto the
+// provider error taxonomy.
+func brevoError(ctx context.Context, response *http.Response, err error) error {
+	var swaggerErr brevo.GenericSwaggerError
+	if response == nil || !errors.As(err, &swaggerErr) {
+		return errs.Network(ctx, "brevo", err)
+	}
+
+	var body brevoErrorResponse
+	_ = json.Unmarshal(swaggerErr.Body(), &body)
+	if body.Message == "" {
+		body.Message = strings.TrimSpace(string(swaggerErr.Body()))
+	}
+	providerErr := errs.FromStatus("brevo", response.StatusCode, response.Header, body.Code, body.Message)
+	providerErr.Err = err
+	switch body.Code {
+	case "unauthorized", "permission_denied":
+		providerErr.Kind = errs.ErrAuthentication
+	case "invalid_parameter":
+		// Malformed addresses are reported as e.g. "email is not valid in to".
+		if strings.Contains(body.Message, "email is not valid") {
+			providerErr.Kind = errs.ErrInvalidRecipient
+		}
+	}
+	return providerErr
 }
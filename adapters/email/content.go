@@ -0,0 +1,18 @@
+package email
+
+import (
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/mailhtml"
+)
+
+// emailContent returns the plain-text and HTML parts of an email: Body is the
+// text/plain part and Html the text/html part. When only Html is set the text
+// part is generated from it, so plain-text clients get readable text and every
+// provider sends the same alternatives.
+func emailContent(email dto.Email) (text, html string) {
+	text = email.Body
+	if text == "" && email.Html != "" {
+		text = mailhtml.ToText(email.Html)
+	}
+	return text, email.Html
+}
@@ -0,0 +1,27 @@
+package email
+
+import (
+	"fmt"
+	"net/mail"
+	"sort"
+)
+
+// parseAddress splits an address such as "Support <support@example.com>" into
+// its display name and address.
+func parseAddress(address string) (name, email string, err error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid address %q: %w", address, err)
+	}
+	return parsed.Name, parsed.Address, nil
+}
+
+// sortedKeys returns the keys of m in order, so requests are deterministic.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,24 @@
+package email
+
+import (
+	"time"
+
+	"github.com/lugondev/send-sen/internal/httpopt"
+)
+
+// defaultTimeout bounds a provider request when the config sets no timeout.
+const defaultTimeout = 30 * time.Second
+
+// Option customizes the HTTP provider adapters (SendGrid, Brevo, Mailgun and SES).
+type Option = httpopt.Option
+
+var (
+	// WithHTTPClient sends the provider requests through client, e.g. one with a
+	// proxy, client certificates, custom CAs or request logging. The provider
+	// timeout from the config still applies; when the config sets none, the
+	// client's own timeout is kept.
+	WithHTTPClient = httpopt.WithHTTPClient
+	// WithBaseURL overrides the API base URL from the config (the SES endpoint),
+	// e.g. to point the adapter at a local fake.
+	WithBaseURL = httpopt.WithBaseURL
+)
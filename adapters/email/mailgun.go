@@ -0,0 +1,226 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/errs"
+	"github.com/lugondev/send-sen/internal/httpopt"
+)
+
+// Mailgun regional API base URLs.
+const (
+	mailgunBaseURLUS = "https://api.mailgun.net"
+	mailgunBaseURLEU = "https://api.eu.mailgun.net"
+)
+
+// MailgunAdapter implements the port.EmailAdapter interface for sending emails via the Mailgun messages API.
+type MailgunAdapter struct {
+	apiKey      string
+	messagesURL string
+	from        string
+	tags        []string
+	variables   map[string]string
+	client      *http.Client
+	logger      logger.Logger
+	serviceName string
+}
+
+// mailgunResponse is the body returned by the messages API, on success and on failure.
+type mailgunResponse struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+// NewMailgunAdapter creates a new instance of MailgunAdapter.
+func NewMailgunAdapter(cfg config.MailgunConfig, logger logger.Logger, opts ...Option) (*MailgunAdapter, error) {
+	o := httpopt.New(opts, defaultTimeout)
+	if o.BaseURL != "" {
+		cfg.BaseURL = o.BaseURL
+	}
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("mailgun API key is required")
+	}
+	if cfg.Domain == "" {
+		return nil, fmt.Errorf("mailgun domain is required")
+	}
+	if cfg.FromEmail == "" {
+		return nil, fmt.Errorf("mailgun From email address is required")
+	}
+
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		switch strings.ToLower(cfg.Region) {
+		case "", "us":
+			baseURL = mailgunBaseURLUS
+		case "eu":
+			baseURL = mailgunBaseURLEU
+		default:
+			return nil, fmt.Errorf("unsupported mailgun region %q", cfg.Region)
+		}
+	}
+
+	namedLogger := logger.WithFields(map[string]any{
+		"service": "mailgun_email",
+	})
+	adapter := &MailgunAdapter{
+		apiKey:      cfg.APIKey,
+		messagesURL: fmt.Sprintf("%s/v3/%s/messages", baseURL, cfg.Domain),
+		from:        (&mail.Address{Name: cfg.FromName, Address: cfg.FromEmail}).String(),
+		tags:        cfg.Tags,
+		variables:   cfg.Variables,
+		client:      o.HTTPClient(cfg.Timeout),
+		logger:      namedLogger,
+		serviceName: "mailgun_email",
+	}
+	namedLogger.Info(context.Background(), "Mailgun email adapter initialized", map[string]any{
+		"base_url":   baseURL,
+		"domain":     cfg.Domain,
+		"from_email": cfg.FromEmail,
+	})
+	return adapter, nil
+}
+
+// SendEmail sends an email using the Mailgun messages API.
+func (a *MailgunAdapter) SendEmail(ctx context.Context, email dto.Email) error {
+	_, err := a.SendEmailWithResult(ctx, email)
+	return err
+}
+
+// SendEmailWithResult sends an email using the Mailgun messages API and returns the message ID assigned by Mailgun.
+func (a *MailgunAdapter) SendEmailWithResult(ctx context.Context, email dto.Email) (dto.SendResult, error) {
+	a.logger.Info(ctx, "Attempting to send email via Mailgun", map[string]any{
+		"subject": email.Subject,
+		"to":      email.To,
+		"cc":      email.Cc,
+		"bcc":     email.Bcc,
+	})
+
+	if err := attachmentsUnsupported("mailgun", email); err != nil {
+		return dto.SendResult{}, err
+	}
+
+	body, contentType, err := a.buildForm(email)
+	if err != nil {
+		return dto.SendResult{}, fmt.Errorf("failed to build mailgun request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.messagesURL, body)
+	if err != nil {
+		return dto.SendResult{}, fmt.Errorf("failed to create mailgun request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.SetBasicAuth("api", a.apiKey)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		a.logger.Error(ctx, "Mailgun API request failed", map[string]any{"error": err})
+		return dto.SendResult{}, errs.Network(ctx, "mailgun", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var result mailgunResponse
+	_ = json.Unmarshal(respBody, &result)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message := result.Message
+		if message == "" {
+			message = strings.TrimSpace(string(respBody))
+		}
+		providerErr := errs.FromStatus("mailgun", resp.StatusCode, resp.Header, "", message)
+		// Bad recipients are reported as e.g. "to parameter is not a valid address".
+		if resp.StatusCode == http.StatusBadRequest && strings.Contains(message, "not a valid address") {
+			providerErr.Kind = errs.ErrInvalidRecipient
+		}
+		a.logger.Error(ctx, "Failed to send email via Mailgun API", map[string]any{"error": providerErr})
+		return dto.SendResult{}, providerErr
+	}
+
+	a.logger.Info(ctx, "Email sent successfully via Mailgun", map[string]any{
+		"subject":    email.Subject,
+		"to":         email.To,
+		"message_id": result.ID,
+	})
+	return dto.SendResult{
+		Provider:  "mailgun",
+		MessageID: result.ID,
+		Accepted:  recipients(email),
+		Timestamp: time.Now(),
+		Status:    result.Message,
+	}, nil
+}
+
+// ServiceName returns the name of the email service.
+func (a *MailgunAdapter) ServiceName() string {
+	return a.serviceName
+}
+
+// buildForm encodes the email as the multipart/form-data body expected by the messages API.
+func (a *MailgunAdapter) buildForm(email dto.Email) (io.Reader, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	from := a.from
+	if email.From != "" {
+		from = email.From
+	}
+	fields := [][2]string{{"from", from}}
+	for _, to := range email.To {
+		fields = append(fields, [2]string{"to", to})
+	}
+	for _, cc := range email.Cc {
+		fields = append(fields, [2]string{"cc", cc})
+	}
+	for _, bcc := range email.Bcc {
+		fields = append(fields, [2]string{"bcc", bcc})
+	}
+	fields = append(fields, [2]string{"subject", email.Subject})
+	text, htmlContent := emailContent(email)
+	if text != "" {
+		fields = append(fields, [2]string{"text", text})
+	}
+	if htmlContent != "" {
+		fields = append(fields, [2]string{"html", htmlContent})
+	}
+	if email.ReplyTo != "" {
+		fields = append(fields, [2]string{"h:Reply-To", email.ReplyTo})
+	}
+	for _, name := range sortedKeys(email.Headers) {
+		fields = append(fields, [2]string{"h:" + name, email.Headers[name]})
+	}
+	for _, tag := range append(slices.Clone(a.tags), email.Tags...) {
+		fields = append(fields, [2]string{"o:tag", tag})
+	}
+
+	// Message metadata overrides the configured variables with the same name.
+	variables := maps.Clone(a.variables)
+	if variables == nil {
+		variables = make(map[string]string, len(email.Metadata))
+	}
+	maps.Copy(variables, email.Metadata)
+	for _, name := range sortedKeys(variables) {
+		fields = append(fields, [2]string{"v:" + name, variables[name]})
+	}
+
+	for _, field := range fields {
+		if err := w.WriteField(field[0], field[1]); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return &buf, w.FormDataContentType(), nil
+}
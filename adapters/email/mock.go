@@ -6,6 +6,7 @@ import (
 
 	logger "github.com/lugondev/go-log"
 	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/internal/attach"
 )
 
 // MockEmailAdapter is a mock implementation of the port.EmailAdapter interface.
@@ -30,8 +31,17 @@ func NewMockEmailAdapter(logger logger.Logger) *MockEmailAdapter {
 func (a *MockEmailAdapter) SendEmail(ctx context.Context, email dto.Email) error {
 	// Placeholder implementation (remove once real implementation is added)
 	a.logger.Warn(ctx, "Mock SendEmail function is not fully implemented yet.")
-	fmt.Printf("--- MOCK Brevo Send ---\nTo: %v\nCc: %v\nBcc: %v\nSubject: %s\nBody: %s\n-----------------------\n",
-		email.To, email.Cc, email.Bcc, email.Subject, email.Body)
+	text, htmlContent := emailContent(email)
+	fmt.Printf("--- MOCK Brevo Send ---\nTo: %v\nCc: %v\nBcc: %v\nSubject: %s\nBody: %s\nHtml: %s\n",
+		email.To, email.Cc, email.Bcc, email.Subject, text, htmlContent)
+	for _, attachment := range email.Attachments {
+		content, err := attach.Content(attachment, 0)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Attachment: %s (%s, %s, %d bytes)\n", attachment.Filename, attach.ContentType(attachment), attachment.Disposition, len(content))
+	}
+	fmt.Println("-----------------------")
 	// --- End Placeholder ---
 
 	return nil // Return nil for now
@@ -2,27 +2,45 @@ package email
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	logger "github.com/lugondev/go-log"
 	"github.com/lugondev/send-sen/config"
 	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/errs"
+	"github.com/lugondev/send-sen/internal/attach"
+	"github.com/lugondev/send-sen/internal/httpopt"
 
+	"github.com/sendgrid/rest"
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
 )
 
+// sendGridBaseURL is the default SendGrid API host.
+const sendGridBaseURL = "https://api.sendgrid.com"
+
 // SendGridAdapter implements the port.EmailAdapter interface for sending emails via SendGrid.
 type SendGridAdapter struct {
 	apiKey      string
-	client      *sendgrid.Client
+	baseURL     string
+	client      *rest.Client
 	from        *mail.Email
 	logger      logger.Logger
 	serviceName string
 }
 
 // NewSendGridAdapter creates a new instance of SendGridAdapter.
-func NewSendGridAdapter(cfg config.SendGridConfig, logger logger.Logger) (*SendGridAdapter, error) {
+func NewSendGridAdapter(cfg config.SendGridConfig, logger logger.Logger, opts ...Option) (*SendGridAdapter, error) {
+	o := httpopt.New(opts, defaultTimeout)
+	if o.BaseURL != "" {
+		cfg.BaseURL = o.BaseURL
+	}
 	if cfg.APIKey == "" {
 		return nil, fmt.Errorf("SendGrid API key is required")
 	}
@@ -32,11 +50,15 @@ func NewSendGridAdapter(cfg config.SendGridConfig, logger logger.Logger) (*SendG
 	namedLogger := logger.WithFields(map[string]any{
 		"service": "sendgrid_email",
 	})
-	client := sendgrid.NewSendClient(cfg.APIKey)
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = sendGridBaseURL
+	}
 	from := mail.NewEmail(cfg.FromName, cfg.FromEmail)
 	adapter := &SendGridAdapter{
 		apiKey:      cfg.APIKey,
-		client:      client,
+		baseURL:     baseURL,
+		client:      &rest.Client{HTTPClient: o.HTTPClient(cfg.Timeout)},
 		from:        from,
 		logger:      namedLogger,
 		serviceName: "sendgrid_email",
@@ -44,6 +66,7 @@ func NewSendGridAdapter(cfg config.SendGridConfig, logger logger.Logger) (*SendG
 	ctx := context.Background()
 	namedLogger.Info(ctx, "SendGrid email adapter initialized", map[string]any{
 		"service_name": adapter.serviceName,
+		"base_url":     baseURL,
 		"from_email":   cfg.FromEmail,
 		"from_name":    cfg.FromName,
 	})
@@ -52,19 +75,50 @@ func NewSendGridAdapter(cfg config.SendGridConfig, logger logger.Logger) (*SendG
 
 // SendEmail sends an email using the SendGrid API.
 func (a *SendGridAdapter) SendEmail(ctx context.Context, email dto.Email) error {
+	_, err := a.SendEmailWithResult(ctx, email)
+	return err
+}
+
+// SendEmailWithResult sends an email using the SendGrid API and returns the X-Message-Id assigned by SendGrid.
+func (a *SendGridAdapter) SendEmailWithResult(ctx context.Context, email dto.Email) (dto.SendResult, error) {
 	a.logger.Info(ctx, "Attempting to send email via SendGrid", map[string]any{
 		"subject": email.Subject,
 		"to":      email.To,
 		"cc":      email.Cc,
 		"bcc":     email.Bcc,
-		"from":    a.from.Address,
+		"from":    email.From,
 	})
 
 	// Create SendGrid message structure
 	message := mail.NewV3Mail()
 	message.SetFrom(a.from)
+	if email.From != "" {
+		name, address, err := parseAddress(email.From)
+		if err != nil {
+			return dto.SendResult{}, err
+		}
+		message.SetFrom(mail.NewEmail(name, address))
+	}
+	if email.ReplyTo != "" {
+		name, address, err := parseAddress(email.ReplyTo)
+		if err != nil {
+			return dto.SendResult{}, err
+		}
+		message.SetReplyTo(mail.NewEmail(name, address))
+	}
 	message.Subject = email.Subject
 
+	for _, name := range sortedKeys(email.Headers) {
+		message.SetHeader(name, email.Headers[name])
+	}
+	// Tags become categories and metadata custom_args, both reported in event webhooks.
+	if len(email.Tags) > 0 {
+		message.AddCategories(email.Tags...)
+	}
+	for _, key := range sortedKeys(email.Metadata) {
+		message.SetCustomArg(key, email.Metadata[key])
+	}
+
 	// Create personalization block for To, Cc, Bcc
 	p := mail.NewPersonalization()
 
@@ -95,27 +149,101 @@ func (a *SendGridAdapter) SendEmail(ctx context.Context, email dto.Email) error
 
 	message.AddPersonalizations(p)
 
-	htmlContent := email.Body
-	message.AddContent(mail.NewContent("text/html", htmlContent))
+	// SendGrid requires text/plain to come before text/html.
+	text, htmlContent := emailContent(email)
+	if text != "" {
+		message.AddContent(mail.NewContent("text/plain", text))
+	}
+	if htmlContent != "" {
+		message.AddContent(mail.NewContent("text/html", htmlContent))
+	}
+
+	// SendGrid takes attachments base64 encoded; inline images keep their content ID.
+	for _, attachment := range email.Attachments {
+		content, err := attach.Content(attachment, 0)
+		if err != nil {
+			return dto.SendResult{}, err
+		}
+		sendGridAttachment := mail.NewAttachment().
+			SetContent(base64.StdEncoding.EncodeToString(content)).
+			SetType(attach.ContentType(attachment)).
+			SetFilename(attachment.Filename)
+		if attachment.Disposition != "" {
+			sendGridAttachment.SetDisposition(string(attachment.Disposition))
+		}
+		if attachment.ContentID != "" {
+			sendGridAttachment.SetContentID(attachment.ContentID)
+		}
+		message.AddAttachment(sendGridAttachment)
+	}
 
 	// Send the email
-	_, err := a.client.Send(message)
+	request := sendgrid.GetRequest(a.apiKey, "/v3/mail/send", a.baseURL)
+	request.Method = rest.Post
+	request.Body = mail.GetRequestBody(message)
+	response, err := a.client.SendWithContext(ctx, request)
+	if err != nil {
+		err = errs.Network(ctx, "sendgrid", err)
+	} else if response.StatusCode < 200 || response.StatusCode >= 300 {
+		err = sendGridError(response)
+	}
 	if err != nil {
-		a.logger.Error(context.Background(), "Failed to send email via SendGrid API", map[string]any{
+		a.logger.Error(ctx, "Failed to send email via SendGrid API", map[string]any{
 			"error": err,
 		})
-		return fmt.Errorf("sendGrid API error: %w", err)
+		return dto.SendResult{}, err
 	}
+	messageID := http.Header(response.Headers).Get("X-Message-Id")
 	a.logger.Info(ctx, "Email sent successfully via SendGrid", map[string]any{
-		"subject": email.Subject,
-		"to":      email.To,
-		"content": htmlContent,
+		"subject":    email.Subject,
+		"to":         email.To,
+		"message_id": messageID,
 	})
 
-	return nil
+	return dto.SendResult{
+		Provider:  "sendgrid",
+		MessageID: messageID,
+		Accepted:  recipients(email),
+		Timestamp: time.Now(),
+		Status:    strconv.Itoa(response.StatusCode),
+	}, nil
 }
 
 // ServiceName returns the name of the email service.
 func (a *SendGridAdapter) ServiceName() string {
 	return a.serviceName
 }
+
+// sendGridErrorResponse is the v3 API error body.
+type sendGridErrorResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+		Field   string `json:"field"`
+	} `json:"errors"`
+}
+
+// sendGridError maps a failed v3 API response onto the provider error taxonomy.
+func sendGridError(response *rest.Response) error {
+	var body sendGridErrorResponse
+	_ = json.Unmarshal([]byte(response.Body), &body)
+	messages := make([]string, 0, len(body.Errors))
+	for _, e := range body.Errors {
+		messages = append(messages, e.Message)
+	}
+	message := strings.Join(messages, "; ")
+	if message == "" {
+		message = strings.TrimSpace(response.Body)
+	}
+
+	providerErr := errs.FromStatus("sendgrid", response.StatusCode, http.Header(response.Headers), "", message)
+	if response.StatusCode == http.StatusBadRequest {
+		for _, e := range body.Errors {
+			// Malformed addresses are reported against personalizations.N.{to,cc,bcc}.
+			if strings.HasPrefix(e.Field, "personalizations") && strings.Contains(e.Message, "valid address") {
+				providerErr.Kind = errs.ErrInvalidRecipient
+				break
+			}
+		}
+	}
+	return providerErr
+}
@@ -0,0 +1,311 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/errs"
+	"github.com/lugondev/send-sen/internal/httpopt"
+)
+
+const (
+	sesSendEmailPath = "/v2/email/outbound-emails"
+	// sesMaxTagLength is the longest tag name or value SES accepts.
+	sesMaxTagLength = 256
+)
+
+// SESAdapter implements the port.EmailAdapter interface for sending emails via the Amazon SES v2 API.
+type SESAdapter struct {
+	endpoint         string
+	signer           sigV4Signer
+	from             string
+	configurationSet string
+	tags             []sesMessageTag
+	client           *http.Client
+	logger           logger.Logger
+	serviceName      string
+}
+
+// sesSendEmailRequest is the SESv2 SendEmail request body.
+type sesSendEmailRequest struct {
+	FromEmailAddress     string          `json:"FromEmailAddress"`
+	Destination          sesDestination  `json:"Destination"`
+	ReplyToAddresses     []string        `json:"ReplyToAddresses,omitempty"`
+	Content              sesEmailContent `json:"Content"`
+	ConfigurationSetName string          `json:"ConfigurationSetName,omitempty"`
+	EmailTags            []sesMessageTag `json:"EmailTags,omitempty"`
+}
+
+type sesDestination struct {
+	ToAddresses  []string `json:"ToAddresses,omitempty"`
+	CcAddresses  []string `json:"CcAddresses,omitempty"`
+	BccAddresses []string `json:"BccAddresses,omitempty"`
+}
+
+type sesEmailContent struct {
+	Simple sesSimpleMessage `json:"Simple"`
+}
+
+type sesSimpleMessage struct {
+	Subject sesContent  `json:"Subject"`
+	Body    sesBody     `json:"Body"`
+	Headers []sesHeader `json:"Headers,omitempty"`
+}
+
+type sesHeader struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+type sesBody struct {
+	Text *sesContent `json:"Text,omitempty"`
+	Html *sesContent `json:"Html,omitempty"`
+}
+
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+type sesMessageTag struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+// sesSendEmailResponse is the SESv2 SendEmail success body.
+type sesSendEmailResponse struct {
+	MessageId string `json:"MessageId"`
+}
+
+// sesErrorResponse is the SESv2 error body; the error type is also sent in the X-Amzn-ErrorType header.
+type sesErrorResponse struct {
+	Message string `json:"message"`
+}
+
+// NewSESAdapter creates a new instance of SESAdapter.
+func NewSESAdapter(cfg config.SESConfig, logger logger.Logger, opts ...Option) (*SESAdapter, error) {
+	o := httpopt.New(opts, defaultTimeout)
+	if o.BaseURL != "" {
+		cfg.Endpoint = o.BaseURL
+	}
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("SES region is required")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("SES access key ID and secret access key are required")
+	}
+	if cfg.FromEmail == "" {
+		return nil, fmt.Errorf("SES From email address is required")
+	}
+	endpoint := strings.TrimRight(cfg.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://email.%s.amazonaws.com", cfg.Region)
+	}
+
+	// Sort tags so requests (and their signatures) are deterministic.
+	tags := make([]sesMessageTag, 0, len(cfg.Tags))
+	seen := make(map[string]bool, len(cfg.Tags))
+	for _, tag := range cfg.Tags {
+		if !validSESTag(tag.Name) || !validSESTag(tag.Value) {
+			return nil, fmt.Errorf("invalid SES tag %q=%q: names and values need 1 to %d ASCII letters, digits, '_' or '-'", tag.Name, tag.Value, sesMaxTagLength)
+		}
+		if seen[tag.Name] {
+			return nil, fmt.Errorf("duplicate SES tag %q", tag.Name)
+		}
+		seen[tag.Name] = true
+		tags = append(tags, sesMessageTag{Name: tag.Name, Value: tag.Value})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+	namedLogger := logger.WithFields(map[string]any{
+		"service": "ses_email",
+	})
+	adapter := &SESAdapter{
+		endpoint: endpoint,
+		signer: sigV4Signer{
+			accessKeyID:     cfg.AccessKeyID,
+			secretAccessKey: cfg.SecretAccessKey,
+			sessionToken:    cfg.SessionToken,
+			region:          cfg.Region,
+			service:         "ses",
+		},
+		from:             (&mail.Address{Name: cfg.FromName, Address: cfg.FromEmail}).String(),
+		configurationSet: cfg.ConfigurationSet,
+		tags:             tags,
+		client:           o.HTTPClient(cfg.Timeout),
+		logger:           namedLogger,
+		serviceName:      "ses_email",
+	}
+	namedLogger.Info(context.Background(), "SES email adapter initialized", map[string]any{
+		"region":     cfg.Region,
+		"endpoint":   endpoint,
+		"from_email": cfg.FromEmail,
+	})
+	return adapter, nil
+}
+
+// SendEmail sends an email using the SESv2 SendEmail API.
+func (a *SESAdapter) SendEmail(ctx context.Context, email dto.Email) error {
+	_, err := a.SendEmailWithResult(ctx, email)
+	return err
+}
+
+// SendEmailWithResult sends an email using the SESv2 SendEmail API and returns the message ID assigned by SES.
+func (a *SESAdapter) SendEmailWithResult(ctx context.Context, email dto.Email) (dto.SendResult, error) {
+	a.logger.Info(ctx, "Attempting to send email via SES", map[string]any{
+		"subject": email.Subject,
+		"to":      email.To,
+		"cc":      email.Cc,
+		"bcc":     email.Bcc,
+	})
+
+	if err := attachmentsUnsupported("ses", email); err != nil {
+		return dto.SendResult{}, err
+	}
+
+	from := a.from
+	if email.From != "" {
+		from = email.From
+	}
+	request := sesSendEmailRequest{
+		FromEmailAddress: from,
+		Destination: sesDestination{
+			ToAddresses:  email.To,
+			CcAddresses:  email.Cc,
+			BccAddresses: email.Bcc,
+		},
+		Content: sesEmailContent{Simple: sesSimpleMessage{
+			Subject: sesContent{Data: email.Subject, Charset: "UTF-8"},
+		}},
+		ConfigurationSetName: a.configurationSet,
+		EmailTags:            a.messageTags(email),
+	}
+	if email.ReplyTo != "" {
+		request.ReplyToAddresses = []string{email.ReplyTo}
+	}
+	for _, name := range sortedKeys(email.Headers) {
+		request.Content.Simple.Headers = append(request.Content.Simple.Headers, sesHeader{Name: name, Value: email.Headers[name]})
+	}
+	text, htmlContent := emailContent(email)
+	if text != "" {
+		request.Content.Simple.Body.Text = &sesContent{Data: text, Charset: "UTF-8"}
+	}
+	if htmlContent != "" {
+		request.Content.Simple.Body.Html = &sesContent{Data: htmlContent, Charset: "UTF-8"}
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return dto.SendResult{}, fmt.Errorf("failed to encode SES request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint+sesSendEmailPath, bytes.NewReader(payload))
+	if err != nil {
+		return dto.SendResult{}, fmt.Errorf("failed to create SES request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	a.signer.sign(req, payload, time.Now())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		a.logger.Error(ctx, "SES API request failed", map[string]any{"error": err})
+		return dto.SendResult{}, errs.Network(ctx, "ses", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var sesErr sesErrorResponse
+		_ = json.Unmarshal(body, &sesErr)
+		errorType := strings.SplitN(resp.Header.Get("X-Amzn-ErrorType"), ":", 2)[0]
+		providerErr := errs.FromStatus("ses", resp.StatusCode, resp.Header, errorType, sesErr.Message)
+		// Malformed addresses come back as BadRequestException, e.g. "Illegal address".
+		if errorType == "BadRequestException" && strings.Contains(strings.ToLower(sesErr.Message), "address") {
+			providerErr.Kind = errs.ErrInvalidRecipient
+		}
+		a.logger.Error(ctx, "Failed to send email via SES API", map[string]any{"error": providerErr})
+		return dto.SendResult{}, providerErr
+	}
+
+	var result sesSendEmailResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return dto.SendResult{}, fmt.Errorf("failed to decode SES response: %w", err)
+	}
+	a.logger.Info(ctx, "Email sent successfully via SES", map[string]any{
+		"subject":    email.Subject,
+		"to":         email.To,
+		"message_id": result.MessageId,
+	})
+	return dto.SendResult{
+		Provider:  "ses",
+		MessageID: result.MessageId,
+		Accepted:  recipients(email),
+		Timestamp: time.Now(),
+		Status:    resp.Status,
+	}, nil
+}
+
+// messageTags returns the configured tags with the email's metadata as further
+// tags, overriding configured tags with the same name. SES tags are name/value
+// pairs, so plain email tags are sent with the value "true". Email tags and
+// metadata are free-form, so they are sanitized to the characters SES accepts.
+func (a *SESAdapter) messageTags(email dto.Email) []sesMessageTag {
+	if len(email.Tags) == 0 && len(email.Metadata) == 0 {
+		return a.tags
+	}
+	values := make(map[string]string, len(a.tags)+len(email.Tags)+len(email.Metadata))
+	for _, tag := range a.tags {
+		values[tag.Name] = tag.Value
+	}
+	for _, tag := range email.Tags {
+		if name := sesTagToken(tag); name != "" {
+			values[name] = "true"
+		}
+	}
+	for name, value := range email.Metadata {
+		if name = sesTagToken(name); name != "" {
+			values[name] = sesTagToken(value)
+		}
+	}
+
+	tags := make([]sesMessageTag, 0, len(values))
+	for _, name := range sortedKeys(values) {
+		tags = append(tags, sesMessageTag{Name: name, Value: values[name]})
+	}
+	return tags
+}
+
+// ServiceName returns the name of the email service.
+func (a *SESAdapter) ServiceName() string {
+	return a.serviceName
+}
+
+// validSESTag reports whether s is a valid SES tag name or value.
+func validSESTag(s string) bool {
+	return s != "" && sesTagToken(s) == s
+}
+
+// sesTagToken replaces the characters SES does not accept in tag names and
+// values with '_', and truncates the result to sesMaxTagLength.
+func sesTagToken(s string) string {
+	token := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, s)
+	if len(token) > sesMaxTagLength {
+		token = token[:sesMaxTagLength]
+	}
+	return token
+}
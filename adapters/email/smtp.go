@@ -0,0 +1,634 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/errs"
+)
+
+const (
+	smtpEncryptionStartTLS = "starttls"
+	smtpEncryptionTLS      = "tls"
+	smtpEncryptionNone     = "none"
+
+	smtpAuthPlain   = "plain"
+	smtpAuthLogin   = "login"
+	smtpAuthCRAMMD5 = "cram-md5"
+	smtpAuthNone    = "none"
+
+	defaultSMTPMaxIdleConns = 2
+	defaultSMTPIdleTimeout  = 30 * time.Second
+	defaultSMTPDialTimeout  = 10 * time.Second
+)
+
+// SMTPAdapter implements the port.EmailAdapter interface for sending emails through an SMTP relay.
+// Connections are kept alive and reused so bursts of emails do not pay for a new handshake each time.
+type SMTPAdapter struct {
+	addr        string
+	host        string
+	localName   string
+	encryption  string
+	auth        smtp.Auth
+	tlsConfig   *tls.Config
+	from        mail.Address
+	idleTimeout time.Duration
+	timeout     time.Duration
+	maxIdle     int
+	mu          sync.Mutex
+	idle        []*smtpConn
+	closed      bool
+	logger      logger.Logger
+	serviceName string
+}
+
+// smtpConn is a pooled, authenticated SMTP session.
+type smtpConn struct {
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+// NewSMTPAdapter creates a new instance of SMTPAdapter.
+func NewSMTPAdapter(cfg config.SMTPConfig, logger logger.Logger) (*SMTPAdapter, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("SMTP host is required")
+	}
+	if cfg.FromEmail == "" {
+		return nil, fmt.Errorf("SMTP From email address is required")
+	}
+
+	encryption := strings.ToLower(cfg.Encryption)
+	switch encryption {
+	case "", smtpEncryptionStartTLS, smtpEncryptionTLS, smtpEncryptionNone:
+	case "ssl":
+		encryption = smtpEncryptionTLS
+	default:
+		return nil, fmt.Errorf("unsupported SMTP encryption %q", cfg.Encryption)
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+		if encryption == smtpEncryptionTLS {
+			port = 465
+		}
+	}
+
+	auth, err := newSMTPAuth(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	localName := cfg.LocalName
+	if localName == "" {
+		localName = "localhost"
+	}
+	maxIdle := cfg.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = defaultSMTPMaxIdleConns
+	}
+	idleTimeout := cfg.IdleTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = defaultSMTPIdleTimeout
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	namedLogger := logger.WithFields(map[string]any{
+		"service": "smtp_email",
+	})
+	adapter := &SMTPAdapter{
+		addr:       net.JoinHostPort(cfg.Host, strconv.Itoa(port)),
+		host:       cfg.Host,
+		localName:  localName,
+		encryption: encryption,
+		auth:       auth,
+		tlsConfig: &tls.Config{
+			ServerName:         cfg.Host,
+			InsecureSkipVerify: cfg.InsecureSkipVerify,
+		},
+		from:        mail.Address{Name: cfg.FromName, Address: cfg.FromEmail},
+		idleTimeout: idleTimeout,
+		timeout:     timeout,
+		maxIdle:     maxIdle,
+		logger:      namedLogger,
+		serviceName: "smtp_email",
+	}
+	namedLogger.Info(context.Background(), "SMTP email adapter initialized", map[string]any{
+		"addr":       adapter.addr,
+		"encryption": encryption,
+		"from_email": cfg.FromEmail,
+	})
+	return adapter, nil
+}
+
+// SendEmail sends an email through the configured SMTP relay.
+func (a *SMTPAdapter) SendEmail(ctx context.Context, email dto.Email) error {
+	_, err := a.SendEmailWithResult(ctx, email)
+	return err
+}
+
+// SendEmailWithResult sends an email through the configured SMTP relay and returns
+// the generated Message-ID. Recipients refused by the server are reported as
+// rejected as long as at least one recipient was accepted.
+func (a *SMTPAdapter) SendEmailWithResult(ctx context.Context, email dto.Email) (dto.SendResult, error) {
+	a.logger.Info(ctx, "Attempting to send email via SMTP", map[string]any{
+		"subject": email.Subject,
+		"to":      email.To,
+		"cc":      email.Cc,
+		"bcc":     email.Bcc,
+	})
+
+	if err := attachmentsUnsupported("smtp", email); err != nil {
+		return dto.SendResult{}, err
+	}
+
+	from := a.from
+	if email.From != "" {
+		address, err := mail.ParseAddress(email.From)
+		if err != nil {
+			return dto.SendResult{}, fmt.Errorf("invalid From address %q: %w", email.From, err)
+		}
+		from = *address
+	}
+	messageID := newMessageID(from.Address)
+	msg, err := buildMIMEMessage(from, email, messageID, time.Now())
+	if err != nil {
+		return dto.SendResult{}, fmt.Errorf("failed to build SMTP message: %w", err)
+	}
+
+	c, err := a.getConn(ctx)
+	if err != nil {
+		a.logger.Error(ctx, "Failed to connect to SMTP server", map[string]any{"error": err})
+		return dto.SendResult{}, err
+	}
+
+	// Bound the transaction by the configured timeout, and abort blocking network
+	// I/O when the context is cancelled or its deadline passes.
+	deadline := time.Now().Add(a.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = c.conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() {
+		_ = c.conn.SetDeadline(time.Now())
+	})
+
+	accepted, rejected, err := a.deliver(ctx, c.client, from.Address, email, msg)
+	if !stop() || ctx.Err() != nil {
+		a.discard(c)
+		return dto.SendResult{}, fmt.Errorf("smtp send aborted: %w", errors.Join(ctx.Err(), err))
+	}
+	if err != nil {
+		// The session state is unknown after a failure, so do not hand it out again.
+		a.discard(c)
+		a.logger.Error(ctx, "Failed to send email via SMTP", map[string]any{"error": err})
+		return dto.SendResult{}, err
+	}
+
+	_ = c.conn.SetDeadline(time.Time{})
+	a.release(c)
+	a.logger.Info(ctx, "Email sent successfully via SMTP", map[string]any{
+		"subject":    email.Subject,
+		"to":         email.To,
+		"message_id": messageID,
+		"rejected":   rejected,
+	})
+	return dto.SendResult{
+		Provider:  "smtp",
+		MessageID: messageID,
+		Accepted:  accepted,
+		Rejected:  rejected,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// Close closes all idle pooled connections. The adapter must not be used afterwards.
+func (a *SMTPAdapter) Close() error {
+	a.mu.Lock()
+	idle := a.idle
+	a.idle = nil
+	a.closed = true
+	a.mu.Unlock()
+
+	for _, c := range idle {
+		a.quit(c)
+	}
+	return nil
+}
+
+// ServiceName returns the name of the email service.
+func (a *SMTPAdapter) ServiceName() string {
+	return a.serviceName
+}
+
+// deliver runs one MAIL/RCPT/DATA transaction on an established session and returns
+// the accepted and rejected recipients. Permanently refused recipients are skipped;
+// the transaction fails only when none is left.
+func (a *SMTPAdapter) deliver(ctx context.Context, client *smtp.Client, from string, email dto.Email, msg []byte) (accepted, rejected []string, err error) {
+	if err := client.Mail(from); err != nil {
+		return nil, nil, smtpError(ctx, "MAIL FROM", err, errs.ErrRejected)
+	}
+	var rcptErr error
+	for _, rcpt := range recipients(email) {
+		if err := client.Rcpt(rcpt); err != nil {
+			rcptErr = smtpError(ctx, "RCPT TO <"+rcpt+">", err, errs.ErrInvalidRecipient)
+			if !errors.Is(rcptErr, errs.ErrInvalidRecipient) {
+				return nil, nil, rcptErr
+			}
+			rejected = append(rejected, rcpt)
+			continue
+		}
+		accepted = append(accepted, rcpt)
+	}
+	if len(accepted) == 0 && rcptErr != nil {
+		return nil, rejected, rcptErr
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return nil, nil, smtpError(ctx, "DATA", err, errs.ErrRejected)
+	}
+	if _, err := w.Write(msg); err != nil {
+		_ = w.Close()
+		return nil, nil, smtpError(ctx, "write message", err, errs.ErrRejected)
+	}
+	if err := w.Close(); err != nil {
+		return nil, nil, smtpError(ctx, "end of DATA", err, errs.ErrRejected)
+	}
+	return accepted, rejected, nil
+}
+
+// smtpError classifies a failed SMTP step of a send with context ctx. 4xx replies
+// are transient and 5xx replies are permanent failures of the given kind, except
+// 530/534/535 which signal an authentication problem. Connection failures
+// without a reply are transient.
+func smtpError(ctx context.Context, step string, err error, kind error) error {
+	var replyErr *textproto.Error
+	var netErr net.Error
+	switch {
+	case errors.As(err, &replyErr):
+		providerErr := &errs.ProviderError{
+			Provider:   "smtp",
+			Kind:       kind,
+			StatusCode: replyErr.Code,
+			Message:    step + ": " + replyErr.Msg,
+			Err:        err,
+		}
+		switch replyErr.Code {
+		case 530, 534, 535:
+			providerErr.Kind = errs.ErrAuthentication
+		default:
+			if replyErr.Code >= 400 && replyErr.Code < 500 {
+				providerErr.Kind = errs.ErrTransient
+			}
+		}
+		return providerErr
+	case errors.As(err, &netErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return errs.Network(ctx, "smtp", fmt.Errorf("%s: %w", step, err))
+	default:
+		return &errs.ProviderError{Provider: "smtp", Kind: kind, Message: step + ": " + err.Error(), Err: err}
+	}
+}
+
+// getConn returns a live pooled session, or dials a new one.
+func (a *SMTPAdapter) getConn(ctx context.Context) (*smtpConn, error) {
+	for {
+		a.mu.Lock()
+		if a.closed {
+			a.mu.Unlock()
+			return nil, fmt.Errorf("smtp adapter is closed")
+		}
+		if len(a.idle) == 0 {
+			a.mu.Unlock()
+			return a.dial(ctx)
+		}
+		// Take the most recently used session, it is the most likely to still be alive.
+		c := a.idle[len(a.idle)-1]
+		a.idle = a.idle[:len(a.idle)-1]
+		a.mu.Unlock()
+
+		if time.Since(c.lastUsed) > a.idleTimeout {
+			a.quit(c)
+			continue
+		}
+		// The server may have dropped the session while it sat idle.
+		_ = c.conn.SetDeadline(time.Now().Add(defaultSMTPDialTimeout))
+		err := c.client.Noop()
+		_ = c.conn.SetDeadline(time.Time{})
+		if err != nil {
+			a.discard(c)
+			continue
+		}
+		return c, nil
+	}
+}
+
+// dial opens, secures and authenticates a new SMTP session.
+func (a *SMTPAdapter) dial(ctx context.Context) (*smtpConn, error) {
+	timeout := min(a.timeout, defaultSMTPDialTimeout)
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", a.addr)
+	if err != nil {
+		return nil, smtpError(ctx, "dial "+a.addr, err, errs.ErrTransient)
+	}
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = conn.SetDeadline(deadline)
+	// Abort the handshake when the context is cancelled.
+	tcpConn := conn
+	stop := context.AfterFunc(ctx, func() {
+		_ = tcpConn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if a.encryption == smtpEncryptionTLS {
+		tlsConn := tls.Client(conn, a.tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, smtpError(ctx, "TLS handshake", err, errs.ErrRejected)
+		}
+		conn = tlsConn
+	}
+
+	client, err := smtp.NewClient(conn, a.host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, smtpError(ctx, "greeting", err, errs.ErrRejected)
+	}
+	if err := client.Hello(a.localName); err != nil {
+		_ = client.Close()
+		return nil, smtpError(ctx, "EHLO", err, errs.ErrRejected)
+	}
+
+	if a.encryption == smtpEncryptionStartTLS || a.encryption == "" {
+		ok, _ := client.Extension("STARTTLS")
+		if ok {
+			if err := client.StartTLS(a.tlsConfig); err != nil {
+				_ = client.Close()
+				return nil, smtpError(ctx, "STARTTLS", err, errs.ErrRejected)
+			}
+		} else if a.encryption == smtpEncryptionStartTLS {
+			_ = client.Close()
+			return nil, errs.New("smtp", errs.ErrUnsupported, fmt.Sprintf("server %s does not support STARTTLS", a.addr))
+		}
+	}
+
+	if a.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			_ = client.Close()
+			return nil, errs.New("smtp", errs.ErrAuthentication, fmt.Sprintf("server %s does not support AUTH", a.addr))
+		}
+		if err := client.Auth(a.auth); err != nil {
+			_ = client.Close()
+			return nil, smtpError(ctx, "AUTH", err, errs.ErrAuthentication)
+		}
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+	return &smtpConn{conn: conn, client: client}, nil
+}
+
+// release resets the session and returns it to the pool, closing it when the pool is full.
+func (a *SMTPAdapter) release(c *smtpConn) {
+	if err := c.client.Reset(); err != nil {
+		a.discard(c)
+		return
+	}
+	c.lastUsed = time.Now()
+
+	a.mu.Lock()
+	if !a.closed && len(a.idle) < a.maxIdle {
+		a.idle = append(a.idle, c)
+		a.mu.Unlock()
+		return
+	}
+	a.mu.Unlock()
+	a.quit(c)
+}
+
+// quit ends the session politely.
+func (a *SMTPAdapter) quit(c *smtpConn) {
+	_ = c.conn.SetDeadline(time.Now().Add(time.Second))
+	if err := c.client.Quit(); err != nil {
+		_ = c.client.Close()
+	}
+}
+
+// discard drops a session whose state can no longer be trusted.
+func (a *SMTPAdapter) discard(c *smtpConn) {
+	_ = c.client.Close()
+}
+
+// recipients returns every recipient of the email (To, Cc and Bcc), i.e. the SMTP envelope recipients.
+func recipients(email dto.Email) []string {
+	rcpts := make([]string, 0, len(email.To)+len(email.Cc)+len(email.Bcc))
+	rcpts = append(rcpts, email.To...)
+	rcpts = append(rcpts, email.Cc...)
+	rcpts = append(rcpts, email.Bcc...)
+	return rcpts
+}
+
+// ---------- auth ----------
+
+// newSMTPAuth builds the smtp.Auth mechanism selected in the config.
+func newSMTPAuth(cfg config.SMTPConfig) (smtp.Auth, error) {
+	method := strings.ToLower(cfg.AuthMethod)
+	if method == "" {
+		method = smtpAuthNone
+		if cfg.Username != "" {
+			method = smtpAuthPlain
+		}
+	}
+	switch method {
+	case smtpAuthNone:
+		return nil, nil
+	case smtpAuthPlain:
+		return smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host), nil
+	case smtpAuthLogin:
+		return &loginAuth{username: cfg.Username, password: cfg.Password, host: cfg.Host}, nil
+	case smtpAuthCRAMMD5:
+		return smtp.CRAMMD5Auth(cfg.Username, cfg.Password), nil
+	default:
+		return nil, fmt.Errorf("unsupported SMTP auth method %q", cfg.AuthMethod)
+	}
+}
+
+// loginAuth implements the non-standard but widely deployed AUTH LOGIN mechanism.
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+// Start begins the LOGIN exchange, refusing to send credentials over an unencrypted
+// connection except to localhost (mirroring smtp.PlainAuth).
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+// Next answers the server's Username:/Password: challenges.
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:", "username":
+		return []byte(a.username), nil
+	case "password:", "password":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+	}
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
+
+// ---------- MIME ----------
+
+// buildMIMEMessage renders the email as an RFC 5322 message. When it has both a
+// plain-text and an HTML part (see emailContent) the message is multipart/alternative
+// with the plain-text part first.
+func buildMIMEMessage(from mail.Address, email dto.Email, messageID string, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	email.Body, email.Html = emailContent(email)
+
+	headers := [][2]string{
+		{"From", from.String()},
+		{"To", formatAddressList(email.To)},
+	}
+	if len(email.Cc) > 0 {
+		headers = append(headers, [2]string{"Cc", formatAddressList(email.Cc)})
+	}
+	if email.ReplyTo != "" {
+		headers = append(headers, [2]string{"Reply-To", formatAddressList([]string{email.ReplyTo})})
+	}
+	headers = append(headers,
+		[2]string{"Subject", mime.QEncoding.Encode("utf-8", email.Subject)},
+		[2]string{"Date", now.Format(time.RFC1123Z)},
+		[2]string{"Message-ID", messageID},
+	)
+	for _, name := range sortedKeys(email.Headers) {
+		headers = append(headers, [2]string{name, mime.QEncoding.Encode("utf-8", email.Headers[name])})
+	}
+	headers = append(headers, [2]string{"MIME-Version", "1.0"})
+
+	switch {
+	case email.Body != "" && email.Html != "":
+		mw := multipart.NewWriter(&buf)
+		headers = append(headers, [2]string{"Content-Type", "multipart/alternative; boundary=" + mw.Boundary()})
+		writeHeaders(&buf, headers)
+
+		if err := writeTextPart(mw, "text/plain", email.Body); err != nil {
+			return nil, err
+		}
+		if err := writeTextPart(mw, "text/html", email.Html); err != nil {
+			return nil, err
+		}
+		if err := mw.Close(); err != nil {
+			return nil, err
+		}
+	case email.Html != "":
+		headers = append(headers,
+			[2]string{"Content-Type", "text/html; charset=utf-8"},
+			[2]string{"Content-Transfer-Encoding", "quoted-printable"},
+		)
+		writeHeaders(&buf, headers)
+		if err := writeQuotedPrintable(&buf, email.Html); err != nil {
+			return nil, err
+		}
+	default:
+		headers = append(headers,
+			[2]string{"Content-Type", "text/plain; charset=utf-8"},
+			[2]string{"Content-Transfer-Encoding", "quoted-printable"},
+		)
+		writeHeaders(&buf, headers)
+		if err := writeQuotedPrintable(&buf, email.Body); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeHeaders writes the header block in order, followed by the blank separator line.
+func writeHeaders(w io.Writer, headers [][2]string) {
+	for _, header := range headers {
+		fmt.Fprintf(w, "%s: %s\r\n", header[0], header[1])
+	}
+	fmt.Fprint(w, "\r\n")
+}
+
+func writeTextPart(mw *multipart.Writer, contentType, content string) error {
+	part, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType + "; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	return writeQuotedPrintable(part, content)
+}
+
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func formatAddressList(addresses []string) string {
+	formatted := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if parsed, err := mail.ParseAddress(address); err == nil {
+			formatted = append(formatted, parsed.String())
+		} else {
+			formatted = append(formatted, address)
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// newMessageID generates a unique Message-ID in the sender's domain.
+func newMessageID(fromAddress string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(fromAddress, "@"); at >= 0 && at < len(fromAddress)-1 {
+		domain = fromAddress[at+1:]
+	}
+	random := make([]byte, 16)
+	_, _ = rand.Read(random)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain)
+}
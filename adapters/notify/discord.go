@@ -0,0 +1,239 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/errs"
+	"github.com/lugondev/send-sen/internal/httpopt"
+)
+
+const (
+	// Discord embed limits, see https://discord.com/developers/docs/resources/message#embed-object-embed-limits
+	discordMaxTitleLength       = 256
+	discordMaxDescriptionLength = 4096
+)
+
+// discordLevelColors maps notification levels to Discord embed colors (decimal RGB).
+var discordLevelColors = map[dto.Level]int{
+	dto.Debug:   0x9E9E9E,
+	dto.Info:    0x2196F3,
+	dto.Warning: 0xFF9800,
+	dto.Error:   0xF44336,
+}
+
+// DiscordAdapter implements the port.NotifyAdapter interface for Discord webhooks.
+type DiscordAdapter struct {
+	webhookURL  string
+	username    string
+	avatarURL   string
+	client      *http.Client
+	logger      logger.Logger
+	serviceName string
+}
+
+// discordEmbedFooter is the footer block of a Discord embed.
+type discordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// discordEmbed is the subset of Discord's embed object used to render a notification.
+type discordEmbed struct {
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description"`
+	Color       int                `json:"color"`
+	Footer      discordEmbedFooter `json:"footer"`
+	Timestamp   string             `json:"timestamp"`
+}
+
+// discordMessage is the webhook execute payload.
+type discordMessage struct {
+	Username  string         `json:"username,omitempty"`
+	AvatarURL string         `json:"avatar_url,omitempty"`
+	Embeds    []discordEmbed `json:"embeds"`
+}
+
+// discordRateLimit is the body Discord returns alongside HTTP 429.
+type discordRateLimit struct {
+	Message    string  `json:"message"`
+	RetryAfter float64 `json:"retry_after"` // seconds
+	Global     bool    `json:"global"`
+}
+
+// discordMessageResponse is the created message, returned because the webhook is executed with wait=true.
+type discordMessageResponse struct {
+	ID        string `json:"id"`
+	ChannelID string `json:"channel_id"`
+}
+
+// discordErrorResponse is the JSON error body of the Discord API.
+type discordErrorResponse struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// NewDiscordAdapter creates a new instance of DiscordAdapter.
+func NewDiscordAdapter(cfg config.DiscordConfig, logger logger.Logger, opts ...Option) (*DiscordAdapter, error) {
+	o := httpopt.New(opts, defaultTimeout)
+	if cfg.WebhookURL == "" {
+		return nil, fmt.Errorf("discord webhook URL is required")
+	}
+	webhookURL, err := url.Parse(cfg.WebhookURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid discord webhook URL: %w", err)
+	}
+	// wait=true makes Discord confirm the message was created instead of returning 204
+	query := webhookURL.Query()
+	query.Set("wait", "true")
+	webhookURL.RawQuery = query.Encode()
+
+	namedLogger := logger.WithFields(map[string]any{
+		"service": "discord_notify_adapter",
+	})
+	adapter := &DiscordAdapter{
+		webhookURL:  webhookURL.String(),
+		username:    cfg.Username,
+		avatarURL:   cfg.AvatarURL,
+		client:      o.HTTPClient(cfg.Timeout),
+		logger:      namedLogger,
+		serviceName: "discord",
+	}
+	namedLogger.Info(context.Background(), "Discord notify adapter initialized")
+	return adapter, nil
+}
+
+// Send posts a notification to Discord as an embed.
+func (a *DiscordAdapter) Send(ctx context.Context, msg dto.Content) error {
+	_, err := a.SendWithResult(ctx, msg)
+	return err
+}
+
+// SendWithResult posts a notification to Discord as an embed and returns the created message ID.
+func (a *DiscordAdapter) SendWithResult(ctx context.Context, msg dto.Content) (dto.SendResult, error) {
+	color, ok := discordLevelColors[msg.Level]
+	if !ok {
+		color = 0x607D8B
+	}
+	level := msg.Level
+	if level == "" {
+		level = "notification"
+	}
+
+	payload := discordMessage{
+		Username:  a.username,
+		AvatarURL: a.avatarURL,
+		Embeds: []discordEmbed{{
+			Title:       truncate(msg.Subject, discordMaxTitleLength),
+			Description: truncate(msg.Message, discordMaxDescriptionLength),
+			Color:       color,
+			Footer:      discordEmbedFooter{Text: fmt.Sprintf("Level: %s", level)},
+			Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		}},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return dto.SendResult{}, fmt.Errorf("failed to encode discord payload: %w", err)
+	}
+
+	// A 429 is returned as an ErrRateLimited error carrying Discord's wait, so
+	// the retry decorator decides whether to wait it out.
+	created, err := a.post(ctx, body)
+	if err != nil {
+		a.logger.Error(ctx, "discord send failed", map[string]any{"error": err})
+		return dto.SendResult{}, err
+	}
+	a.logger.Debug(ctx, "discord send ok", map[string]any{"message_id": created.ID})
+	return dto.SendResult{
+		Provider:  "discord",
+		MessageID: created.ID,
+		Accepted:  []string{created.ChannelID},
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// post executes the webhook once and returns the created message. When Discord
+// answers 429 the error carries the duration to wait before retrying.
+func (a *DiscordAdapter) post(ctx context.Context, body []byte) (discordMessageResponse, error) {
+	var created discordMessageResponse
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return created, fmt.Errorf("failed to create discord request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return created, errs.Network(ctx, "discord", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		retryAfter := discordRetryAfter(resp.Header, respBody)
+		return created, &errs.ProviderError{
+			Provider:   "discord",
+			Kind:       errs.ErrRateLimited,
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("retry after %s", retryAfter),
+			RetryDelay: retryAfter,
+		}
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return created, discordError(resp, respBody)
+	}
+	_ = json.Unmarshal(respBody, &created)
+	return created, nil
+}
+
+// discordError maps a failed webhook response onto the provider error taxonomy.
+// A deleted webhook answers 404 Unknown Webhook, which is treated as an invalid recipient.
+func discordError(resp *http.Response, body []byte) error {
+	var apiErr discordErrorResponse
+	_ = json.Unmarshal(body, &apiErr)
+	message := apiErr.Message
+	if message == "" {
+		message = strings.TrimSpace(string(body))
+	}
+	code := ""
+	if apiErr.Code != 0 {
+		code = strconv.Itoa(apiErr.Code)
+	}
+
+	providerErr := errs.FromStatus("discord", resp.StatusCode, resp.Header, code, message)
+	if resp.StatusCode == http.StatusNotFound {
+		providerErr.Kind = errs.ErrInvalidRecipient
+	}
+	return providerErr
+}
+
+// discordRetryAfter reads the wait duration from the 429 body, falling back to the Retry-After header.
+func discordRetryAfter(header http.Header, body []byte) time.Duration {
+	var rateLimit discordRateLimit
+	if err := json.Unmarshal(body, &rateLimit); err == nil && rateLimit.RetryAfter > 0 {
+		return time.Duration(rateLimit.RetryAfter * float64(time.Second))
+	}
+	if seconds, err := strconv.ParseFloat(header.Get("Retry-After"), 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	return time.Second
+}
+
+// truncate shortens s to at most limit runes, marking the cut with an ellipsis.
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/lugondev/send-sen/internal/httpopt"
+)
+
+// defaultTimeout bounds a provider request when the config sets no timeout.
+const defaultTimeout = 10 * time.Second
+
+// Option customizes the HTTP notify adapters (Telegram, Slack, Discord and Teams).
+type Option = httpopt.Option
+
+var (
+	// WithHTTPClient sends the provider requests through client, e.g. one with a
+	// proxy, client certificates, custom CAs or request logging. The provider
+	// timeout from the config still applies; when the config sets none, the
+	// client's own timeout is kept.
+	WithHTTPClient = httpopt.WithHTTPClient
+	// WithBaseURL overrides the Telegram Bot API or Slack Web API base URL from the
+	// config, e.g. to point the adapter at a local fake. Webhooks are configured by
+	// their own URL.
+	WithBaseURL = httpopt.WithBaseURL
+)
+
+// contextClient makes an SDK that builds its requests without a context
+// (the Telegram Bot API) send them with the context of the send.
+type contextClient struct {
+	ctx    context.Context
+	client *http.Client
+}
+
+func (c contextClient) Do(req *http.Request) (*http.Response, error) {
+	return c.client.Do(req.WithContext(c.ctx))
+}
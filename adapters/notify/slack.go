@@ -0,0 +1,261 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/errs"
+	"github.com/lugondev/send-sen/internal/httpopt"
+)
+
+const defaultSlackAPIURL = "https://slack.com/api"
+
+// slackErrorKinds maps Slack error codes, returned as the webhook response body or
+// the "error" field of Web API responses, onto failure kinds.
+var slackErrorKinds = map[string]error{
+	"channel_not_found":   errs.ErrInvalidRecipient,
+	"channel_is_archived": errs.ErrInvalidRecipient,
+	"is_archived":         errs.ErrInvalidRecipient,
+	"not_in_channel":      errs.ErrInvalidRecipient,
+	"user_not_found":      errs.ErrInvalidRecipient,
+	"no_service":          errs.ErrInvalidRecipient,
+	"no_active_hooks":     errs.ErrInvalidRecipient,
+	"invalid_auth":        errs.ErrAuthentication,
+	"not_authed":          errs.ErrAuthentication,
+	"invalid_token":       errs.ErrAuthentication,
+	"token_revoked":       errs.ErrAuthentication,
+	"token_expired":       errs.ErrAuthentication,
+	"account_inactive":    errs.ErrAuthentication,
+	"missing_scope":       errs.ErrAuthentication,
+	"ratelimited":         errs.ErrRateLimited,
+	"rate_limited":        errs.ErrRateLimited,
+	"internal_error":      errs.ErrTransient,
+	"fatal_error":         errs.ErrTransient,
+	"service_unavailable": errs.ErrTransient,
+	"request_timeout":     errs.ErrTransient,
+}
+
+// slackLevelColors maps notification levels to Slack attachment colors.
+var slackLevelColors = map[dto.Level]string{
+	dto.Debug:   "#9E9E9E",
+	dto.Info:    "#2196F3",
+	dto.Warning: "#FF9800",
+	dto.Error:   "#F44336",
+}
+
+// SlackAdapter implements the port.NotifyAdapter interface for Slack,
+// using either an incoming webhook or the chat.postMessage Web API.
+type SlackAdapter struct {
+	webhookURL  string
+	botToken    string
+	channelID   string
+	apiURL      string
+	client      *http.Client
+	logger      logger.Logger
+	serviceName string
+}
+
+// slackAttachment is the subset of Slack's (legacy) attachment used to render a colored message.
+type slackAttachment struct {
+	Color    string   `json:"color"`
+	Title    string   `json:"title,omitempty"`
+	Text     string   `json:"text"`
+	Footer   string   `json:"footer,omitempty"`
+	MrkdwnIn []string `json:"mrkdwn_in,omitempty"`
+}
+
+// slackMessage is the request payload shared by webhooks and chat.postMessage.
+type slackMessage struct {
+	Channel     string            `json:"channel,omitempty"`
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+// slackAPIResponse is the response envelope returned by the Slack Web API.
+type slackAPIResponse struct {
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	TS      string `json:"ts,omitempty"` // message timestamp, Slack's message ID
+}
+
+// NewSlackAdapter creates a new instance of SlackAdapter.
+func NewSlackAdapter(cfg config.SlackConfig, logger logger.Logger, opts ...Option) (*SlackAdapter, error) {
+	o := httpopt.New(opts, defaultTimeout)
+	if o.BaseURL != "" {
+		cfg.APIURL = o.BaseURL
+	}
+	if cfg.BotToken == "" && cfg.WebhookURL == "" {
+		return nil, fmt.Errorf("slack webhook URL or bot token is required")
+	}
+	if cfg.BotToken != "" && cfg.ChannelID == "" {
+		return nil, fmt.Errorf("slack channel ID is required when using a bot token")
+	}
+	namedLogger := logger.WithFields(map[string]any{
+		"service": "slack_notify_adapter",
+	})
+	apiURL := strings.TrimRight(cfg.APIURL, "/")
+	if apiURL == "" {
+		apiURL = defaultSlackAPIURL
+	}
+	adapter := &SlackAdapter{
+		webhookURL:  cfg.WebhookURL,
+		botToken:    cfg.BotToken,
+		channelID:   cfg.ChannelID,
+		apiURL:      apiURL,
+		client:      o.HTTPClient(cfg.Timeout),
+		logger:      namedLogger,
+		serviceName: "slack",
+	}
+
+	mode := "webhook"
+	if adapter.botToken != "" {
+		mode = "bot"
+	}
+	namedLogger.Info(context.Background(), "Slack notify adapter initialized", map[string]any{"mode": mode})
+	return adapter, nil
+}
+
+// Send posts a notification to Slack.
+func (a *SlackAdapter) Send(ctx context.Context, msg dto.Content) error {
+	_, err := a.SendWithResult(ctx, msg)
+	return err
+}
+
+// SendWithResult posts a notification to Slack. In bot token mode the result carries
+// the message timestamp (ts), which Slack uses as the message ID; webhooks return none.
+func (a *SlackAdapter) SendWithResult(ctx context.Context, msg dto.Content) (dto.SendResult, error) {
+	color, ok := slackLevelColors[msg.Level]
+	if !ok {
+		color = "#607D8B"
+	}
+
+	title := msg.Subject
+	if title == "" {
+		title = "Notification"
+	}
+	level := msg.Level
+	if level == "" {
+		level = "notification"
+	}
+
+	payload := slackMessage{
+		// Top-level text is used for push notifications and clients without attachment support.
+		Text: escapeSlack(title),
+		Attachments: []slackAttachment{{
+			Color:    color,
+			Title:    escapeSlack(title),
+			Text:     escapeSlack(msg.Message),
+			Footer:   fmt.Sprintf("Level: %s", level),
+			MrkdwnIn: []string{"text"},
+		}},
+	}
+
+	var result dto.SendResult
+	var err error
+	if a.botToken != "" {
+		payload.Channel = a.channelID
+		result, err = a.postMessage(ctx, payload)
+	} else {
+		result, err = a.postWebhook(ctx, payload)
+	}
+	if err != nil {
+		a.logger.Error(ctx, "slack send failed", map[string]any{"error": err})
+		return dto.SendResult{}, err
+	}
+	result.Provider = "slack"
+	result.Timestamp = time.Now()
+	a.logger.Debug(ctx, "slack send ok", map[string]any{"message_id": result.MessageID})
+	return result, nil
+}
+
+// postWebhook sends the payload to an incoming webhook, which answers with a plain-text body.
+func (a *SlackAdapter) postWebhook(ctx context.Context, payload slackMessage) (dto.SendResult, error) {
+	resp, err := a.do(ctx, a.webhookURL, payload)
+	if err != nil {
+		return dto.SendResult{}, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode != http.StatusOK {
+		return dto.SendResult{}, slackError(resp, strings.TrimSpace(string(body)))
+	}
+	return dto.SendResult{Status: strings.TrimSpace(string(body))}, nil
+}
+
+// postMessage sends the payload through chat.postMessage, which reports failures in a JSON envelope.
+func (a *SlackAdapter) postMessage(ctx context.Context, payload slackMessage) (dto.SendResult, error) {
+	resp, err := a.do(ctx, a.apiURL+"/chat.postMessage", payload)
+	if err != nil {
+		return dto.SendResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return dto.SendResult{}, slackError(resp, strings.TrimSpace(string(body)))
+	}
+	var result slackAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return dto.SendResult{}, fmt.Errorf("failed to decode slack API response: %w", err)
+	}
+	if !result.OK {
+		kind, ok := slackErrorKinds[result.Error]
+		if !ok {
+			kind = errs.ErrRejected
+		}
+		return dto.SendResult{}, &errs.ProviderError{Provider: "slack", Kind: kind, Code: result.Error}
+	}
+	return dto.SendResult{
+		MessageID: result.TS,
+		Accepted:  []string{result.Channel},
+		Status:    "ok",
+	}, nil
+}
+
+// do encodes the payload as JSON and POSTs it to the given URL.
+func (a *SlackAdapter) do(ctx context.Context, url string, payload slackMessage) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode slack payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if a.botToken != "" {
+		req.Header.Set("Authorization", "Bearer "+a.botToken)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, errs.Network(ctx, "slack", err)
+	}
+	return resp, nil
+}
+
+// slackError maps a non-200 response onto the provider error taxonomy, refining
+// the status based kind with the Slack error code when it is a known one.
+func slackError(resp *http.Response, code string) error {
+	providerErr := errs.FromStatus("slack", resp.StatusCode, resp.Header, code, "")
+	if kind, ok := slackErrorKinds[code]; ok {
+		providerErr.Kind = kind
+	}
+	return providerErr
+}
+
+// escapeSlack escapes the control characters of Slack's mrkdwn format.
+func escapeSlack(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
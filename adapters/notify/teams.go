@@ -0,0 +1,192 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/errs"
+	"github.com/lugondev/send-sen/internal/httpopt"
+)
+
+// teamsLevelStyles maps notification levels to Adaptive Card container styles.
+var teamsLevelStyles = map[dto.Level]string{
+	dto.Debug:   "emphasis",
+	dto.Info:    "accent",
+	dto.Warning: "warning",
+	dto.Error:   "attention",
+}
+
+// TeamsAdapter implements the port.NotifyAdapter interface for Microsoft Teams webhooks.
+type TeamsAdapter struct {
+	webhookURL  string
+	client      *http.Client
+	logger      logger.Logger
+	serviceName string
+}
+
+// teamsMessage is the webhook payload wrapping a single Adaptive Card attachment.
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	ContentURL  *string      `json:"contentUrl"`
+	Content     adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string         `json:"$schema"`
+	Type    string         `json:"type"`
+	Version string         `json:"version"`
+	Body    []any          `json:"body"`
+	MSTeams map[string]any `json:"msteams,omitempty"`
+}
+
+type adaptiveContainer struct {
+	Type  string              `json:"type"`
+	Style string              `json:"style,omitempty"`
+	Bleed bool                `json:"bleed,omitempty"`
+	Items []adaptiveTextBlock `json:"items"`
+}
+
+type adaptiveTextBlock struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Weight   string `json:"weight,omitempty"`
+	Size     string `json:"size,omitempty"`
+	IsSubtle bool   `json:"isSubtle,omitempty"`
+	Wrap     bool   `json:"wrap"`
+}
+
+// NewTeamsAdapter creates a new instance of TeamsAdapter.
+func NewTeamsAdapter(cfg config.TeamsConfig, logger logger.Logger, opts ...Option) (*TeamsAdapter, error) {
+	o := httpopt.New(opts, defaultTimeout)
+	if cfg.WebhookURL == "" {
+		return nil, fmt.Errorf("teams webhook URL is required")
+	}
+	namedLogger := logger.WithFields(map[string]any{
+		"service": "teams_notify_adapter",
+	})
+	adapter := &TeamsAdapter{
+		webhookURL:  cfg.WebhookURL,
+		client:      o.HTTPClient(cfg.Timeout),
+		logger:      namedLogger,
+		serviceName: "teams",
+	}
+	namedLogger.Info(context.Background(), "Teams notify adapter initialized")
+	return adapter, nil
+}
+
+// Send posts a notification to Microsoft Teams as an Adaptive Card.
+func (a *TeamsAdapter) Send(ctx context.Context, msg dto.Content) error {
+	_, err := a.SendWithResult(ctx, msg)
+	return err
+}
+
+// SendWithResult posts a notification to Microsoft Teams as an Adaptive Card. Teams webhooks do not return a message ID.
+func (a *TeamsAdapter) SendWithResult(ctx context.Context, msg dto.Content) (dto.SendResult, error) {
+	body, err := json.Marshal(buildTeamsMessage(msg))
+	if err != nil {
+		return dto.SendResult{}, fmt.Errorf("failed to encode teams payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return dto.SendResult{}, fmt.Errorf("failed to create teams request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		err = errs.Network(ctx, "teams", err)
+		a.logger.Error(ctx, "teams send failed", map[string]any{"error": err})
+		return dto.SendResult{}, err
+	}
+	defer resp.Body.Close()
+
+	// Workflows answer 202 Accepted, legacy incoming webhooks answer 200 with "1".
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		providerErr := errs.FromStatus("teams", resp.StatusCode, resp.Header, "", strings.TrimSpace(string(respBody)))
+		// A deleted webhook or workflow answers 404.
+		if resp.StatusCode == http.StatusNotFound {
+			providerErr.Kind = errs.ErrInvalidRecipient
+		}
+		a.logger.Error(ctx, "teams send failed", map[string]any{"error": providerErr})
+		return dto.SendResult{}, providerErr
+	}
+	a.logger.Debug(ctx, "teams send ok", nil)
+	return dto.SendResult{
+		Provider:  "teams",
+		Timestamp: time.Now(),
+		Status:    resp.Status,
+	}, nil
+}
+
+// buildTeamsMessage renders the notification into an Adaptive Card with a level-styled header.
+func buildTeamsMessage(msg dto.Content) teamsMessage {
+	style, ok := teamsLevelStyles[msg.Level]
+	if !ok {
+		style = "default"
+	}
+	title := msg.Subject
+	if title == "" {
+		title = "Notification"
+	}
+	level := msg.Level
+	if level == "" {
+		level = "notification"
+	}
+
+	card := adaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body: []any{
+			adaptiveContainer{
+				Type:  "Container",
+				Style: style,
+				Bleed: true,
+				Items: []adaptiveTextBlock{{
+					Type:   "TextBlock",
+					Text:   title,
+					Weight: "Bolder",
+					Size:   "Medium",
+					Wrap:   true,
+				}},
+			},
+			adaptiveTextBlock{
+				Type: "TextBlock",
+				Text: msg.Message,
+				Wrap: true,
+			},
+			adaptiveTextBlock{
+				Type:     "TextBlock",
+				Text:     fmt.Sprintf("Level: %s", level),
+				Size:     "Small",
+				IsSubtle: true,
+				Wrap:     true,
+			},
+		},
+		MSTeams: map[string]any{"width": "Full"},
+	}
+
+	return teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content:     card,
+		}},
+	}
+}
@@ -2,11 +2,17 @@ package notify
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/lugondev/send-sen/config"
 	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/errs"
+	"github.com/lugondev/send-sen/internal/httpopt"
 	"golang.org/x/net/html"
 
 	logger "github.com/lugondev/go-log"
@@ -17,20 +23,30 @@ import (
 // TelegramAdapter implements the port.NotifyAdapter
 type TelegramAdapter struct {
 	bot         *tgbotapi.BotAPI
+	client      *http.Client
 	chatID      int64
 	logger      logger.Logger
 	serviceName string
 }
 
 // NewTelegramAdapter creates a new instance of TelegramAdapter.
-func NewTelegramAdapter(config config.TelegramConfig, logger logger.Logger) (*TelegramAdapter, error) {
+func NewTelegramAdapter(config config.TelegramConfig, logger logger.Logger, opts ...Option) (*TelegramAdapter, error) {
+	o := httpopt.New(opts, defaultTimeout)
+	if o.BaseURL != "" {
+		config.BaseURL = o.BaseURL
+	}
 	if config.BotToken == "" {
 		return nil, fmt.Errorf("telegram bot token is required")
 	}
 	namedLogger := logger.WithFields(map[string]any{
 		"service": "telegram_notify_adapter",
 	})
-	bot, err := tgbotapi.NewBotAPI(config.BotToken)
+	endpoint := tgbotapi.APIEndpoint
+	if config.BaseURL != "" {
+		endpoint = strings.TrimRight(config.BaseURL, "/") + "/bot%s/%s"
+	}
+	client := o.HTTPClient(config.Timeout)
+	bot, err := tgbotapi.NewBotAPIWithClient(config.BotToken, endpoint, client)
 	if err != nil {
 		namedLogger.Error(context.Background(), "Error creating Telegram bot API instance", map[string]any{"error": err})
 		return nil, fmt.Errorf("failed to initialize Telegram bot: %w", err)
@@ -38,6 +54,7 @@ func NewTelegramAdapter(config config.TelegramConfig, logger logger.Logger) (*Te
 	bot.Debug = config.Debug
 	adapter := &TelegramAdapter{
 		bot:         bot,
+		client:      client,
 		logger:      namedLogger,
 		serviceName: "telegram",
 	}
@@ -58,6 +75,12 @@ func NewTelegramAdapter(config config.TelegramConfig, logger logger.Logger) (*Te
 
 // Send a message via Telegram using the library.
 func (a *TelegramAdapter) Send(ctx context.Context, msg dto.Content) error {
+	_, err := a.SendWithResult(ctx, msg)
+	return err
+}
+
+// SendWithResult sends a message via Telegram and returns the ID of the sent message.
+func (a *TelegramAdapter) SendWithResult(ctx context.Context, msg dto.Content) (dto.SendResult, error) {
 	// 1) Fallback parse-mode
 	if msg.ParseMode == "" {
 		msg.ParseMode = tgbotapi.ModeHTML
@@ -90,10 +113,45 @@ func (a *TelegramAdapter) Send(ctx context.Context, msg dto.Content) error {
 	m := tgbotapi.NewMessage(a.chatID, text)
 	m.ParseMode = msg.ParseMode
 
-	if _, err := a.bot.Send(m); err != nil {
+	// The library builds its requests without a context; send them with ctx
+	// through a copy of the bot.
+	bot := *a.bot
+	bot.Client = contextClient{ctx: ctx, client: a.client}
+	sent, err := bot.Send(m)
+	if err != nil {
+		err = telegramError(ctx, err)
 		a.logger.Error(ctx, "telegram send failed", map[string]any{"error": err})
-		return err
+		return dto.SendResult{}, err
+	}
+	a.logger.Debug(ctx, "telegram send ok", map[string]any{"message_id": sent.MessageID})
+	return dto.SendResult{
+		Provider:  "telegram",
+		MessageID: strconv.Itoa(sent.MessageID),
+		Accepted:  []string{strconv.FormatInt(a.chatID, 10)},
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// telegramError maps a Bot API error of a send with context ctx onto the
+// provider error taxonomy.
+// Telegram answers 403 when the bot was blocked or removed from the chat, and
+// 401/404 when the bot token is wrong.
+func telegramError(ctx context.Context, err error) error {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		return errs.Network(ctx, "telegram", err)
+	}
+
+	providerErr := errs.FromStatus("telegram", apiErr.Code, nil, "", apiErr.Message)
+	providerErr.Err = err
+	switch {
+	case apiErr.Code == http.StatusForbidden,
+		apiErr.Code == http.StatusBadRequest && strings.Contains(apiErr.Message, "chat not found"):
+		providerErr.Kind = errs.ErrInvalidRecipient
+	case apiErr.Code == http.StatusNotFound:
+		providerErr.Kind = errs.ErrAuthentication
+	case apiErr.RetryAfter > 0:
+		providerErr.RetryDelay = time.Duration(apiErr.RetryAfter) * time.Second
 	}
-	a.logger.Debug(ctx, "telegram send ok", nil)
-	return nil
+	return providerErr
 }
@@ -2,12 +2,20 @@ package sms
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	brevo "github.com/getbrevo/brevo-go/lib"
 	logger "github.com/lugondev/go-log"
 	"github.com/lugondev/send-sen/config"
 	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/errs"
+	"github.com/lugondev/send-sen/internal/httpopt"
 )
 
 // BrevoAdapter implements the port.SmsAdapter interface for sending SMS via Brevo (formerly SendinBlue).
@@ -19,13 +27,21 @@ type BrevoAdapter struct {
 }
 
 // NewBrevoAdapter creates a new instance of BrevoAdapter.
-func NewBrevoAdapter(cfg config.BrevoConfig, logger logger.Logger) (*BrevoAdapter, error) {
+func NewBrevoAdapter(cfg config.BrevoConfig, logger logger.Logger, opts ...Option) (*BrevoAdapter, error) {
+	o := httpopt.New(opts, defaultTimeout)
+	if o.BaseURL != "" {
+		cfg.BaseURL = o.BaseURL
+	}
 	if cfg.APIKey == "" {
 		return nil, fmt.Errorf("brevo API key is required")
 	}
 	brevoCfg := brevo.NewConfiguration()
 	// Configure API key authorization
 	brevoCfg.AddDefaultHeader("api-key", cfg.APIKey)
+	if cfg.BaseURL != "" {
+		brevoCfg.BasePath = strings.TrimRight(cfg.BaseURL, "/")
+	}
+	brevoCfg.HTTPClient = o.HTTPClient(cfg.Timeout)
 	// Create new API client
 	apiClient := brevo.NewAPIClient(brevoCfg)
 
@@ -41,6 +57,12 @@ func NewBrevoAdapter(cfg config.BrevoConfig, logger logger.Logger) (*BrevoAdapte
 
 // Send sends an SMS using the Brevo API.
 func (a *BrevoAdapter) Send(ctx context.Context, sms dto.SMS) error {
+	_, err := a.SendWithResult(ctx, sms)
+	return err
+}
+
+// SendWithResult sends an SMS using the Brevo API and returns the message ID assigned by Brevo.
+func (a *BrevoAdapter) SendWithResult(ctx context.Context, sms dto.SMS) (dto.SendResult, error) {
 	a.logger.Info(ctx, "Attempting to send SMS via Brevo", map[string]any{
 		"to":      sms.To,
 		"from":    a.cfg.SMSSender,
@@ -57,10 +79,51 @@ func (a *BrevoAdapter) Send(ctx context.Context, sms dto.SMS) error {
 	}
 
 	// Send the SMS
-	if _, _, err := a.client.TransactionalSMSApi.SendTransacSms(ctx, sendTransacSms); err != nil {
-		fmt.Printf("Error sending SMS: %s\n", err.Error())
-		return err
+	result, response, err := a.client.TransactionalSMSApi.SendTransacSms(ctx, sendTransacSms)
+	if err != nil {
+		err = brevoError(ctx, response, err)
+		a.logger.Error(ctx, "Failed to send SMS via Brevo API", map[string]any{"error": err})
+		return dto.SendResult{}, err
+	}
+
+	return dto.SendResult{
+		Provider:  "brevo",
+		MessageID: strconv.FormatInt(result.MessageId, 10),
+		Accepted:  []string{sms.To},
+		Timestamp: time.Now(),
+		Status:    response.Status,
+	}, nil
+}
+
+// brevoErrorResponse is the Brevo API error body.
+type brevoErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// brevoError maps a failed Brevo SDK call of a send with context ctx onto the
+// provider error taxonomy.
+func brevoError(ctx context.Context, response *http.Response, err error) error {
+	var swaggerErr brevo.GenericSwaggerError
+	if response == nil || !errors.As(err, &swaggerErr) {
+		return errs.Network(ctx, "brevo", err)
 	}
 
-	return nil
+	var body brevoErrorResponse
+	_ = json.Unmarshal(swaggerErr.Body(), &body)
+	if body.Message == "" {
+		body.Message = strings.TrimSpace(string(swaggerErr.Body()))
+	}
+	providerErr := errs.FromStatus("brevo", response.StatusCode, response.Header, body.Code, body.Message)
+	providerErr.Err = err
+	switch body.Code {
+	case "unauthorized", "permission_denied":
+		providerErr.Kind = errs.ErrAuthentication
+	case "invalid_parameter":
+		// Malformed or unreachable numbers are reported against the recipient parameter.
+		if strings.Contains(strings.ToLower(body.Message), "recipient") {
+			providerErr.Kind = errs.ErrInvalidRecipient
+		}
+	}
+	return providerErr
 }
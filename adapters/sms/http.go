@@ -0,0 +1,47 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/lugondev/send-sen/internal/httpopt"
+)
+
+// defaultTimeout bounds a provider request when the config sets no timeout.
+const defaultTimeout = 30 * time.Second
+
+// Option customizes the HTTP provider adapters (Twilio, Brevo, Vonage and MessageBird).
+type Option = httpopt.Option
+
+var (
+	// WithHTTPClient sends the provider requests through client, e.g. one with a
+	// proxy, client certificates, custom CAs or request logging. The provider
+	// timeout from the config still applies; when the config sets none, the
+	// client's own timeout is kept.
+	WithHTTPClient = httpopt.WithHTTPClient
+	// WithBaseURL overrides the API base URL from the config, e.g. to point the
+	// adapter at a local fake.
+	WithBaseURL = httpopt.WithBaseURL
+)
+
+// contextTransport makes an SDK that builds its requests without a context
+// (Twilio) send them with the context of the send, and to baseURL when set.
+type contextTransport struct {
+	ctx     context.Context
+	baseURL *url.URL
+	base    http.RoundTripper
+}
+
+func (t contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(t.ctx)
+	if t.baseURL != nil {
+		req.URL.Scheme = t.baseURL.Scheme
+		req.URL.Host = t.baseURL.Host
+		req.URL.Path = t.baseURL.Path + req.URL.Path
+		req.URL.RawPath = ""
+		req.Host = ""
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,178 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/errs"
+	"github.com/lugondev/send-sen/internal/httpopt"
+)
+
+const defaultMessageBirdBaseURL = "https://rest.messagebird.com"
+
+// MessageBirdAdapter implements the port.SMSAdapter interface for sending SMS via the MessageBird REST API.
+type MessageBirdAdapter struct {
+	accessKey  string
+	originator string
+	baseURL    string
+	client     *http.Client
+	logger     logger.Logger
+}
+
+// messageBirdRequest is the create message request body.
+type messageBirdRequest struct {
+	Recipients []string `json:"recipients"`
+	Originator string   `json:"originator"`
+	Body       string   `json:"body"`
+	DataCoding string   `json:"datacoding,omitempty"`
+}
+
+// messageBirdResponse is the create message response body.
+type messageBirdResponse struct {
+	ID         string                `json:"id"`
+	Recipients messageBirdRecipients `json:"recipients"`
+	Errors     []messageBirdError    `json:"errors"`
+}
+
+// messageBirdRecipients reports the delivery status of each recipient.
+type messageBirdRecipients struct {
+	Items []struct {
+		Status string `json:"status"`
+	} `json:"items"`
+}
+
+type messageBirdError struct {
+	Code        int    `json:"code"`
+	Description string `json:"description"`
+	Parameter   string `json:"parameter"`
+}
+
+// NewMessageBirdAdapter creates a new instance of MessageBirdAdapter.
+func NewMessageBirdAdapter(cfg config.MessageBirdConfig, logger logger.Logger, opts ...Option) (*MessageBirdAdapter, error) {
+	o := httpopt.New(opts, defaultTimeout)
+	if o.BaseURL != "" {
+		cfg.BaseURL = o.BaseURL
+	}
+	if cfg.AccessKey == "" {
+		return nil, fmt.Errorf("messagebird access key is required")
+	}
+	if cfg.Originator == "" {
+		return nil, fmt.Errorf("messagebird originator is required")
+	}
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultMessageBirdBaseURL
+	}
+	namedLogger := logger.WithFields(map[string]any{
+		"service": "messagebird_sms",
+	})
+	adapter := &MessageBirdAdapter{
+		accessKey:  cfg.AccessKey,
+		originator: cfg.Originator,
+		baseURL:    baseURL,
+		client:     o.HTTPClient(cfg.Timeout),
+		logger:     namedLogger,
+	}
+	namedLogger.Info(context.Background(), "MessageBird SMS adapter initialized")
+	return adapter, nil
+}
+
+// Send sends an SMS using the MessageBird REST API.
+func (a *MessageBirdAdapter) Send(ctx context.Context, sms dto.SMS) error {
+	_, err := a.SendWithResult(ctx, sms)
+	return err
+}
+
+// SendWithResult sends an SMS using the MessageBird REST API and returns the message ID assigned by MessageBird.
+func (a *MessageBirdAdapter) SendWithResult(ctx context.Context, sms dto.SMS) (dto.SendResult, error) {
+	a.logger.Info(ctx, "Attempting to send SMS via MessageBird", map[string]any{
+		"to":             sms.To,
+		"from":           a.originator,
+		"message_length": len(sms.Message),
+	})
+
+	request := messageBirdRequest{
+		Recipients: []string{strings.TrimPrefix(sms.To, "+")},
+		Originator: a.originator,
+		Body:       sms.Message,
+		// auto switches to unicode only when the body needs it
+		DataCoding: "auto",
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return dto.SendResult{}, fmt.Errorf("failed to encode messagebird request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+"/messages", bytes.NewReader(payload))
+	if err != nil {
+		return dto.SendResult{}, fmt.Errorf("failed to create messagebird request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "AccessKey "+a.accessKey)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		a.logger.Error(ctx, "MessageBird API request failed", map[string]any{"error": err})
+		return dto.SendResult{}, errs.Network(ctx, "messagebird", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var result messageBirdResponse
+	decodeErr := json.Unmarshal(body, &result)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || len(result.Errors) > 0 {
+		err := messageBirdAPIError(resp, body, result.Errors)
+		a.logger.Error(ctx, "Failed to send SMS via MessageBird API", map[string]any{"error": err})
+		return dto.SendResult{}, err
+	}
+	if decodeErr != nil {
+		return dto.SendResult{}, fmt.Errorf("failed to decode messagebird response: %w", decodeErr)
+	}
+
+	a.logger.Info(ctx, "SMS sent successfully via MessageBird", map[string]any{
+		"to":         sms.To,
+		"message_id": result.ID,
+	})
+	sendResult := dto.SendResult{
+		Provider:  "messagebird",
+		MessageID: result.ID,
+		Accepted:  []string{sms.To},
+		Timestamp: time.Now(),
+	}
+	if len(result.Recipients.Items) > 0 {
+		sendResult.Status = result.Recipients.Items[0].Status
+	}
+	return sendResult, nil
+}
+
+// messageBirdAPIError maps a failed create message response onto the provider error taxonomy.
+// See https://developers.messagebird.com/api/#errors.
+func messageBirdAPIError(resp *http.Response, body []byte, apiErrors []messageBirdError) error {
+	if len(apiErrors) == 0 {
+		return errs.FromStatus("messagebird", resp.StatusCode, resp.Header, "", strings.TrimSpace(string(body)))
+	}
+
+	first := apiErrors[0]
+	providerErr := errs.FromStatus("messagebird", resp.StatusCode, resp.Header, strconv.Itoa(first.Code), first.Description)
+	switch {
+	case first.Code == 2: // Request not allowed (incorrect access_key)
+		providerErr.Kind = errs.ErrAuthentication
+	case first.Code == 25: // Not enough balance: the account, not the message, is at fault
+		providerErr.Kind = errs.ErrAuthentication
+	case first.Parameter == "recipient" || first.Parameter == "recipients":
+		providerErr.Kind = errs.ErrInvalidRecipient
+	}
+	return providerErr
+}
@@ -2,43 +2,60 @@ package sms
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	logger "github.com/lugondev/go-log"
 	"github.com/lugondev/send-sen/config"
 	"github.com/lugondev/send-sen/dto"
-	"github.com/twilio/twilio-go"
+	"github.com/lugondev/send-sen/errs"
+	"github.com/lugondev/send-sen/internal/httpopt"
+	twilioClient "github.com/twilio/twilio-go/client"
 	twilioApi "github.com/twilio/twilio-go/rest/api/v2010"
 )
 
 // TwilioAdapter implements the port.SMSAdapter interface for sending SMS via Twilio.
 type TwilioAdapter struct {
-	client *twilio.RestClient
-	cfg    config.TwilioConfig
-	logger logger.Logger
+	cfg     config.TwilioConfig
+	baseURL *url.URL // nil for the SDK's default host
+	client  *http.Client
+	logger  logger.Logger
 }
 
 // NewTwilioAdapter creates a new instance of TwilioAdapter.
-func NewTwilioAdapter(cfg config.TwilioConfig, logger logger.Logger) (*TwilioAdapter, error) {
+func NewTwilioAdapter(cfg config.TwilioConfig, logger logger.Logger, opts ...Option) (*TwilioAdapter, error) {
+	o := httpopt.New(opts, defaultTimeout)
+	if o.BaseURL != "" {
+		cfg.BaseURL = o.BaseURL
+	}
 	if cfg.AccountSid == "" || cfg.AuthToken == "" {
 		return nil, fmt.Errorf("twilio Account SID and Auth Token are required")
 	}
 	if cfg.MessagingSid == "" && cfg.FromNumber == "" {
 		return nil, fmt.Errorf("either Twilio Messaging Service SID or From Number is required")
 	}
+	var baseURL *url.URL
+	if cfg.BaseURL != "" {
+		parsed, err := url.Parse(strings.TrimRight(cfg.BaseURL, "/"))
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid Twilio base URL %q", cfg.BaseURL)
+		}
+		baseURL = parsed
+	}
 	namedLogger := logger.WithFields(map[string]any{
 		"service":     "twilio_sms",
 		"from_number": cfg.FromNumber,
 	})
-	client := twilio.NewRestClientWithParams(twilio.ClientParams{
-		Username: cfg.AccountSid,
-		Password: cfg.AuthToken,
-	})
 	adapter := &TwilioAdapter{
-		client: client,
-		cfg:    cfg,
-		logger: namedLogger,
+		cfg:     cfg,
+		baseURL: baseURL,
+		client:  o.HTTPClient(cfg.Timeout),
+		logger:  namedLogger,
 	}
 	ctx := context.Background()
 	namedLogger.Info(ctx, "Twilio SMS adapter initialized")
@@ -47,6 +64,12 @@ func NewTwilioAdapter(cfg config.TwilioConfig, logger logger.Logger) (*TwilioAda
 
 // Send sends an SMS using the Twilio Messages API.
 func (a *TwilioAdapter) Send(ctx context.Context, sms dto.SMS) error {
+	_, err := a.SendWithResult(ctx, sms)
+	return err
+}
+
+// SendWithResult sends an SMS using the Twilio Messages API and returns the message SID.
+func (a *TwilioAdapter) SendWithResult(ctx context.Context, sms dto.SMS) (dto.SendResult, error) {
 	params := &twilioApi.CreateMessageParams{
 		To:   &sms.To,
 		From: &a.cfg.FromNumber,
@@ -60,31 +83,92 @@ func (a *TwilioAdapter) Send(ctx context.Context, sms dto.SMS) error {
 	})
 
 	// Send the message
-	resp, err := a.client.Api.CreateMessage(params)
+	requestCtx, cancel := context.WithTimeout(ctx, a.client.Timeout)
+	defer cancel()
+	resp, err := a.api(requestCtx).CreateMessage(params)
 	if err != nil {
-		// Handle specific Twilio errors if possible (e.g., using url.Error)
-		if urlErr, ok := err.(*url.Error); ok {
-			a.logger.Error(context.Background(), "Twilio API request network error", map[string]any{"error": urlErr})
-			return fmt.Errorf("twilio network error: %w", err)
-		}
-		// Generic error handling
-		a.logger.Error(context.Background(), "Failed to send SMS via Twilio API", map[string]any{"error": err})
-		return fmt.Errorf("twilio API error: %w", err)
+		err = twilioError(ctx, err)
+		a.logger.Error(ctx, "Failed to send SMS via Twilio API", map[string]any{"error": err})
+		return dto.SendResult{}, err
 	}
 
 	// Check response details (resp will be nil on error)
 	// Twilio library handles standard success/failure via the error return.
-	// We can log the SID of the created message for tracking.
+	// The SID of the created message is returned for tracking.
+	result := dto.SendResult{
+		Provider:  "twilio",
+		Accepted:  []string{sms.To},
+		Timestamp: time.Now(),
+	}
 	if resp != nil && resp.Sid != nil {
+		result.MessageID = *resp.Sid
+		if resp.Status != nil {
+			result.Status = *resp.Status
+		}
 		a.logger.Info(ctx, "SMS sent successfully via Twilio", map[string]any{
 			"to":          sms.To,
-			"message_sid": *resp.Sid,
-			"status":      *resp.Status,
+			"message_sid": result.MessageID,
+			"status":      result.Status,
 		})
 	} else {
 		// Should not happen if err is nil, but log just in case
 		a.logger.Warn(ctx, "Twilio API call returned nil error but also nil response/SID")
 	}
 
-	return nil
+	return result, nil
+}
+
+// api returns a Messages API client whose requests carry ctx. The SDK builds
+// its requests without a context, so it gets a copy of the HTTP client whose
+// transport adds it; ctx carries the timeout too.
+func (a *TwilioAdapter) api(ctx context.Context) *twilioApi.ApiService {
+	httpClient := *a.client
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	httpClient.Transport = contextTransport{ctx: ctx, baseURL: a.baseURL, base: base}
+	httpClient.Timeout = 0
+	// Like the SDK's default client, return redirects instead of following them.
+	httpClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	client := &twilioClient.Client{
+		Credentials: twilioClient.NewCredentials(a.cfg.AccountSid, a.cfg.AuthToken),
+		HTTPClient:  &httpClient,
+	}
+	client.SetAccountSid(a.cfg.AccountSid)
+	return twilioApi.NewApiServiceWithClient(client)
+}
+
+// Twilio error codes that identify an unusable destination number.
+// See https://www.twilio.com/docs/api/errors.
+var twilioInvalidRecipientCodes = map[int]bool{
+	21211: true, // Invalid 'To' phone number
+	21214: true, // 'To' phone number cannot be reached
+	21217: true, // Phone number does not appear to be valid
+	21401: true, // Invalid phone number
+	21610: true, // Recipient has unsubscribed (replied STOP)
+	21614: true, // 'To' number is not a valid mobile number
+}
+
+// twilioError maps a Twilio SDK error of a send with context ctx onto the
+// provider error taxonomy.
+func twilioError(ctx context.Context, err error) error {
+	var restErr *twilioClient.TwilioRestError
+	if !errors.As(err, &restErr) {
+		return errs.Network(ctx, "twilio", err)
+	}
+
+	providerErr := errs.FromStatus("twilio", restErr.Status, nil, strconv.Itoa(restErr.Code), restErr.Message)
+	providerErr.Err = err
+	switch {
+	case twilioInvalidRecipientCodes[restErr.Code]:
+		providerErr.Kind = errs.ErrInvalidRecipient
+	case restErr.Code == 20003:
+		providerErr.Kind = errs.ErrAuthentication
+	case restErr.Code == 20429 || restErr.Code == 14107:
+		providerErr.Kind = errs.ErrRateLimited
+	}
+	return providerErr
 }
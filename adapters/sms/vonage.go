@@ -0,0 +1,211 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	logger "github.com/lugondev/go-log"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/errs"
+	"github.com/lugondev/send-sen/internal/httpopt"
+)
+
+const defaultVonageBaseURL = "https://rest.nexmo.com"
+
+// VonageAdapter implements the port.SMSAdapter interface for sending SMS via the Vonage (formerly Nexmo) SMS API.
+type VonageAdapter struct {
+	apiKey    string
+	apiSecret string
+	from      string
+	baseURL   string
+	client    *http.Client
+	logger    logger.Logger
+}
+
+// vonageRequest is the SMS API request body.
+type vonageRequest struct {
+	APIKey    string `json:"api_key"`
+	APISecret string `json:"api_secret"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Text      string `json:"text"`
+	Type      string `json:"type,omitempty"`
+}
+
+// vonageResponse is the SMS API response body. The HTTP status is 200 even
+// when sending failed; the per-message status carries the outcome.
+type vonageResponse struct {
+	MessageCount string          `json:"message-count"`
+	Messages     []vonageMessage `json:"messages"`
+}
+
+type vonageMessage struct {
+	To        string `json:"to"`
+	MessageID string `json:"message-id"`
+	Status    string `json:"status"`
+	ErrorText string `json:"error-text"`
+}
+
+// NewVonageAdapter creates a new instance of VonageAdapter.
+func NewVonageAdapter(cfg config.VonageConfig, logger logger.Logger, opts ...Option) (*VonageAdapter, error) {
+	o := httpopt.New(opts, defaultTimeout)
+	if o.BaseURL != "" {
+		cfg.BaseURL = o.BaseURL
+	}
+	if cfg.APIKey == "" || cfg.APISecret == "" {
+		return nil, fmt.Errorf("vonage API key and secret are required")
+	}
+	if cfg.From == "" {
+		return nil, fmt.Errorf("vonage sender ('from') is required")
+	}
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultVonageBaseURL
+	}
+	namedLogger := logger.WithFields(map[string]any{
+		"service": "vonage_sms",
+	})
+	adapter := &VonageAdapter{
+		apiKey:    cfg.APIKey,
+		apiSecret: cfg.APISecret,
+		from:      cfg.From,
+		baseURL:   baseURL,
+		client:    o.HTTPClient(cfg.Timeout),
+		logger:    namedLogger,
+	}
+	namedLogger.Info(context.Background(), "Vonage SMS adapter initialized")
+	return adapter, nil
+}
+
+// Send sends an SMS using the Vonage SMS API.
+func (a *VonageAdapter) Send(ctx context.Context, sms dto.SMS) error {
+	_, err := a.SendWithResult(ctx, sms)
+	return err
+}
+
+// SendWithResult sends an SMS using the Vonage SMS API and returns the ID of the first message part.
+func (a *VonageAdapter) SendWithResult(ctx context.Context, sms dto.SMS) (dto.SendResult, error) {
+	a.logger.Info(ctx, "Attempting to send SMS via Vonage", map[string]any{
+		"to":             sms.To,
+		"from":           a.from,
+		"message_length": len(sms.Message),
+	})
+
+	request := vonageRequest{
+		APIKey:    a.apiKey,
+		APISecret: a.apiSecret,
+		From:      a.from,
+		// Vonage expects E.164 numbers without the leading '+'
+		To:   strings.TrimPrefix(sms.To, "+"),
+		Text: sms.Message,
+	}
+	if !isGSM7(sms.Message) {
+		request.Type = "unicode"
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return dto.SendResult{}, fmt.Errorf("failed to encode vonage request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+"/sms/json", bytes.NewReader(payload))
+	if err != nil {
+		return dto.SendResult{}, fmt.Errorf("failed to create vonage request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		a.logger.Error(ctx, "Vonage API request failed", map[string]any{"error": err})
+		return dto.SendResult{}, errs.Network(ctx, "vonage", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode != http.StatusOK {
+		err := errs.FromStatus("vonage", resp.StatusCode, resp.Header, "", strings.TrimSpace(string(body)))
+		a.logger.Error(ctx, "Failed to send SMS via Vonage API", map[string]any{"error": err})
+		return dto.SendResult{}, err
+	}
+
+	var result vonageResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return dto.SendResult{}, fmt.Errorf("failed to decode vonage response: %w", err)
+	}
+	if len(result.Messages) == 0 {
+		return dto.SendResult{}, fmt.Errorf("vonage API returned no messages")
+	}
+
+	// Long texts are split into several parts; every part must be accepted.
+	for _, message := range result.Messages {
+		if message.Status != "0" {
+			err := &errs.ProviderError{
+				Provider: "vonage",
+				Kind:     vonageStatusKind(message.Status),
+				Code:     message.Status,
+				Message:  message.ErrorText,
+			}
+			a.logger.Error(ctx, "Failed to send SMS via Vonage API", map[string]any{"error": err})
+			return dto.SendResult{}, err
+		}
+	}
+
+	messageID := result.Messages[0].MessageID
+	a.logger.Info(ctx, "SMS sent successfully via Vonage", map[string]any{
+		"to":         sms.To,
+		"message_id": messageID,
+		"parts":      len(result.Messages),
+	})
+	return dto.SendResult{
+		Provider:  "vonage",
+		MessageID: messageID,
+		Accepted:  []string{sms.To},
+		Timestamp: time.Now(),
+		Status:    result.Messages[0].Status,
+	}, nil
+}
+
+// vonageStatusKinds maps the per-message status codes of the SMS API onto failure kinds.
+// See https://developer.vonage.com/en/messaging/sms/guides/troubleshooting-sms.
+var vonageStatusKinds = map[string]error{
+	"1":  errs.ErrRateLimited,      // Throttled
+	"4":  errs.ErrAuthentication,   // Invalid Credentials
+	"5":  errs.ErrTransient,        // Internal Error
+	"7":  errs.ErrInvalidRecipient, // Number Barred
+	"8":  errs.ErrAuthentication,   // Partner Account Barred
+	"9":  errs.ErrAuthentication,   // Partner Quota Violation: not enough credit
+	"10": errs.ErrRateLimited,      // Too Many Existing Binds
+	"11": errs.ErrAuthentication,   // Account Not Enabled For HTTP
+	"14": errs.ErrAuthentication,   // Invalid Signature
+	"15": errs.ErrAuthentication,   // Invalid Sender Address: not allowed for this account
+	"29": errs.ErrInvalidRecipient, // Non-Whitelisted Destination
+	"32": errs.ErrAuthentication,   // Signature And API Secret Disallowed
+	"33": errs.ErrInvalidRecipient, // Number De-activated
+}
+
+// vonageStatusKind returns the failure kind for a non-zero message status. Account
+// problems map to ErrAuthentication so a failover chain moves on; the remaining
+// statuses are about the message itself and are permanent rejections.
+func vonageStatusKind(status string) error {
+	if kind, ok := vonageStatusKinds[status]; ok {
+		return kind
+	}
+	return errs.ErrRejected
+}
+
+// isGSM7 reports whether s can be sent with the default GSM 03.38 alphabet (approximated as printable ASCII).
+func isGSM7(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package sen
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/internal/attach"
+)
+
+// Default attachment limits, used for zero fields of config.AttachmentConfig.
+const (
+	DefaultMaxAttachmentSize      = 10 << 20
+	DefaultMaxTotalAttachmentSize = 25 << 20
+)
+
+// ErrAttachmentTooLarge is returned by SendEmail when an attachment, or all of
+// them together, exceed the cfg.Attachments limits.
+var ErrAttachmentTooLarge = errors.New("attachment too large")
+
+func newAttachmentLimits(cfg config.AttachmentConfig) config.AttachmentConfig {
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = DefaultMaxAttachmentSize
+	}
+	if cfg.MaxTotalSize <= 0 {
+		cfg.MaxTotalSize = DefaultMaxTotalAttachmentSize
+	}
+	return cfg
+}
+
+// AttachmentPreparer is implemented by the email service. PrepareAttachments
+// runs the attachment checks of SendEmail with the service's limits, so emails
+// held for later, e.g. in an outbox, can be refused up front.
+type AttachmentPreparer interface {
+	PrepareAttachments(attachments []dto.Attachment) ([]dto.Attachment, error)
+}
+
+// PrepareAttachments runs the attachment checks of SendEmail against limits,
+// using the default limits for zero fields, and returns a copy with every
+// Reader read into Content.
+func PrepareAttachments(attachments []dto.Attachment, limits config.AttachmentConfig) ([]dto.Attachment, error) {
+	return prepareAttachments(attachments, newAttachmentLimits(limits))
+}
+
+// prepareAttachments validates the attachments of an email against limits and
+// returns a copy with every Reader read into Content and every ContentType and
+// Disposition set, so retries and failover resend the same bytes and adapters
+// do not have to guess.
+func prepareAttachments(attachments []dto.Attachment, limits config.AttachmentConfig) ([]dto.Attachment, error) {
+	if len(attachments) == 0 {
+		return attachments, nil
+	}
+
+	prepared := make([]dto.Attachment, len(attachments))
+	var total int64
+	for i, attachment := range attachments {
+		if attachment.Filename == "" {
+			return nil, fmt.Errorf("attachment %d must have a filename", i)
+		}
+		switch attachment.Disposition {
+		case "":
+			attachment.Disposition = dto.DispositionAttachment
+		case dto.DispositionAttachment:
+		case dto.DispositionInline:
+			if attachment.ContentID == "" {
+				return nil, fmt.Errorf("inline attachment %s must have a content ID", attachment.Filename)
+			}
+		default:
+			return nil, fmt.Errorf("attachment %s has unknown disposition %q", attachment.Filename, attachment.Disposition)
+		}
+		attachment.ContentType = attach.ContentType(attachment)
+
+		// Read one byte past the limit to detect oversized content without buffering it all.
+		content, err := attach.Content(attachment, limits.MaxSize+1)
+		if err != nil {
+			return nil, err
+		}
+		attachment.Content = content
+		attachment.Reader = nil
+
+		size := int64(len(attachment.Content))
+		if size > limits.MaxSize {
+			return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrAttachmentTooLarge, attachment.Filename, limits.MaxSize)
+		}
+		total += size
+		if total > limits.MaxTotalSize {
+			return nil, fmt.Errorf("%w: attachments exceed %d bytes in total", ErrAttachmentTooLarge, limits.MaxTotalSize)
+		}
+		prepared[i] = attachment
+	}
+	return prepared, nil
+}
@@ -0,0 +1,346 @@
+package sen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+)
+
+// Default breaker policy values, used for zero fields of config.BreakerConfig.
+const (
+	defaultBreakerMinRequests      = 10
+	defaultBreakerWindow           = time.Minute
+	defaultBreakerOpenTimeout      = 30 * time.Second
+	defaultBreakerHalfOpenRequests = 1
+)
+
+// ErrCircuitOpen is matched by errors from a provider whose circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// CircuitOpenError is returned without calling the provider while its circuit is open.
+type CircuitOpenError struct {
+	// Provider is the adapter whose circuit is open.
+	Provider string
+	// RetryDelay is the time left until the breaker lets a probe through.
+	RetryDelay time.Duration
+}
+
+// Error implements error.
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: circuit open, next probe in %s", e.Provider, e.RetryDelay.Round(time.Millisecond))
+}
+
+// Unwrap makes the error match ErrCircuitOpen.
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// Temporary reports that the provider may accept the message once the circuit closes.
+func (e *CircuitOpenError) Temporary() bool {
+	return true
+}
+
+// RetryAfter returns the time left until the breaker lets a probe through.
+func (e *CircuitOpenError) RetryAfter() time.Duration {
+	return e.RetryDelay
+}
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets every send through and counts failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails every send fast until the open timeout elapses.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe sends through.
+	CircuitHalfOpen
+)
+
+// String returns "closed", "open" or "half-open".
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// circuitBreaker tracks a provider's health. Only transient failures (timeouts,
+// network errors, 5xx) count against it: rejected messages, invalid recipients
+// and rate limits say nothing about the provider being down. Sends abandoned
+// by their caller are not counted at all; provider timeouts are transient
+// failures (see errs.Network) and do count.
+type circuitBreaker struct {
+	provider         string
+	failureRatio     float64
+	minRequests      int
+	window           time.Duration
+	openTimeout      time.Duration
+	halfOpenRequests int
+	logger           logger.Logger
+
+	mu          sync.Mutex
+	state       CircuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int // Probes in flight while half-open
+	successes   int // Successful probes while half-open
+}
+
+func newCircuitBreaker(provider string, cfg config.BreakerConfig, logger logger.Logger) *circuitBreaker {
+	b := &circuitBreaker{
+		provider:         provider,
+		failureRatio:     cfg.FailureRatio,
+		minRequests:      cfg.MinRequests,
+		window:           cfg.Window,
+		openTimeout:      cfg.OpenTimeout,
+		halfOpenRequests: cfg.HalfOpenRequests,
+		logger: logger.WithFields(map[string]any{
+			"service":  "circuit_breaker",
+			"provider": provider,
+		}),
+	}
+	if b.minRequests <= 0 {
+		b.minRequests = defaultBreakerMinRequests
+	}
+	if b.window <= 0 {
+		b.window = defaultBreakerWindow
+	}
+	if b.openTimeout <= 0 {
+		b.openTimeout = defaultBreakerOpenTimeout
+	}
+	if b.halfOpenRequests <= 0 {
+		b.halfOpenRequests = defaultBreakerHalfOpenRequests
+	}
+	return b
+}
+
+// State returns the current state, moving an open circuit to half-open once its timeout has elapsed.
+func (b *circuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(time.Now())
+	return b.state
+}
+
+// run calls send if the circuit allows it and records the outcome.
+func (b *circuitBreaker) run(ctx context.Context, send func() error) error {
+	if err := b.acquire(); err != nil {
+		return err
+	}
+	err := send()
+	b.record(ctx, err)
+	return err
+}
+
+func (b *circuitBreaker) acquire() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.advance(now)
+
+	switch b.state {
+	case CircuitOpen:
+		return &CircuitOpenError{Provider: b.provider, RetryDelay: b.openedAt.Add(b.openTimeout).Sub(now)}
+	case CircuitHalfOpen:
+		if b.probes >= b.halfOpenRequests {
+			return &CircuitOpenError{Provider: b.provider}
+		}
+		b.probes++
+	}
+	return nil
+}
+
+func (b *circuitBreaker) record(ctx context.Context, err error) {
+	failed := IsTransient(err) && !errors.Is(err, ErrRateLimited)
+	// A send cut short by its caller says nothing about the provider, either
+	// way: it only gives back its probe slot.
+	abandoned := ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+
+	switch b.state {
+	case CircuitHalfOpen:
+		b.probes--
+		if abandoned {
+			return
+		}
+		if failed {
+			b.open(ctx, now)
+			return
+		}
+		b.successes++
+		if b.successes >= b.halfOpenRequests {
+			b.logger.Info(ctx, "Circuit closed, provider recovered")
+			b.state = CircuitClosed
+			b.resetWindow(now)
+		}
+	case CircuitClosed:
+		if abandoned {
+			return
+		}
+		if now.Sub(b.windowStart) >= b.window {
+			b.resetWindow(now)
+		}
+		b.requests++
+		if failed {
+			b.failures++
+		}
+		if b.requests >= b.minRequests && float64(b.failures)/float64(b.requests) >= b.failureRatio {
+			b.open(ctx, now)
+		}
+	}
+}
+
+// advance moves an open circuit to half-open once the open timeout has elapsed.
+func (b *circuitBreaker) advance(now time.Time) {
+	if b.state == CircuitOpen && now.Sub(b.openedAt) >= b.openTimeout {
+		b.state = CircuitHalfOpen
+		b.probes = 0
+		b.successes = 0
+	}
+}
+
+func (b *circuitBreaker) open(ctx context.Context, now time.Time) {
+	b.logger.Warn(ctx, "Circuit opened, failing fast", map[string]any{
+		"requests":     b.requests,
+		"failures":     b.failures,
+		"open_timeout": b.openTimeout.String(),
+	})
+	b.state = CircuitOpen
+	b.openedAt = now
+	b.resetWindow(now)
+}
+
+func (b *circuitBreaker) resetWindow(now time.Time) {
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+}
+
+// circuitStates returns the state of each breaker, keyed by provider name.
+func circuitStates(breakers map[string]interface{ State() CircuitState }) map[string]CircuitState {
+	states := make(map[string]CircuitState, len(breakers))
+	for name, breaker := range breakers {
+		states[name] = breaker.State()
+	}
+	return states
+}
+
+// EmailCircuitBreaker is an EmailAdapter that stops calling an unhealthy provider
+// for a while, failing fast with a *CircuitOpenError instead.
+type EmailCircuitBreaker struct {
+	adapter EmailAdapter
+	breaker *circuitBreaker
+}
+
+// NewEmailCircuitBreaker wraps adapter with the given breaker policy.
+func NewEmailCircuitBreaker(adapter EmailAdapter, provider string, cfg config.BreakerConfig, logger logger.Logger) *EmailCircuitBreaker {
+	return &EmailCircuitBreaker{adapter: adapter, breaker: newCircuitBreaker(provider, cfg, logger)}
+}
+
+// State returns the breaker's current state.
+func (b *EmailCircuitBreaker) State() CircuitState {
+	return b.breaker.State()
+}
+
+// SendEmail sends the email unless the circuit is open.
+func (b *EmailCircuitBreaker) SendEmail(ctx context.Context, email dto.Email) error {
+	_, err := b.SendEmailWithResult(ctx, email)
+	return err
+}
+
+// SendEmailWithResult sends the email unless the circuit is open and returns the provider's result.
+func (b *EmailCircuitBreaker) SendEmailWithResult(ctx context.Context, email dto.Email) (dto.SendResult, error) {
+	var result dto.SendResult
+	err := b.breaker.run(ctx, func() error {
+		var err error
+		result, err = sendEmail(ctx, b.adapter, email)
+		return err
+	})
+	return result, err
+}
+
+// SMSCircuitBreaker is an SMSAdapter that stops calling an unhealthy provider
+// for a while, failing fast with a *CircuitOpenError instead.
+type SMSCircuitBreaker struct {
+	adapter SMSAdapter
+	breaker *circuitBreaker
+}
+
+// NewSMSCircuitBreaker wraps adapter with the given breaker policy.
+func NewSMSCircuitBreaker(adapter SMSAdapter, provider string, cfg config.BreakerConfig, logger logger.Logger) *SMSCircuitBreaker {
+	return &SMSCircuitBreaker{adapter: adapter, breaker: newCircuitBreaker(provider, cfg, logger)}
+}
+
+// State returns the breaker's current state.
+func (b *SMSCircuitBreaker) State() CircuitState {
+	return b.breaker.State()
+}
+
+// Send sends the SMS unless the circuit is open.
+func (b *SMSCircuitBreaker) Send(ctx context.Context, sms dto.SMS) error {
+	_, err := b.SendWithResult(ctx, sms)
+	return err
+}
+
+// SendWithResult sends the SMS unless the circuit is open and returns the provider's result.
+func (b *SMSCircuitBreaker) SendWithResult(ctx context.Context, sms dto.SMS) (dto.SendResult, error) {
+	var result dto.SendResult
+	err := b.breaker.run(ctx, func() error {
+		var err error
+		result, err = sendSMS(ctx, b.adapter, sms)
+		return err
+	})
+	return result, err
+}
+
+// NotifyCircuitBreaker is a NotifyAdapter that stops calling an unhealthy channel
+// for a while, failing fast with a *CircuitOpenError instead.
+type NotifyCircuitBreaker struct {
+	adapter NotifyAdapter
+	breaker *circuitBreaker
+}
+
+// NewNotifyCircuitBreaker wraps adapter with the given breaker policy.
+func NewNotifyCircuitBreaker(adapter NotifyAdapter, provider string, cfg config.BreakerConfig, logger logger.Logger) *NotifyCircuitBreaker {
+	return &NotifyCircuitBreaker{adapter: adapter, breaker: newCircuitBreaker(provider, cfg, logger)}
+}
+
+// State returns the breaker's current state.
+func (b *NotifyCircuitBreaker) State() CircuitState {
+	return b.breaker.State()
+}
+
+// Send sends the notification unless the circuit is open.
+func (b *NotifyCircuitBreaker) Send(ctx context.Context, content dto.Content) error {
+	_, err := b.SendWithResult(ctx, content)
+	return err
+}
+
+// SendWithResult sends the notification unless the circuit is open and returns the channel's result.
+func (b *NotifyCircuitBreaker) SendWithResult(ctx context.Context, content dto.Content) (dto.SendResult, error) {
+	var result dto.SendResult
+	err := b.breaker.run(ctx, func() error {
+		var err error
+		result, err = sendNotify(ctx, b.adapter, content)
+		return err
+	})
+	return result, err
+}
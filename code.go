@@ -0,0 +1,18 @@
+package sen
+
+import (
+	"time"
+
+	"github.com/lugondev/send-sen/templates"
+)
+
+// DefaultCodeTTL is the validity stated by SendCode and SendVerificationCode.
+const DefaultCodeTTL = 10 * time.Minute
+
+// FormatTTL renders a code lifetime in English for messages, e.g. "10 minutes",
+// "1 hour 30 minutes" or "45 seconds". It is rounded down to the largest
+// two units and never shorter than one second. The services translate it
+// with the locale of the send, see WithLocale.
+func FormatTTL(ttl time.Duration) string {
+	return templates.FormatDuration(ttl)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -24,6 +25,8 @@ const (
 	NotifyMock     NotifyChannel = "mock"
 	NotifyTelegram NotifyChannel = "telegram"
 	NotifySlack    NotifyChannel = "slack"
+	NotifyDiscord  NotifyChannel = "discord"
+	NotifyTeams    NotifyChannel = "teams"
 )
 
 type EmailProvider string
@@ -31,62 +34,313 @@ type EmailProvider string
 const (
 	EmailSendGrid EmailProvider = "sendgrid"
 	EmailBrevo    EmailProvider = "brevo"
+	EmailSMTP     EmailProvider = "smtp"
+	EmailSES      EmailProvider = "ses"
+	EmailMailgun  EmailProvider = "mailgun"
 	EmailMock     EmailProvider = "mock"
 )
 
 type SMSProvider string
 
 const (
-	SMSProviderTwilio SMSProvider = "twilio"
-	SMSProviderBrevo  SMSProvider = "brevo"
-	SMSProviderMock   SMSProvider = "mock"
+	SMSProviderTwilio      SMSProvider = "twilio"
+	SMSProviderBrevo       SMSProvider = "brevo"
+	SMSProviderVonage      SMSProvider = "vonage"
+	SMSProviderMessageBird SMSProvider = "messagebird"
+	SMSProviderMock        SMSProvider = "mock"
 )
 
 // AdapterConfig holds configuration for different notification adapters.
+// The *Chain lists configure ordered failover across providers; when set they
+// take precedence over the corresponding single provider field.
 type AdapterConfig struct {
-	Notify NotifyChannel `mapstructure:"notify"`
-	Email  EmailProvider `mapstructure:"email"`
-	SMS    SMSProvider   `mapstructure:"sms"`
+	Notify      NotifyChannel   `mapstructure:"notify"`
+	Email       EmailProvider   `mapstructure:"email"`
+	SMS         SMSProvider     `mapstructure:"sms"`
+	NotifyChain []NotifyChannel `mapstructure:"notifyChain"`
+	EmailChain  []EmailProvider `mapstructure:"emailChain"`
+	SMSChain    []SMSProvider   `mapstructure:"smsChain"`
+}
+
+// NotifyChannels returns the ordered notify channels to use.
+func (c AdapterConfig) NotifyChannels() []NotifyChannel {
+	if len(c.NotifyChain) > 0 {
+		return c.NotifyChain
+	}
+	if c.Notify == "" {
+		return nil
+	}
+	return []NotifyChannel{c.Notify}
+}
+
+// EmailProviders returns the ordered email providers to use.
+func (c AdapterConfig) EmailProviders() []EmailProvider {
+	if len(c.EmailChain) > 0 {
+		return c.EmailChain
+	}
+	if c.Email == "" {
+		return nil
+	}
+	return []EmailProvider{c.Email}
+}
+
+// SMSProviders returns the ordered SMS providers to use.
+func (c AdapterConfig) SMSProviders() []SMSProvider {
+	if len(c.SMSChain) > 0 {
+		return c.SMSChain
+	}
+	if c.SMS == "" {
+		return nil
+	}
+	return []SMSProvider{c.SMS}
+}
+
+// RetryConfig holds the retry policy applied to every provider adapter.
+type RetryConfig struct {
+	MaxAttempts    int           `mapstructure:"maxAttempts"`    // Total attempts per send; 0 or 1 disables retries
+	InitialBackoff time.Duration `mapstructure:"initialBackoff"` // Defaults to 200ms
+	MaxBackoff     time.Duration `mapstructure:"maxBackoff"`     // Defaults to 5s
+	Multiplier     float64       `mapstructure:"multiplier"`     // Defaults to 2
+	Jitter         float64       `mapstructure:"jitter"`         // Randomized fraction of each backoff (0-1), defaults to 0.2
+}
+
+// Outbox store types.
+const (
+	OutboxStoreMemory = "memory" // Lost on restart; for tests and best-effort sending
+	OutboxStoreFile   = "file"   // One JSON file per message under Dir
+)
+
+// OutboxConfig holds the asynchronous sending queue settings.
+type OutboxConfig struct {
+	Store          string        `mapstructure:"store"`          // "memory" or "file", defaults to memory
+	Dir            string        `mapstructure:"dir"`            // Directory of the file store
+	Workers        int           `mapstructure:"workers"`        // Concurrent deliveries, defaults to 4
+	PollInterval   time.Duration `mapstructure:"pollInterval"`   // How often idle workers check for due messages, defaults to 1s
+	MaxAttempts    int           `mapstructure:"maxAttempts"`    // Deliveries before a message is marked failed, defaults to 5
+	InitialBackoff time.Duration `mapstructure:"initialBackoff"` // Defaults to 5s
+	MaxBackoff     time.Duration `mapstructure:"maxBackoff"`     // Defaults to 10m
+	Lease          time.Duration `mapstructure:"lease"`          // How long a claimed message is hidden from other workers; sends are cut off shortly before. Defaults to 1m
+}
+
+// Idempotency store types.
+const (
+	IdempotencyStoreMemory = "memory" // Per process, lost on restart
+	IdempotencyStoreFile   = "file"   // One JSON file per key under Dir
+)
+
+// IdempotencyConfig holds duplicate-send suppression settings for messages with an idempotency key.
+type IdempotencyConfig struct {
+	Store  string        `mapstructure:"store"`  // "memory" or "file", defaults to memory
+	Dir    string        `mapstructure:"dir"`    // Directory of the file store
+	Window time.Duration `mapstructure:"window"` // How long a key suppresses repeats, defaults to 10m
+}
+
+// OTP code character sets.
+const (
+	OTPCharsetNumeric      = "numeric"      // Digits only
+	OTPCharsetAlphanumeric = "alphanumeric" // Upper-case letters and digits, without look-alikes (0/O, 1/I)
+)
+
+// OTPConfig holds one-time password settings.
+type OTPConfig struct {
+	Length         int           `mapstructure:"length"`         // Code length, defaults to 6
+	Charset        string        `mapstructure:"charset"`        // "numeric" or "alphanumeric", defaults to numeric
+	TTL            time.Duration `mapstructure:"ttl"`            // Code lifetime, defaults to 10m
+	MaxAttempts    int           `mapstructure:"maxAttempts"`    // Wrong guesses before a code is locked, defaults to 5
+	ResendCooldown time.Duration `mapstructure:"resendCooldown"` // Minimum delay between codes to one recipient, defaults to 1m
+}
+
+// RateLimit is a token bucket allowing Limit sends per Per, in bursts of up to Limit.
+type RateLimit struct {
+	Limit int           `mapstructure:"limit"` // 0 disables the limit
+	Per   time.Duration `mapstructure:"per"`   // Defaults to 1m
+}
+
+// RateLimitConfig holds the send rate limits applied by the email, SMS and notify services.
+type RateLimitConfig struct {
+	Recipient RateLimit            `mapstructure:"recipient"` // Per email address or phone number, per service
+	Global    RateLimit            `mapstructure:"global"`    // Per service, across all recipients
+	Providers map[string]RateLimit `mapstructure:"providers"` // Keyed by provider name, e.g. "sendgrid", "twilio", "telegram"
+}
+
+// BreakerConfig holds the circuit breaker policy applied to every provider adapter.
+type BreakerConfig struct {
+	FailureRatio     float64       `mapstructure:"failureRatio"`     // Share of failed sends (0-1) that opens the circuit; 0 disables the breaker
+	MinRequests      int           `mapstructure:"minRequests"`      // Sends in the window before the ratio is evaluated, defaults to 10
+	Window           time.Duration `mapstructure:"window"`           // Period over which sends are counted, defaults to 1m
+	OpenTimeout      time.Duration `mapstructure:"openTimeout"`      // How long the circuit stays open before probing, defaults to 30s
+	HalfOpenRequests int           `mapstructure:"halfOpenRequests"` // Successful probes needed to close the circuit, defaults to 1
+}
+
+// TemplateConfig holds the email template settings.
+type TemplateConfig struct {
+	Dir         string `mapstructure:"dir"`         // Directory whose templates override the built-in ones
+	ProductName string `mapstructure:"productName"` // Rendered by {{product}} in the built-in templates, defaults to "MyService"
+	Locale      string `mapstructure:"locale"`      // Locale used when a send names none and as the last fallback, e.g. "en" or "vi"
+}
+
+// AttachmentConfig holds the email attachment size limits, in bytes.
+type AttachmentConfig struct {
+	MaxSize      int64 `mapstructure:"maxSize"`      // Largest single attachment, defaults to 10 MiB
+	MaxTotalSize int64 `mapstructure:"maxTotalSize"` // Largest sum of the attachments of one email, defaults to 25 MiB
 }
 
 // SendGridConfig holds SendGrid specific configuration.
 type SendGridConfig struct {
-	APIKey    string `mapstructure:"apiKey"`
-	FromEmail string `mapstructure:"fromEmail"`
-	FromName  string `mapstructure:"fromName"`
+	APIKey    string        `mapstructure:"apiKey"`
+	FromEmail string        `mapstructure:"fromEmail"`
+	FromName  string        `mapstructure:"fromName"`
+	BaseURL   string        `mapstructure:"baseUrl"` // Optional, defaults to https://api.sendgrid.com
+	Timeout   time.Duration `mapstructure:"timeout"` // Per API request, defaults to 30s
+}
+
+// SMTPConfig holds SMTP relay specific configuration.
+type SMTPConfig struct {
+	Host               string        `mapstructure:"host"`
+	Port               int           `mapstructure:"port"` // Defaults to 465 for implicit TLS, 587 otherwise
+	Username           string        `mapstructure:"username"`
+	Password           string        `mapstructure:"password"`
+	AuthMethod         string        `mapstructure:"authMethod"` // "plain", "login", "cram-md5" or "none"; defaults to "plain" when a username is set
+	Encryption         string        `mapstructure:"encryption"` // "starttls", "tls" (implicit) or "none"; empty uses STARTTLS when offered
+	InsecureSkipVerify bool          `mapstructure:"insecureSkipVerify"`
+	LocalName          string        `mapstructure:"localName"` // Name sent with EHLO, defaults to "localhost"
+	FromEmail          string        `mapstructure:"fromEmail"`
+	FromName           string        `mapstructure:"fromName"`
+	MaxIdleConns       int           `mapstructure:"maxIdleConns"` // Keep-alive connections kept for bursts, defaults to 2
+	IdleTimeout        time.Duration `mapstructure:"idleTimeout"`  // Idle connections older than this are closed, defaults to 30s
+	Timeout            time.Duration `mapstructure:"timeout"`      // Per email, including connecting, defaults to 30s
+}
+
+// SESConfig holds Amazon SES (v2 API) specific configuration.
+type SESConfig struct {
+	Region           string        `mapstructure:"region"`
+	AccessKeyID      string        `mapstructure:"accessKeyId"`
+	SecretAccessKey  string        `mapstructure:"secretAccessKey"`
+	SessionToken     string        `mapstructure:"sessionToken"` // Optional, for temporary credentials
+	FromEmail        string        `mapstructure:"fromEmail"`
+	FromName         string        `mapstructure:"fromName"`
+	ConfigurationSet string        `mapstructure:"configurationSet"` // Optional
+	Tags             []SESTag      `mapstructure:"tags"`             // Optional message tags added to every email
+	Endpoint         string        `mapstructure:"endpoint"`         // Optional, defaults to https://email.<region>.amazonaws.com
+	Timeout          time.Duration `mapstructure:"timeout"`          // Per API request, defaults to 30s
+}
+
+// SESTag is an SES message tag. Tags are a list rather than a map because
+// viper lowercases map keys, and SES tag names are case-sensitive.
+type SESTag struct {
+	Name  string `mapstructure:"name"`
+	Value string `mapstructure:"value"`
+}
+
+// MailgunConfig holds Mailgun specific configuration.
+type MailgunConfig struct {
+	APIKey    string            `mapstructure:"apiKey"`
+	Domain    string            `mapstructure:"domain"`
+	Region    string            `mapstructure:"region"`  // "us" (default) or "eu"
+	BaseURL   string            `mapstructure:"baseUrl"` // Optional, overrides the region base URL
+	FromEmail string            `mapstructure:"fromEmail"`
+	FromName  string            `mapstructure:"fromName"`
+	Tags      []string          `mapstructure:"tags"`      // Optional tags added to every email
+	Variables map[string]string `mapstructure:"variables"` // Optional custom variables added to every email; names are lowercased when read from a config file
+	Timeout   time.Duration     `mapstructure:"timeout"`   // Per API request, defaults to 30s
 }
 
 // TwilioConfig holds Twilio specific configuration.
 type TwilioConfig struct {
-	AccountSid   string `mapstructure:"accountSid"`
-	AuthToken    string `mapstructure:"authToken"`
-	FromNumber   string `mapstructure:"fromNumber"`
-	MessagingSid string `mapstructure:"messagingSid"`
+	AccountSid   string        `mapstructure:"accountSid"`
+	AuthToken    string        `mapstructure:"authToken"`
+	FromNumber   string        `mapstructure:"fromNumber"`
+	MessagingSid string        `mapstructure:"messagingSid"`
+	BaseURL      string        `mapstructure:"baseUrl"` // Optional, defaults to https://api.twilio.com
+	Timeout      time.Duration `mapstructure:"timeout"` // Per API request, defaults to 30s
+}
+
+// VonageConfig holds Vonage (formerly Nexmo) specific configuration.
+type VonageConfig struct {
+	APIKey    string        `mapstructure:"apiKey"`
+	APISecret string        `mapstructure:"apiSecret"`
+	From      string        `mapstructure:"from"`    // Sender ID or phone number
+	BaseURL   string        `mapstructure:"baseUrl"` // Optional, defaults to https://rest.nexmo.com
+	Timeout   time.Duration `mapstructure:"timeout"` // Per API request, defaults to 30s
+}
+
+// MessageBirdConfig holds MessageBird specific configuration.
+type MessageBirdConfig struct {
+	AccessKey  string        `mapstructure:"accessKey"`
+	Originator string        `mapstructure:"originator"` // Sender ID or phone number
+	BaseURL    string        `mapstructure:"baseUrl"`    // Optional, defaults to https://rest.messagebird.com
+	Timeout    time.Duration `mapstructure:"timeout"`    // Per API request, defaults to 30s
 }
 
 // TelegramConfig holds Telegram specific configuration.
 type TelegramConfig struct {
-	BotToken string `mapstructure:"botToken"`
-	ChatID   string `mapstructure:"chatId"`
-	Debug    bool   `mapstructure:"debug"`
+	BotToken string        `mapstructure:"botToken"`
+	ChatID   string        `mapstructure:"chatId"`
+	Debug    bool          `mapstructure:"debug"`
+	BaseURL  string        `mapstructure:"baseUrl"` // Optional, defaults to https://api.telegram.org
+	Timeout  time.Duration `mapstructure:"timeout"` // Per API request, defaults to 10s
+}
+
+// SlackConfig holds Slack specific configuration.
+// When BotToken is set, messages are posted via chat.postMessage to ChannelID;
+// otherwise they are posted to the incoming WebhookURL.
+type SlackConfig struct {
+	WebhookURL string        `mapstructure:"webhookUrl"`
+	BotToken   string        `mapstructure:"botToken"`
+	ChannelID  string        `mapstructure:"channelId"`
+	APIURL     string        `mapstructure:"apiUrl"`  // Optional, defaults to https://slack.com/api
+	Timeout    time.Duration `mapstructure:"timeout"` // Per API request, defaults to 10s
+}
+
+// DiscordConfig holds Discord webhook specific configuration.
+type DiscordConfig struct {
+	WebhookURL string        `mapstructure:"webhookUrl"`
+	Username   string        `mapstructure:"username"`  // Optional, overrides the webhook's default name
+	AvatarURL  string        `mapstructure:"avatarUrl"` // Optional, overrides the webhook's default avatar
+	Timeout    time.Duration `mapstructure:"timeout"`   // Per API request, defaults to 10s
+}
+
+// TeamsConfig holds Microsoft Teams specific configuration.
+// WebhookURL may point to a Workflows (Power Automate) webhook or a legacy incoming webhook.
+type TeamsConfig struct {
+	WebhookURL string        `mapstructure:"webhookUrl"`
+	Timeout    time.Duration `mapstructure:"timeout"` // Per API request, defaults to 10s
 }
 
 // BrevoConfig holds Brevo (formerly Sendinblue) specific configuration.
 type BrevoConfig struct {
-	APIKey      string `mapstructure:"apiKey"`
-	SenderEmail string `mapstructure:"senderEmail"`
-	SenderName  string `mapstructure:"senderName"`
-	SMSSender   string `mapstructure:"smsSender"`
+	APIKey      string        `mapstructure:"apiKey"`
+	SenderEmail string        `mapstructure:"senderEmail"`
+	SenderName  string        `mapstructure:"senderName"`
+	SMSSender   string        `mapstructure:"smsSender"`
+	BaseURL     string        `mapstructure:"baseUrl"` // Optional, defaults to https://api.brevo.com/v3
+	Timeout     time.Duration `mapstructure:"timeout"` // Per API request, defaults to 30s
 }
 
 // Config stores all configuration of the application.
 type Config struct {
-	Log      LogConfig      `mapstructure:"log"`
-	Adapter  AdapterConfig  `mapstructure:"adapter"`
-	SendGrid SendGridConfig `mapstructure:"sendgrid"`
-	Twilio   TwilioConfig   `mapstructure:"twilio"`
-	Telegram TelegramConfig `mapstructure:"telegram"`
-	Brevo    BrevoConfig    `mapstructure:"brevo"`
+	Log         LogConfig         `mapstructure:"log"`
+	Adapter     AdapterConfig     `mapstructure:"adapter"`
+	Retry       RetryConfig       `mapstructure:"retry"`
+	Breaker     BreakerConfig     `mapstructure:"breaker"`
+	Outbox      OutboxConfig      `mapstructure:"outbox"`
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"`
+	OTP         OTPConfig         `mapstructure:"otp"`
+	RateLimit   RateLimitConfig   `mapstructure:"rateLimit"`
+	Templates   TemplateConfig    `mapstructure:"templates"`
+	Attachments AttachmentConfig  `mapstructure:"attachments"`
+	SendGrid    SendGridConfig    `mapstructure:"sendgrid"`
+	Twilio      TwilioConfig      `mapstructure:"twilio"`
+	Telegram    TelegramConfig    `mapstructure:"telegram"`
+	Brevo       BrevoConfig       `mapstructure:"brevo"`
+	Slack       SlackConfig       `mapstructure:"slack"`
+	Discord     DiscordConfig     `mapstructure:"discord"`
+	Teams       TeamsConfig       `mapstructure:"teams"`
+	SMTP        SMTPConfig        `mapstructure:"smtp"`
+	SES         SESConfig         `mapstructure:"ses"`
+	Mailgun     MailgunConfig     `mapstructure:"mailgun"`
+	Vonage      VonageConfig      `mapstructure:"vonage"`
+	MessageBird MessageBirdConfig `mapstructure:"messagebird"`
 }
 
 // LoadConfig reads configuration from a YAML file or environment variables.
@@ -0,0 +1,46 @@
+// Package dedup stores the results of sends made with an idempotency key, so
+// a repeated key within the dedup window returns the original result instead
+// of sending the message again.
+package dedup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+)
+
+// DefaultWindow is how long a result is remembered when config.IdempotencyConfig.Window is zero.
+const DefaultWindow = 10 * time.Minute
+
+// Store remembers send results by idempotency key. Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the result stored under key, if it has not expired.
+	Get(ctx context.Context, key string) (dto.SendResult, bool, error)
+	// Put stores result under key for ttl.
+	Put(ctx context.Context, key string, result dto.SendResult, ttl time.Duration) error
+}
+
+// NewStore creates the store selected by cfg.Store.
+func NewStore(cfg config.IdempotencyConfig) (Store, error) {
+	switch cfg.Store {
+	case "", config.IdempotencyStoreMemory:
+		return NewMemoryStore(), nil
+	case config.IdempotencyStoreFile:
+		return NewFileStore(cfg.Dir)
+	default:
+		return nil, fmt.Errorf("unsupported idempotency store: %s", cfg.Store)
+	}
+}
+
+// entry is a stored result with its expiry.
+type entry struct {
+	Result    dto.SendResult `json:"result"`
+	ExpiresAt time.Time      `json:"expiresAt"`
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !now.Before(e.ExpiresAt)
+}
@@ -0,0 +1,134 @@
+package dedup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lugondev/send-sen/dto"
+)
+
+// FileStore keeps each result as a JSON file named after the hashed key, so
+// results survive restarts and can be shared by processes on one host.
+// Each file's modification time is set to its expiry, so Put can sweep
+// expired files every sweepInterval without decoding them; Get also removes
+// an expired file it reads.
+type FileStore struct {
+	mu        sync.Mutex
+	dir       string
+	lastSweep time.Time
+}
+
+// NewFileStore creates a store in dir, creating the directory if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("idempotency directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create idempotency directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Get implements Store.
+func (s *FileStore) Get(_ context.Context, key string) (dto.SendResult, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.path(key)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return dto.SendResult{}, false, nil
+	}
+	if err != nil {
+		return dto.SendResult{}, false, fmt.Errorf("failed to read idempotency entry: %w", err)
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return dto.SendResult{}, false, fmt.Errorf("failed to decode idempotency entry: %w", err)
+	}
+	if e.expired(time.Now()) {
+		_ = os.Remove(path)
+		return dto.SendResult{}, false, nil
+	}
+	return e.Result, true, nil
+}
+
+// Put implements Store.
+func (s *FileStore) Put(_ context.Context, key string, result dto.SendResult, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+		s.lastSweep = now
+	}
+	expiresAt := now.Add(ttl)
+	data, err := json.Marshal(entry{Result: result, ExpiresAt: expiresAt})
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotency entry: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write idempotency entry: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write idempotency entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write idempotency entry: %w", err)
+	}
+	if err := os.Chtimes(tmp.Name(), expiresAt, expiresAt); err != nil {
+		return fmt.Errorf("failed to write idempotency entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		return fmt.Errorf("failed to write idempotency entry: %w", err)
+	}
+	return nil
+}
+
+// sweep removes the entries whose expiry, kept as the modification time, has
+// passed, along with temporary files left behind by a crash. Errors are
+// ignored: a file that stays is swept next time, or removed by Get.
+func (s *FileStore) sweep(now time.Time) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !(strings.HasSuffix(name, ".json") || strings.HasPrefix(name, ".tmp-")) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		expired := !now.Before(info.ModTime())
+		if strings.HasPrefix(name, ".tmp-") {
+			// Temporary files are only renamed into place once their time is set.
+			expired = now.Sub(info.ModTime()) >= sweepInterval
+		}
+		if expired {
+			_ = os.Remove(filepath.Join(s.dir, name))
+		}
+	}
+}
+
+// path hashes the key so any string is a safe file name.
+func (s *FileStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
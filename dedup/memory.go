@@ -0,0 +1,52 @@
+package dedup
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lugondev/send-sen/dto"
+)
+
+// sweepInterval bounds how often expired entries are purged.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps results in memory. Entries are lost on restart.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]entry
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]entry)}
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(_ context.Context, key string) (dto.SendResult, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok || e.expired(time.Now()) {
+		return dto.SendResult{}, false, nil
+	}
+	return e.Result, true, nil
+}
+
+// Put implements Store.
+func (s *MemoryStore) Put(_ context.Context, key string, result dto.SendResult, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, e := range s.entries {
+			if e.expired(now) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	s.entries[key] = entry{Result: result, ExpiresAt: now.Add(ttl)}
+	return nil
+}
@@ -0,0 +1,21 @@
+package dto
+
+import "io"
+
+// Disposition says how mail clients present an attachment.
+type Disposition string
+
+const (
+	DispositionAttachment Disposition = "attachment" // Listed as a downloadable file
+	DispositionInline     Disposition = "inline"     // Shown in the HTML body through its ContentID
+)
+
+// Attachment represents a file sent with an email. Set either Content or Reader.
+type Attachment struct {
+	Filename    string      // File name shown to the recipient, e.g. "invoice.pdf"
+	ContentType string      // MIME type; guessed from Filename when empty
+	Content     []byte      // The file content
+	Reader      io.Reader   `json:"-"` // Read once, when the email is sent, if Content is nil
+	Disposition Disposition // Defaults to DispositionAttachment
+	ContentID   string      // Required for inline images, referenced as <img src="cid:ContentID">
+}
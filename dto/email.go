@@ -1,10 +1,20 @@
 package dto
 
 type Email struct {
+	From    string // Optional sender, e.g. "Support <support@example.com>"; defaults to the provider's configured sender
+	ReplyTo string // Optional Reply-To address
 	To      []string
 	Cc      []string
 	Bcc     []string
 	Subject string
 	Html    string
 	Body    string
+
+	Attachments []Attachment
+
+	Headers  map[string]string // Optional custom headers, e.g. "X-Ticket-ID"
+	Tags     []string          // Optional analytics tags: SendGrid categories, Brevo, Mailgun and SES tags
+	Metadata map[string]string // Optional custom arguments: SendGrid custom_args, Brevo params, Mailgun variables, SES tags
+
+	IdempotencyKey string // Optional; repeats within the dedup window return the first result without sending
 }
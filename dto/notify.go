@@ -16,4 +16,6 @@ type Content struct {
 	Message   string
 	Level     Level
 	ParseMode string
+
+	IdempotencyKey string // Optional; repeats within the dedup window return the first result without sending
 }
@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+// SendResult describes a message accepted by a provider.
+type SendResult struct {
+	Provider  string    // Provider that accepted the message, e.g. "sendgrid"
+	MessageID string    // Provider message ID, used to correlate delivery webhooks
+	Accepted  []string  // Recipients the provider accepted
+	Rejected  []string  // Recipients the provider refused while accepting the others
+	Timestamp time.Time // When the provider accepted the message
+	Status    string    // Raw provider status, e.g. "queued"
+}
@@ -4,4 +4,6 @@ package dto
 type SMS struct {
 	To      string // The recipient's phone number (E.164 format recommended)
 	Message string // The text message content
+
+	IdempotencyKey string // Optional; repeats within the dedup window return the first result without sending
 }
@@ -2,6 +2,7 @@ package sen
 
 import (
 	"context"
+	"time"
 
 	"github.com/lugondev/send-sen/dto"
 )
@@ -11,12 +12,23 @@ type EmailAdapter interface {
 	SendEmail(ctx context.Context, email dto.Email) error
 }
 
+// EmailResultAdapter is implemented by email adapters that report the provider's send result.
+type EmailResultAdapter interface {
+	EmailAdapter
+	SendEmailWithResult(ctx context.Context, email dto.Email) (dto.SendResult, error)
+}
+
 // Service defines the core logic for handling emails.
 type EmailService interface {
 	SendEmail(ctx context.Context, email dto.Email) error
+	SendEmailWithResult(ctx context.Context, email dto.Email) (dto.SendResult, error)
+	SendTemplate(ctx context.Context, name string, to string, data any) error
 	SendPasswordReset(ctx context.Context, to string, link string) error
 	SendVerificationCode(ctx context.Context, to string, code string) error
+	SendVerificationCodeWithTTL(ctx context.Context, to string, code string, ttl time.Duration) error
 	SendWelcome(ctx context.Context, to string, name string) error
 	SendWarningLogin(ctx context.Context, to string, location string, time string) error
+	SendLoginWarning(ctx context.Context, to string, location string, at time.Time) error
 	ServiceName() string
+	CircuitStates() map[string]CircuitState
 }
@@ -1,16 +1,21 @@
 package sen
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"html/template"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/lugondev/send-sen/adapters/email"
 	"github.com/lugondev/send-sen/dto"
 
 	logger "github.com/lugondev/go-log"
 	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/mailhtml"
+	"github.com/lugondev/send-sen/ratelimit"
+	"github.com/lugondev/send-sen/templates"
 )
 
 // emailService implements the Service interface.
@@ -18,188 +23,275 @@ type emailService struct {
 	adapter EmailAdapter
 	logger  logger.Logger
 	name    config.EmailProvider
+	dedup   *deduper
+	limits  rateLimits
+	// breakers holds the circuit breaker of each provider, for CircuitStates.
+	breakers map[string]interface{ State() CircuitState }
+	// templates renders SendTemplate and the built-in helpers.
+	templates *templates.Registry
+	// attachments holds the attachment size limits, with defaults applied.
+	attachments config.AttachmentConfig
 }
 
 // NewEmailService creates a new instance of Service.
-func NewEmailService(cfg config.Config, logger logger.Logger) (EmailService, error) {
+// Each provider is wrapped with the cfg.Retry policy and the cfg.Breaker circuit
+// breaker, and when several providers are configured (cfg.Adapter.EmailChain) they
+// are wrapped in an EmailFailover, in order.
+// Messages with an IdempotencyKey are deduplicated through the cfg.Idempotency store,
+// and sends are throttled by the cfg.RateLimit limits. Templates are loaded from
+// cfg.Templates unless WithTemplates is given, and attachments are checked against
+// the cfg.Attachments limits.
+func NewEmailService(cfg config.Config, logger logger.Logger, opts ...ServiceOption) (EmailService, error) {
 	ctx := context.Background()
+	options := newServiceOptions(opts)
+	limits := newRateLimits("email", cfg.RateLimit, ratelimit.New(options.rateLimitStore))
+	breakers := make(map[string]interface{ State() CircuitState })
 	logger.Debug(ctx, "Registered email adapter", map[string]any{
-		"adapter": cfg.Adapter.Email,
+		"adapter": cfg.Adapter.EmailProviders(),
 	})
 
-	var emailAdapter EmailAdapter
-	if cfg.Adapter.Email == config.EmailBrevo {
-		brevoAdapter, err := email.NewBrevoAdapter(cfg.Brevo, logger)
+	var providers []Provider[EmailAdapter]
+	var names []string
+	for _, provider := range cfg.Adapter.EmailProviders() {
+		emailAdapter, err := newEmailAdapter(cfg, provider, logger, options.httpClient(string(provider)))
 		if err != nil {
-			logger.Error(ctx, "Failed to create Brevo adapter", map[string]any{
-				"error": err,
+			logger.Error(ctx, "Failed to create email adapter", map[string]any{
+				"adapter": provider,
+				"error":   err,
 			})
-		} else {
-			emailAdapter = brevoAdapter
-			logger.Info(ctx, "Using Brevo adapter for email sending")
+			return nil, err
 		}
-	} else if cfg.Adapter.Email == config.EmailSendGrid {
-		sendgridAdapter, err := email.NewSendGridAdapter(cfg.SendGrid, logger)
-		if err != nil {
-			logger.Error(ctx, "Failed to create SendGrid adapter", map[string]any{
-				"error": err,
-			})
-		} else {
-			emailAdapter = sendgridAdapter
-			logger.Info(ctx, "Using SendGrid adapter for email sending")
+		if cfg.Retry.MaxAttempts > 1 {
+			emailAdapter = NewEmailRetry(emailAdapter, cfg.Retry, logger)
 		}
+		if cfg.Breaker.FailureRatio > 0 {
+			breaker := NewEmailCircuitBreaker(emailAdapter, string(provider), cfg.Breaker, logger)
+			breakers[string(provider)] = breaker
+			emailAdapter = breaker
+		}
+		if limit, ok := limits.providerLimit(string(provider)); ok {
+			emailAdapter = NewEmailRateLimit(emailAdapter, limits.limiter, string(provider), limit)
+		}
+		logger.Info(ctx, "Using email adapter for email sending", map[string]any{
+			"adapter": provider,
+		})
+		providers = append(providers, Provider[EmailAdapter]{Name: string(provider), Adapter: emailAdapter})
+		names = append(names, string(provider))
+	}
+
+	var emailAdapter EmailAdapter
+	switch len(providers) {
+	case 0:
+		return nil, fmt.Errorf("no email provider configured: set adapter.email or adapter.emailChain (%q for testing)", config.EmailMock)
+	case 1:
+		emailAdapter = providers[0].Adapter
+	default:
+		emailAdapter = NewEmailFailover(logger, providers...)
+	}
+	name := config.EmailProvider(strings.Join(names, ","))
+
+	serviceLogger := logger.WithFields(map[string]any{
+		"service": "email_service_" + name,
+	})
+	dedup, err := newDeduper(cfg.Idempotency, options.dedupStore, serviceLogger)
+	if err != nil {
+		return nil, err
 	}
-	if emailAdapter == nil {
-		emailAdapter = email.NewMockEmailAdapter(logger)
-		logger.Info(ctx, "Using MockEmail adapter for email sending")
+	registry := options.templates
+	if registry == nil {
+		if registry, err = templates.New(cfg.Templates); err != nil {
+			return nil, fmt.Errorf("failed to load email templates: %w", err)
+		}
 	}
 
 	return &emailService{
-		adapter: emailAdapter,
-		logger: logger.WithFields(map[string]any{
-			"service": "email_service_" + cfg.Adapter.Email,
-		}),
-		name: cfg.Adapter.Email,
+		adapter:     emailAdapter,
+		logger:      serviceLogger,
+		name:        name,
+		dedup:       dedup,
+		limits:      limits,
+		breakers:    breakers,
+		templates:   registry,
+		attachments: newAttachmentLimits(cfg.Attachments),
 	}, nil
 }
 
+// newEmailAdapter creates the adapter for a single email provider, sending
+// through httpClient when it is not nil.
+func newEmailAdapter(cfg config.Config, provider config.EmailProvider, logger logger.Logger, httpClient *http.Client) (EmailAdapter, error) {
+	var opts []email.Option
+	if httpClient != nil {
+		opts = append(opts, email.WithHTTPClient(httpClient))
+	}
+	switch provider {
+	case config.EmailBrevo:
+		brevoAdapter, err := email.NewBrevoAdapter(cfg.Brevo, logger, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Brevo email adapter: %w", err)
+		}
+		return brevoAdapter, nil
+	case config.EmailSendGrid:
+		sendGridAdapter, err := email.NewSendGridAdapter(cfg.SendGrid, logger, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SendGrid email adapter: %w", err)
+		}
+		return sendGridAdapter, nil
+	case config.EmailSMTP:
+		smtpAdapter, err := email.NewSMTPAdapter(cfg.SMTP, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SMTP email adapter: %w", err)
+		}
+		return smtpAdapter, nil
+	case config.EmailSES:
+		sesAdapter, err := email.NewSESAdapter(cfg.SES, logger, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SES email adapter: %w", err)
+		}
+		return sesAdapter, nil
+	case config.EmailMailgun:
+		mailgunAdapter, err := email.NewMailgunAdapter(cfg.Mailgun, logger, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Mailgun email adapter: %w", err)
+		}
+		return mailgunAdapter, nil
+	case config.EmailMock:
+		return email.NewMockEmailAdapter(logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported email provider %q", provider)
+	}
+}
+
 // SendEmail delegates the email sending task to the configured adapter.
 func (s *emailService) SendEmail(ctx context.Context, message dto.Email) error {
+	_, err := s.SendEmailWithResult(ctx, message)
+	return err
+}
+
+// SendEmailWithResult delegates the email sending task to the configured adapter
+// and returns the provider's send result.
+func (s *emailService) SendEmailWithResult(ctx context.Context, message dto.Email) (dto.SendResult, error) {
 	if len(message.To) == 0 {
-		return fmt.Errorf("message must have at least one recipient")
+		return dto.SendResult{}, fmt.Errorf("message must have at least one recipient")
 	}
 	if message.Subject == "" {
-		return fmt.Errorf("message subject cannot be empty")
+		return dto.SendResult{}, fmt.Errorf("message subject cannot be empty")
+	}
+	if message.Body == "" && message.Html == "" {
+		return dto.SendResult{}, fmt.Errorf("message body cannot be empty")
 	}
 	if message.Body == "" {
-		return fmt.Errorf("message body cannot be empty")
+		// Derive the plain-text part once, rather than in every adapter of a failover chain.
+		message.Body = mailhtml.ToText(message.Html)
 	}
-
-	// Delegate to the adapter
-	err := s.adapter.SendEmail(ctx, message)
+	if err := validateEmailFields(message); err != nil {
+		return dto.SendResult{}, err
+	}
+	attachments, err := prepareAttachments(message.Attachments, s.attachments)
 	if err != nil {
-		// Log the error maybe?
-		return fmt.Errorf("failed to send message via adapter: %w", err)
+		return dto.SendResult{}, err
 	}
-	return nil
-}
+	message.Attachments = attachments
 
-// ---------- private helpers ----------
+	return s.dedup.do(ctx, "email", message.IdempotencyKey, func() (dto.SendResult, error) {
+		if err := s.limits.allow(ctx, slices.Concat(message.To, message.Cc, message.Bcc)...); err != nil {
+			return dto.SendResult{}, err
+		}
 
-// renderHTML compile template & return HTML.
-func (s *emailService) renderHTML(tpl string, data any) (string, error) {
-	t, err := template.New("").Parse(tpl)
-	if err != nil {
-		return "", err
-	}
-	var buf bytes.Buffer
-	if err := t.Execute(&buf, data); err != nil {
-		return "", err
-	}
-	return buf.String(), nil
+		// Delegate to the adapter
+		result, err := sendEmail(ctx, s.adapter, message)
+		if err != nil {
+			// Log the error maybe?
+			return dto.SendResult{}, fmt.Errorf("failed to send message via adapter: %w", err)
+		}
+		if result.Provider == "" {
+			result.Provider = string(s.name)
+		}
+		return result, nil
+	})
+}
+
+// PrepareAttachments checks attachments against the cfg.Attachments limits and
+// reads their readers, as SendEmail does.
+func (s *emailService) PrepareAttachments(attachments []dto.Attachment) ([]dto.Attachment, error) {
+	return prepareAttachments(attachments, s.attachments)
 }
 
+// ServiceName returns the name of the email service.
 func (s *emailService) ServiceName() string {
 	return string(s.name)
 }
 
-// SendPasswordReset sends a password reset email with a reset link.
-func (s *emailService) SendPasswordReset(ctx context.Context, to string, link string) error {
-	// Render the HTML template
-	html, err := s.renderHTML(tplReset, map[string]string{
-		"Link": link,
-	})
+// SendTemplate renders the named template from the registry with data and sends it to the recipient.
+// The template is rendered in the locale of ctx, see WithLocale.
+func (s *emailService) SendTemplate(ctx context.Context, name string, to string, data any) error {
+	locale := LocaleFromContext(ctx)
+	rendered, err := s.templates.RenderLocale(locale, name, data)
 	if err != nil {
-		s.logger.Error(ctx, "Failed to render password reset template", map[string]any{
-			"error": err,
+		s.logger.Error(ctx, "Failed to render email template", map[string]any{
+			"template": name,
+			"locale":   locale,
+			"error":    err,
 		})
-		return fmt.Errorf("failed to render password reset template: %w", err)
+		return fmt.Errorf("failed to render %s template: %w", name, err)
 	}
 
 	// Create the email message
 	message := dto.Email{
 		To:      []string{to},
-		Subject: "Password Reset Request",
-		Html:    html,
-		Body:    "You have requested to reset your password. Click the link to continue: " + link,
+		Subject: rendered.Subject,
+		Html:    rendered.HTML,
+		Body:    rendered.Text,
 	}
 
 	// Send the email
 	return s.SendEmail(ctx, message)
 }
 
-// SendVerificationCode sends an email with a verification code.
-func (s *emailService) SendVerificationCode(ctx context.Context, to string, code string) error {
-	// Render the HTML template
-	html, err := s.renderHTML(tplVerificationCode, map[string]string{
-		"Code": code,
+// SendPasswordReset sends a password reset email with a reset link.
+func (s *emailService) SendPasswordReset(ctx context.Context, to string, link string) error {
+	return s.SendTemplate(ctx, templates.PasswordReset, to, map[string]string{
+		"Link": link,
 	})
-	if err != nil {
-		s.logger.Error(ctx, "Failed to render verification code template", map[string]any{
-			"error": err,
-		})
-		return fmt.Errorf("failed to render verification code template: %w", err)
-	}
+}
 
-	// Create the email message
-	message := dto.Email{
-		To:      []string{to},
-		Subject: "Your Verification Code",
-		Html:    html,
-		Body:    "Your verification code is: " + code + ". This code will expire in 10 minutes.",
-	}
+// SendVerificationCode sends an email with a verification code valid for DefaultCodeTTL.
+func (s *emailService) SendVerificationCode(ctx context.Context, to string, code string) error {
+	return s.SendVerificationCodeWithTTL(ctx, to, code, DefaultCodeTTL)
+}
 
-	// Send the email
-	return s.SendEmail(ctx, message)
+// SendVerificationCodeWithTTL sends an email with a verification code that expires after ttl.
+func (s *emailService) SendVerificationCodeWithTTL(ctx context.Context, to string, code string, ttl time.Duration) error {
+	return s.SendTemplate(ctx, templates.VerificationCode, to, map[string]string{
+		"Code":   code,
+		"Expiry": s.templates.FormatDuration(LocaleFromContext(ctx), ttl),
+	})
 }
 
 // SendWelcome sends a welcome email to a new user.
 func (s *emailService) SendWelcome(ctx context.Context, to string, name string) error {
-	// Render the HTML template
-	html, err := s.renderHTML(tplWelcome, map[string]string{
+	return s.SendTemplate(ctx, templates.Welcome, to, map[string]string{
 		"Name": name,
 	})
-	if err != nil {
-		s.logger.Error(ctx, "Failed to render welcome template", map[string]any{
-			"error": err,
-		})
-		return fmt.Errorf("failed to render welcome template: %w", err)
-	}
-
-	// Create the email message
-	message := dto.Email{
-		To:      []string{to},
-		Subject: "Welcome to MyService!",
-		Html:    html,
-		Body:    "Hello " + name + ", Welcome to MyService! Explore our amazing features right now.",
-	}
-
-	// Send the email
-	return s.SendEmail(ctx, message)
 }
 
 // SendWarningLogin sends a warning email about a new login from an unfamiliar location.
+// The time is shown as given; SendLoginWarning formats it for the recipient's locale.
 func (s *emailService) SendWarningLogin(ctx context.Context, to string, location string, time string) error {
-	// Render the HTML template
-	html, err := s.renderHTML(tplWarningLogin, map[string]string{
+	return s.SendTemplate(ctx, templates.LoginWarning, to, map[string]string{
 		"Location": location,
 		"Time":     time,
 	})
-	if err != nil {
-		s.logger.Error(ctx, "Failed to render login warning template", map[string]any{
-			"error": err,
-		})
-		return fmt.Errorf("failed to render login warning template: %w", err)
-	}
+}
 
-	// Create the email message
-	message := dto.Email{
-		To:      []string{to},
-		Subject: "Security Alert: New Login Detected",
-		Html:    html,
-		Body:    "We detected a new login to your account from " + location + " at " + time + ". If this wasn't you, please secure your account immediately.",
-	}
+// SendLoginWarning sends a warning email about a new login from an unfamiliar location,
+// with the login time formatted for the locale of ctx. Convert at to the recipient's
+// time zone first, e.g. with at.In(loc).
+func (s *emailService) SendLoginWarning(ctx context.Context, to string, location string, at time.Time) error {
+	return s.SendWarningLogin(ctx, to, location, s.templates.FormatTime(LocaleFromContext(ctx), at))
+}
 
-	// Send the email
-	return s.SendEmail(ctx, message)
+// CircuitStates reports the circuit breaker state of each provider, for health checks.
+// It is empty when cfg.Breaker is disabled.
+func (s *emailService) CircuitStates() map[string]CircuitState {
+	return circuitStates(s.breakers)
 }
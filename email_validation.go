@@ -0,0 +1,97 @@
+package sen
+
+import (
+	"fmt"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"unicode"
+
+	"github.com/lugondev/send-sen/dto"
+)
+
+// Tag limits, the strictest of the supported providers (SendGrid categories).
+const (
+	maxTags      = 10
+	maxTagLength = 255
+)
+
+// reservedHeaders are set from the dto.Email fields or by the providers and
+// cannot be given as custom headers.
+var reservedHeaders = map[string]bool{
+	"From":                      true,
+	"Sender":                    true,
+	"Reply-To":                  true,
+	"To":                        true,
+	"Cc":                        true,
+	"Bcc":                       true,
+	"Subject":                   true,
+	"Date":                      true,
+	"Message-Id":                true,
+	"Mime-Version":              true,
+	"Content-Type":              true,
+	"Content-Transfer-Encoding": true,
+}
+
+// validateEmailFields checks the optional sender, Reply-To, headers, tags and
+// metadata of an email before it reaches a provider.
+func validateEmailFields(message dto.Email) error {
+	if message.From != "" {
+		if _, err := mail.ParseAddress(message.From); err != nil {
+			return fmt.Errorf("invalid From address %q: %w", message.From, err)
+		}
+	}
+	if message.ReplyTo != "" {
+		if _, err := mail.ParseAddress(message.ReplyTo); err != nil {
+			return fmt.Errorf("invalid Reply-To address %q: %w", message.ReplyTo, err)
+		}
+	}
+
+	for name, value := range message.Headers {
+		if !isHeaderName(name) {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if reservedHeaders[textproto.CanonicalMIMEHeaderKey(name)] {
+			return fmt.Errorf("header %s cannot be set as a custom header", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("header %s must not contain line breaks", name)
+		}
+	}
+
+	if len(message.Tags) > maxTags {
+		return fmt.Errorf("message can have at most %d tags", maxTags)
+	}
+	for _, tag := range message.Tags {
+		if tag == "" || len(tag) > maxTagLength || hasControl(tag) {
+			return fmt.Errorf("invalid tag %q", tag)
+		}
+	}
+
+	for key, value := range message.Metadata {
+		if key == "" || hasControl(key) {
+			return fmt.Errorf("invalid metadata key %q", key)
+		}
+		if hasControl(value) {
+			return fmt.Errorf("metadata %s must not contain control characters", key)
+		}
+	}
+	return nil
+}
+
+// isHeaderName reports whether name is a valid RFC 5322 field name.
+func isHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r <= ' ' || r > '~' || r == ':' {
+			return false
+		}
+	}
+	return true
+}
+
+func hasControl(s string) bool {
+	return strings.IndexFunc(s, unicode.IsControl) >= 0
+}
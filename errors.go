@@ -0,0 +1,32 @@
+package sen
+
+import (
+	"github.com/lugondev/send-sen/errs"
+	"github.com/lugondev/send-sen/ratelimit"
+)
+
+// Provider failure kinds. Every adapter error wraps one of them, so callers can
+// branch with errors.Is(err, sen.ErrRateLimited) and inspect the provider details
+// with errors.As(err, &providerErr).
+var (
+	// ErrInvalidRecipient means the address, phone number or chat does not exist or cannot receive messages.
+	ErrInvalidRecipient = errs.ErrInvalidRecipient
+	// ErrRateLimited means the provider throttled the request; RetryAfter may carry the requested delay.
+	ErrRateLimited = errs.ErrRateLimited
+	// ErrAuthentication means the credentials were missing, invalid or lack permission, e.g. the account is barred or out of credit.
+	ErrAuthentication = errs.ErrAuthentication
+	// ErrRejected means the provider permanently refused the message; resending it will not help.
+	ErrRejected = errs.ErrRejected
+	// ErrUnsupported means this provider cannot send the message, e.g. one with attachments; another provider may.
+	ErrUnsupported = errs.ErrUnsupported
+	// ErrTransient means a temporary provider or network failure; the message may be resent.
+	ErrTransient = errs.ErrTransient
+)
+
+// ProviderError describes a failed provider call: the provider, the failure kind,
+// the provider's status and error code, and any requested retry delay.
+type ProviderError = errs.ProviderError
+
+// RateLimitError is returned when a send exceeds one of the configured rate limits
+// (cfg.RateLimit). It matches ErrRateLimited and carries the wait in RetryAfter.
+type RateLimitError = ratelimit.Error
@@ -0,0 +1,170 @@
+// Package errs defines the provider failure taxonomy shared by the adapters.
+// The sen package re-exports it; application code should use it from there.
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Failure kinds. Every *ProviderError wraps exactly one of them.
+var (
+	// ErrInvalidRecipient means the address, phone number or chat does not exist or cannot receive messages.
+	ErrInvalidRecipient = errors.New("invalid recipient")
+	// ErrRateLimited means the provider throttled the request; RetryAfter may carry the requested delay.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrAuthentication means the credentials were missing, invalid or lack permission, e.g. the account is barred or out of credit.
+	ErrAuthentication = errors.New("authentication failed")
+	// ErrRejected means the provider permanently refused the message; resending it will not help.
+	ErrRejected = errors.New("rejected")
+	// ErrUnsupported means this provider cannot send the message, e.g. one with attachments; another provider may.
+	ErrUnsupported = errors.New("unsupported")
+	// ErrTransient means a temporary provider or network failure; the message may be resent.
+	ErrTransient = errors.New("transient failure")
+)
+
+// ProviderError describes a failed provider call.
+type ProviderError struct {
+	// Provider is the adapter that failed, e.g. "sendgrid".
+	Provider string
+	// Kind is one of the failure kinds above.
+	Kind error
+	// StatusCode is the HTTP or SMTP status code, when there is one.
+	StatusCode int
+	// Code is the provider specific error code, when there is one.
+	Code string
+	// Message is the provider's error description.
+	Message string
+	// RetryDelay is the wait requested by the provider before resending.
+	RetryDelay time.Duration
+	// Err is the underlying SDK or transport error, if any.
+	Err error
+}
+
+// Error formats the error as "provider: kind (status N, code C): message".
+func (e *ProviderError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Provider)
+	b.WriteString(": ")
+	b.WriteString(e.Kind.Error())
+
+	var details []string
+	if e.StatusCode != 0 {
+		details = append(details, fmt.Sprintf("status %d", e.StatusCode))
+	}
+	if e.Code != "" {
+		details = append(details, "code "+e.Code)
+	}
+	if len(details) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(details, ", "))
+	}
+
+	switch {
+	case e.Message != "":
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+	case e.Err != nil:
+		b.WriteString(": ")
+		b.WriteString(e.Err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap exposes both the failure kind and the underlying error to errors.Is and errors.As.
+func (e *ProviderError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// Temporary reports whether resending the same message may succeed.
+func (e *ProviderError) Temporary() bool {
+	return e.Kind == ErrTransient || e.Kind == ErrRateLimited
+}
+
+// RetryAfter returns the delay requested by the provider, or zero.
+func (e *ProviderError) RetryAfter() time.Duration {
+	return e.RetryDelay
+}
+
+// New returns a ProviderError of the given kind.
+func New(provider string, kind error, message string) *ProviderError {
+	return &ProviderError{Provider: provider, Kind: kind, Message: message}
+}
+
+// FromStatus classifies a failed HTTP response by its status code:
+// 401/403 are authentication failures, 429 is a rate limit, 408/425/5xx are
+// transient and any other status is a permanent rejection. Adapters refine the
+// kind from provider error codes afterwards. The Retry-After header is honored
+// for 429 and 503 responses.
+func FromStatus(provider string, status int, header http.Header, code, message string) *ProviderError {
+	e := &ProviderError{
+		Provider:   provider,
+		Kind:       KindForStatus(status),
+		StatusCode: status,
+		Code:       code,
+		Message:    message,
+	}
+	if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+		e.RetryDelay = ParseRetryAfter(header.Get("Retry-After"))
+	}
+	return e
+}
+
+// KindForStatus maps an HTTP status code onto a failure kind.
+func KindForStatus(status int) error {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrAuthentication
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status == http.StatusRequestTimeout || status == http.StatusTooEarly || status >= 500:
+		return ErrTransient
+	default:
+		return ErrRejected
+	}
+}
+
+// Network wraps a transport failure (DNS, connect, TLS, timeout) of a send
+// with context ctx as transient. Cancellation of ctx is the caller's decision,
+// not a provider failure, so it is returned wrapped but unclassified. A
+// deadline error while ctx is still live comes from the adapter's own
+// timeout: it is reported as transient without exposing the context error, so
+// retries and failover still treat the provider as unavailable.
+func Network(ctx context.Context, provider string, err error) error {
+	switch {
+	case ctx.Err() != nil && !errors.Is(err, ctx.Err()):
+		// The transport failed because of the cancellation, e.g. through a
+		// connection deadline set from ctx.
+		return fmt.Errorf("%s: %w: %w", provider, ctx.Err(), err)
+	case ctx.Err() != nil, errors.Is(err, context.Canceled):
+		return fmt.Errorf("%s: %w", provider, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return &ProviderError{Provider: provider, Kind: ErrTransient, Message: "timed out: " + err.Error()}
+	default:
+		return &ProviderError{Provider: provider, Kind: ErrTransient, Err: err}
+	}
+}
+
+// ParseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func ParseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
@@ -0,0 +1,184 @@
+package sen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	logger "github.com/lugondev/go-log"
+	"github.com/lugondev/send-sen/dto"
+)
+
+// Provider pairs an adapter with the provider name used in logs and errors.
+type Provider[A any] struct {
+	Name    string
+	Adapter A
+}
+
+// failoverChain tries an ordered list of providers until one of them delivers.
+type failoverChain[A any] struct {
+	kind      string
+	providers []Provider[A]
+	logger    logger.Logger
+}
+
+// run calls send with each provider in order and returns the name of the provider that
+// delivered. It stops at the first success, or as soon as an error should not be retried
+// on another provider (e.g. the caller's context is done).
+func (c *failoverChain[A]) run(ctx context.Context, send func(A) error) (string, error) {
+	errs := make([]error, 0, len(c.providers))
+	for i, provider := range c.providers {
+		err := send(provider.Adapter)
+		if err == nil {
+			c.logger.Info(ctx, "Delivered via failover chain", map[string]any{
+				"provider": provider.Name,
+				"attempt":  i + 1,
+			})
+			return provider.Name, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name, err))
+
+		if !shouldFailover(ctx, err) {
+			return "", fmt.Errorf("%s provider %s failed: %w", c.kind, provider.Name, err)
+		}
+		if i < len(c.providers)-1 {
+			c.logger.Warn(ctx, "Provider failed, failing over to next provider", map[string]any{
+				"provider": provider.Name,
+				"next":     c.providers[i+1].Name,
+				"error":    err,
+			})
+		}
+	}
+	return "", fmt.Errorf("all %d %s providers failed: %w", len(c.providers), c.kind, errors.Join(errs...))
+}
+
+// shouldFailover reports whether a provider error warrants trying the next provider.
+// Once the caller's context is done there is no point in trying anyone else. Outages,
+// rate limits, bad credentials and messages the provider cannot send are specific to
+// that provider; an invalid recipient or a rejected message would be refused by every
+// provider. Errors without a failure kind come from the adapter, not the message.
+func shouldFailover(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if IsTransient(err) || errors.Is(err, ErrAuthentication) || errors.Is(err, ErrUnsupported) {
+		return true
+	}
+	var providerErr *ProviderError
+	return !errors.As(err, &providerErr)
+}
+
+func newFailoverChain[A any](kind string, logger logger.Logger, providers []Provider[A]) failoverChain[A] {
+	return failoverChain[A]{
+		kind:      kind,
+		providers: providers,
+		logger: logger.WithFields(map[string]any{
+			"service": kind + "_failover",
+		}),
+	}
+}
+
+// EmailFailover is an EmailAdapter that sends through an ordered list of providers,
+// failing over to the next one when a provider errors.
+type EmailFailover struct {
+	chain failoverChain[EmailAdapter]
+}
+
+// NewEmailFailover creates an EmailFailover trying providers in the given order.
+func NewEmailFailover(logger logger.Logger, providers ...Provider[EmailAdapter]) *EmailFailover {
+	return &EmailFailover{chain: newFailoverChain("email", logger, providers)}
+}
+
+// SendEmail sends the email through the first provider that accepts it.
+func (f *EmailFailover) SendEmail(ctx context.Context, email dto.Email) error {
+	_, err := f.SendEmailWithResult(ctx, email)
+	return err
+}
+
+// SendEmailWithResult sends the email through the first provider that accepts it
+// and returns that provider's result.
+func (f *EmailFailover) SendEmailWithResult(ctx context.Context, email dto.Email) (dto.SendResult, error) {
+	var result dto.SendResult
+	provider, err := f.chain.run(ctx, func(adapter EmailAdapter) error {
+		var err error
+		result, err = sendEmail(ctx, adapter, email)
+		return err
+	})
+	if err != nil {
+		return dto.SendResult{}, err
+	}
+	if result.Provider == "" {
+		result.Provider = provider
+	}
+	return result, nil
+}
+
+// SMSFailover is an SMSAdapter that sends through an ordered list of providers,
+// failing over to the next one when a provider errors.
+type SMSFailover struct {
+	chain failoverChain[SMSAdapter]
+}
+
+// NewSMSFailover creates an SMSFailover trying providers in the given order.
+func NewSMSFailover(logger logger.Logger, providers ...Provider[SMSAdapter]) *SMSFailover {
+	return &SMSFailover{chain: newFailoverChain("sms", logger, providers)}
+}
+
+// Send sends the SMS through the first provider that accepts it.
+func (f *SMSFailover) Send(ctx context.Context, sms dto.SMS) error {
+	_, err := f.SendWithResult(ctx, sms)
+	return err
+}
+
+// SendWithResult sends the SMS through the first provider that accepts it
+// and returns that provider's result.
+func (f *SMSFailover) SendWithResult(ctx context.Context, sms dto.SMS) (dto.SendResult, error) {
+	var result dto.SendResult
+	provider, err := f.chain.run(ctx, func(adapter SMSAdapter) error {
+		var err error
+		result, err = sendSMS(ctx, adapter, sms)
+		return err
+	})
+	if err != nil {
+		return dto.SendResult{}, err
+	}
+	if result.Provider == "" {
+		result.Provider = provider
+	}
+	return result, nil
+}
+
+// NotifyFailover is a NotifyAdapter that sends through an ordered list of channels,
+// failing over to the next one when a channel errors.
+type NotifyFailover struct {
+	chain failoverChain[NotifyAdapter]
+}
+
+// NewNotifyFailover creates a NotifyFailover trying channels in the given order.
+func NewNotifyFailover(logger logger.Logger, providers ...Provider[NotifyAdapter]) *NotifyFailover {
+	return &NotifyFailover{chain: newFailoverChain("notify", logger, providers)}
+}
+
+// Send sends the notification through the first channel that accepts it.
+func (f *NotifyFailover) Send(ctx context.Context, content dto.Content) error {
+	_, err := f.SendWithResult(ctx, content)
+	return err
+}
+
+// SendWithResult sends the notification through the first channel that accepts it
+// and returns that channel's result.
+func (f *NotifyFailover) SendWithResult(ctx context.Context, content dto.Content) (dto.SendResult, error) {
+	var result dto.SendResult
+	provider, err := f.chain.run(ctx, func(adapter NotifyAdapter) error {
+		var err error
+		result, err = sendNotify(ctx, adapter, content)
+		return err
+	})
+	if err != nil {
+		return dto.SendResult{}, err
+	}
+	if result.Provider == "" {
+		result.Provider = provider
+	}
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package sen
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dedup"
+	"github.com/lugondev/send-sen/dto"
+)
+
+// deduper suppresses repeated sends of the same idempotency key. Concurrent
+// sends of one key are coalesced in process; the store catches repeats across
+// calls (and, for shared stores, across processes). Only successful results
+// are remembered, so a failed send can be retried with the same key.
+type deduper struct {
+	store  dedup.Store
+	window time.Duration
+	logger logger.Logger
+
+	mu       sync.Mutex
+	inflight map[string]*dedupCall
+}
+
+type dedupCall struct {
+	done   chan struct{}
+	result dto.SendResult
+	err    error
+}
+
+// newDeduper creates a deduper backed by store, or by the store selected by cfg if store is nil.
+func newDeduper(cfg config.IdempotencyConfig, store dedup.Store, logger logger.Logger) (*deduper, error) {
+	if store == nil {
+		var err error
+		if store, err = dedup.NewStore(cfg); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = dedup.DefaultWindow
+	}
+	return &deduper{
+		store:    store,
+		window:   cfg.Window,
+		logger:   logger,
+		inflight: make(map[string]*dedupCall),
+	}, nil
+}
+
+// do calls send unless key was already sent successfully within the window,
+// in which case the original result is returned. Keys are namespaced by scope
+// (the message kind). An empty key always sends.
+func (d *deduper) do(ctx context.Context, scope, key string, send func() (dto.SendResult, error)) (dto.SendResult, error) {
+	if key == "" {
+		return send()
+	}
+	key = scope + ":" + key
+
+	d.mu.Lock()
+	if call, ok := d.inflight[key]; ok {
+		d.mu.Unlock()
+		select {
+		case <-call.done:
+			d.logger.Info(ctx, "Duplicate send coalesced with an in-flight send", map[string]any{"idempotency_key": key})
+			return call.result, call.err
+		case <-ctx.Done():
+			return dto.SendResult{}, ctx.Err()
+		}
+	}
+	call := &dedupCall{done: make(chan struct{})}
+	d.inflight[key] = call
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		delete(d.inflight, key)
+		d.mu.Unlock()
+		close(call.done)
+	}()
+
+	result, found, err := d.store.Get(ctx, key)
+	if err != nil {
+		// Sending twice is better than not sending at all.
+		d.logger.Warn(ctx, "Idempotency store lookup failed, sending anyway", map[string]any{"idempotency_key": key, "error": err})
+	}
+	if found {
+		d.logger.Info(ctx, "Duplicate send suppressed", map[string]any{"idempotency_key": key, "message_id": result.MessageID})
+		call.result = result
+		return result, nil
+	}
+
+	call.result, call.err = send()
+	if call.err == nil {
+		if err := d.store.Put(ctx, key, call.result, d.window); err != nil {
+			d.logger.Warn(ctx, "Failed to record idempotency key", map[string]any{"idempotency_key": key, "error": err})
+		}
+	}
+	return call.result, call.err
+}
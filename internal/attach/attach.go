@@ -0,0 +1,43 @@
+// Package attach reads email attachments and resolves their content type. The
+// email service and the email adapters share it, so an attachment is treated
+// the same whether it goes through the service, the outbox or an adapter used
+// on its own.
+package attach
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"path"
+
+	"github.com/lugondev/send-sen/dto"
+)
+
+// Content returns the content of an attachment, reading its Reader when
+// Content is not set. A positive limit caps how many bytes are read.
+func Content(attachment dto.Attachment, limit int64) ([]byte, error) {
+	if attachment.Content != nil || attachment.Reader == nil {
+		return attachment.Content, nil
+	}
+	reader := attachment.Reader
+	if limit > 0 {
+		reader = io.LimitReader(reader, limit)
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment %s: %w", attachment.Filename, err)
+	}
+	return content, nil
+}
+
+// ContentType returns the MIME type of an attachment, guessed from its file
+// name when not set.
+func ContentType(attachment dto.Attachment) string {
+	if attachment.ContentType != "" {
+		return attachment.ContentType
+	}
+	if contentType := mime.TypeByExtension(path.Ext(attachment.Filename)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
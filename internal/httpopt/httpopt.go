@@ -0,0 +1,65 @@
+// Package httpopt implements the HTTP client and base URL options shared by
+// the provider adapters. The adapter packages re-export them.
+package httpopt
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option customizes an HTTP provider adapter.
+type Option func(*Options)
+
+// Options holds the settings applied by the options.
+type Options struct {
+	// Client is the client to send through, copied before use; nil for a new one.
+	Client *http.Client
+	// BaseURL overrides the API base URL from the config when set.
+	BaseURL string
+	// DefaultTimeout bounds a request when neither the config nor Client sets a timeout.
+	DefaultTimeout time.Duration
+}
+
+// New applies opts, with the adapter package's default timeout.
+func New(opts []Option, defaultTimeout time.Duration) Options {
+	o := Options{DefaultTimeout: defaultTimeout}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithHTTPClient sends the provider requests through client, e.g. one with a
+// proxy, client certificates, custom CAs or request logging. The provider
+// timeout from the config still applies; when the config sets none, the
+// client's own timeout is kept.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *Options) {
+		o.Client = client
+	}
+}
+
+// WithBaseURL overrides the API base URL from the config, e.g. to point the
+// adapter at a local fake.
+func WithBaseURL(baseURL string) Option {
+	return func(o *Options) {
+		o.BaseURL = baseURL
+	}
+}
+
+// HTTPClient returns the client for a provider API with the given timeout,
+// the client's own or the default one.
+func (o Options) HTTPClient(timeout time.Duration) *http.Client {
+	client := &http.Client{}
+	if o.Client != nil {
+		copied := *o.Client
+		client = &copied
+	}
+	switch {
+	case timeout > 0:
+		client.Timeout = timeout
+	case client.Timeout == 0:
+		client.Timeout = o.DefaultTimeout
+	}
+	return client
+}
@@ -0,0 +1,20 @@
+package sen
+
+import "context"
+
+type localeKey struct{}
+
+// WithLocale returns a context whose sends use locale ("vi-VN", "vi", "en",
+// ...) for the built-in templates, subjects and SMS texts. Missing
+// translations fall back to the parent language (vi-VN -> vi), then to the
+// cfg.Templates locale, then to the root templates.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext returns the locale set by WithLocale, or "" for the
+// default locale.
+func LocaleFromContext(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
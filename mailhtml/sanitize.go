@@ -0,0 +1,134 @@
+package mailhtml
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedElements lists the elements Sanitize keeps and the attributes each
+// may carry. Anything else is unwrapped: its tags are removed but its content
+// kept.
+var allowedElements = map[string]map[string]bool{
+	"a":          {"href": true, "title": true},
+	"b":          {},
+	"blockquote": {},
+	"br":         {},
+	"code":       {},
+	"div":        {},
+	"em":         {},
+	"h1":         {},
+	"h2":         {},
+	"h3":         {},
+	"h4":         {},
+	"h5":         {},
+	"h6":         {},
+	"hr":         {},
+	"i":          {},
+	"img":        {"src": true, "alt": true, "title": true, "width": true, "height": true},
+	"li":         {},
+	"ol":         {"start": true},
+	"p":          {},
+	"pre":        {},
+	"s":          {},
+	"small":      {},
+	"span":       {},
+	"strong":     {},
+	"sub":        {},
+	"sup":        {},
+	"table":      {},
+	"tbody":      {},
+	"td":         {"colspan": true, "rowspan": true},
+	"th":         {"colspan": true, "rowspan": true},
+	"thead":      {},
+	"tr":         {},
+	"u":          {},
+	"ul":         {},
+}
+
+// droppedElements are removed together with their content.
+var droppedElements = map[string]bool{
+	"script": true, "style": true, "template": true, "noscript": true, "iframe": true,
+	"object": true, "embed": true, "head": true, "title": true, "textarea": true,
+	"select": true, "svg": true, "math": true,
+}
+
+// voidElements have no end tag.
+var voidElements = map[string]bool{"br": true, "hr": true, "img": true}
+
+// URL schemes allowed in links and image sources. Relative URLs are dropped
+// too, since they have no base in an email.
+var (
+	linkSchemes  = map[string]bool{"http": true, "https": true, "mailto": true}
+	imageSchemes = map[string]bool{"http": true, "https": true, "cid": true}
+)
+
+// Sanitize returns a safe version of a user-supplied HTML fragment, such as a
+// comment or a profile bio, to embed into an email. Only basic formatting,
+// lists, tables, links and images are kept; styles, event handlers, scripts,
+// forms and URLs with other schemes (javascript:, data:) are removed.
+//
+// Templates can call it as {{sanitize .Bio}}.
+func Sanitize(fragment string) string {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), context)
+	if err != nil {
+		// The parser only fails on reader errors, which strings.Reader never returns.
+		return html.EscapeString(fragment)
+	}
+
+	var buf strings.Builder
+	for _, n := range nodes {
+		sanitizeNode(&buf, n)
+	}
+	return buf.String()
+}
+
+func sanitizeNode(buf *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		buf.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		// Comments and doctypes are dropped.
+		return
+	}
+
+	if droppedElements[n.Data] {
+		return
+	}
+	attributes, allowed := allowedElements[n.Data]
+	if !allowed {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			sanitizeNode(buf, c)
+		}
+		return
+	}
+
+	buf.WriteString("<" + n.Data)
+	for _, a := range n.Attr {
+		if a.Namespace != "" || !attributes[a.Key] {
+			continue
+		}
+		if (a.Key == "href" && !allowedURL(a.Val, linkSchemes)) || (a.Key == "src" && !allowedURL(a.Val, imageSchemes)) {
+			continue
+		}
+		buf.WriteString(" " + a.Key + `="` + html.EscapeString(a.Val) + `"`)
+	}
+	buf.WriteString(">")
+	if voidElements[n.Data] {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sanitizeNode(buf, c)
+	}
+	buf.WriteString("</" + n.Data + ">")
+}
+
+func allowedURL(value string, schemes map[string]bool) bool {
+	u, err := url.Parse(strings.TrimSpace(value))
+	return err == nil && schemes[strings.ToLower(u.Scheme)]
+}
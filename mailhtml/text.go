@@ -0,0 +1,335 @@
+// Package mailhtml converts email HTML to plain text and sanitizes
+// user-supplied HTML fragments before they are embedded into templates.
+package mailhtml
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// skippedElements have no readable content.
+var skippedElements = map[string]bool{
+	"head": true, "title": true, "script": true, "style": true, "template": true,
+	"noscript": true, "iframe": true, "object": true, "svg": true,
+}
+
+// Block elements start a new line; paragraph-like ones are also separated from
+// their neighbours by a blank line.
+var (
+	paragraphElements = map[string]bool{
+		"p": true, "blockquote": true, "pre": true, "table": true, "dl": true,
+		"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	}
+	lineElements = map[string]bool{
+		"div": true, "section": true, "article": true, "header": true, "footer": true,
+		"main": true, "nav": true, "aside": true, "address": true, "figure": true,
+		"figcaption": true, "form": true, "fieldset": true, "center": true,
+		"tr": true, "dt": true, "dd": true, "caption": true,
+	}
+)
+
+// ToText renders an HTML document or fragment as plain text for the text/plain
+// part of an email:
+//
+//   - paragraphs, headings and tables are separated by blank lines, and h1/h2
+//     headings are underlined;
+//   - list items start with "* " or their number, nested lists are indented;
+//   - table rows become lines with their cells separated by " | ";
+//   - links become "text [1]" with the URLs listed as footnotes at the end,
+//     unless the text already is the URL;
+//   - images are replaced by their alt text, and scripts and styles are dropped.
+func ToText(document string) string {
+	root, err := html.Parse(strings.NewReader(document))
+	if err != nil {
+		// The parser only fails on reader errors, which strings.Reader never returns.
+		return strings.TrimSpace(document)
+	}
+
+	w := &textWriter{}
+	w.children(root)
+	if len(w.links) > 0 {
+		w.block(2)
+		for i, link := range w.links {
+			w.block(1)
+			w.word("[" + strconv.Itoa(i+1) + "] " + link)
+		}
+	}
+
+	lines := strings.Split(w.buf.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// textWriter accumulates words and line breaks. Breaks are only written once
+// more text follows, so consecutive blocks never produce runs of blank lines.
+type textWriter struct {
+	buf        strings.Builder
+	prefixes   []string // Written at the start of every line: list indentation and quote markers
+	linePrefix string   // Prefix of the current line
+	newlines   int      // Line breaks to write before the next word
+	space      bool     // Whether the next word is separated by a space
+	pre        int      // Depth of <pre> elements, whose whitespace is kept
+	lists      []*list
+	links      []string
+}
+
+type list struct {
+	ordered bool
+	next    int
+}
+
+// block ends the current line and, with n = 2, leaves a blank line.
+func (w *textWriter) block(n int) {
+	w.newlines = max(w.newlines, n)
+	w.space = false
+}
+
+// startLine writes the pending line breaks and the line prefix.
+func (w *textWriter) startLine() {
+	if w.buf.Len() == 0 {
+		w.newlines = 0
+		w.linePrefix = strings.Join(w.prefixes, "")
+		w.buf.WriteString(w.linePrefix)
+		return
+	}
+	if w.newlines == 0 {
+		return
+	}
+	prefix := strings.Join(w.prefixes, "")
+	// Blank lines keep the quote markers shared with the previous line, so
+	// quoted paragraphs stay quoted.
+	shared := 0
+	for shared < len(prefix) && shared < len(w.linePrefix) && prefix[shared] == w.linePrefix[shared] {
+		shared++
+	}
+	for i := 1; i < w.newlines; i++ {
+		w.buf.WriteString("\n" + strings.TrimRight(prefix[:shared], " "))
+	}
+	w.buf.WriteString("\n" + prefix)
+	w.linePrefix = prefix
+	w.newlines = 0
+	w.space = false
+}
+
+// word writes text that must not be broken.
+func (w *textWriter) word(text string) {
+	if w.buf.Len() == 0 || w.newlines > 0 {
+		w.startLine()
+	} else if w.space {
+		w.buf.WriteByte(' ')
+	}
+	w.buf.WriteString(text)
+	w.space = false
+}
+
+// text writes a text node, collapsing whitespace outside <pre>.
+func (w *textWriter) text(text string) {
+	if w.pre > 0 {
+		for i, line := range strings.Split(text, "\n") {
+			if i > 0 {
+				w.newlines++
+			}
+			if line != "" {
+				w.startLine()
+				w.buf.WriteString(line)
+			}
+		}
+		return
+	}
+
+	if text != "" && strings.TrimLeft(text, " \t\r\n\f") != text {
+		w.space = true
+	}
+	for _, field := range strings.Fields(text) {
+		w.word(field)
+		w.space = true
+	}
+	if strings.TrimRight(text, " \t\r\n\f") == text {
+		w.space = false
+	}
+}
+
+func (w *textWriter) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.node(c)
+	}
+}
+
+func (w *textWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.DocumentNode:
+		w.children(n)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	tag := n.Data
+	switch {
+	case skippedElements[tag]:
+	case tag == "br":
+		if w.buf.Len() > 0 {
+			w.newlines = min(w.newlines+1, 2)
+			w.space = false
+		}
+	case tag == "hr":
+		w.block(2)
+		w.word("----------")
+		w.block(2)
+	case tag == "img":
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			w.text(alt)
+		}
+	case tag == "a":
+		w.link(n)
+	case tag == "ul" || tag == "ol":
+		w.list(n, tag == "ol")
+	case tag == "li":
+		w.listItem(n)
+	case tag == "tr":
+		w.row(n)
+	case tag == "blockquote":
+		w.block(2)
+		w.prefixes = append(w.prefixes, "> ")
+		w.children(n)
+		w.prefixes = w.prefixes[:len(w.prefixes)-1]
+		w.block(2)
+	case tag == "pre":
+		w.block(2)
+		w.pre++
+		w.children(n)
+		w.pre--
+		w.block(2)
+	case tag == "h1" || tag == "h2":
+		w.heading(n, map[string]string{"h1": "=", "h2": "-"}[tag])
+	case paragraphElements[tag]:
+		w.block(2)
+		w.children(n)
+		w.block(2)
+	case lineElements[tag]:
+		w.block(1)
+		w.children(n)
+		w.block(1)
+	default:
+		w.children(n)
+	}
+}
+
+// heading underlines the heading text with the given character.
+func (w *textWriter) heading(n *html.Node, underline string) {
+	w.block(2)
+	start := w.buf.Len()
+	w.children(n)
+	if w.buf.Len() > start {
+		written := w.buf.String()[start:]
+		line := written[strings.LastIndex(written, "\n")+1:]
+		line = strings.TrimPrefix(line, strings.Join(w.prefixes, ""))
+		w.block(1)
+		w.word(strings.Repeat(underline, utf8.RuneCountInString(line)))
+	}
+	w.block(2)
+}
+
+func (w *textWriter) link(n *html.Node) {
+	href := strings.TrimSpace(attr(n, "href"))
+	start := w.buf.Len()
+	w.children(n)
+	text := strings.TrimSpace(w.buf.String()[start:])
+
+	switch {
+	case href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:"):
+	case text == "":
+		w.word(href)
+	case text == href || "mailto:"+text == href || strings.TrimSuffix(href, "/") == text:
+	default:
+		w.space = true
+		w.word("[" + strconv.Itoa(w.footnote(href)) + "]")
+	}
+}
+
+// footnote returns the number of the footnote for href, adding it if needed.
+func (w *textWriter) footnote(href string) int {
+	for i, link := range w.links {
+		if link == href {
+			return i + 1
+		}
+	}
+	w.links = append(w.links, href)
+	return len(w.links)
+}
+
+func (w *textWriter) list(n *html.Node, ordered bool) {
+	if len(w.lists) == 0 {
+		w.block(2)
+	} else {
+		w.block(1)
+	}
+	next := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+		next = start
+	}
+	w.lists = append(w.lists, &list{ordered: ordered, next: next})
+	w.children(n)
+	w.lists = w.lists[:len(w.lists)-1]
+	if len(w.lists) == 0 {
+		w.block(2)
+	} else {
+		w.block(1)
+	}
+}
+
+func (w *textWriter) listItem(n *html.Node) {
+	marker := "*"
+	if len(w.lists) > 0 {
+		if current := w.lists[len(w.lists)-1]; current.ordered {
+			marker = strconv.Itoa(current.next) + "."
+			current.next++
+		}
+	}
+	w.block(1)
+	w.word(marker)
+	w.space = true
+	// Continuation lines line up with the text after the marker.
+	w.prefixes = append(w.prefixes, strings.Repeat(" ", len(marker)+1))
+	w.children(n)
+	w.prefixes = w.prefixes[:len(w.prefixes)-1]
+	w.block(1)
+}
+
+// row writes a table row on one line, with its cells separated by " | ".
+func (w *textWriter) row(n *html.Node) {
+	w.block(1)
+	cells := 0
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || (c.Data != "td" && c.Data != "th") {
+			w.node(c)
+			continue
+		}
+		if cells > 0 {
+			w.space = true
+			w.word("|")
+			w.space = true
+		}
+		cells++
+		w.children(c)
+	}
+	w.block(1)
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
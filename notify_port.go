@@ -11,11 +11,19 @@ type NotifyAdapter interface {
 	Send(ctx context.Context, content dto.Content) error
 }
 
+// NotifyResultAdapter is implemented by notify adapters that report the provider's send result.
+type NotifyResultAdapter interface {
+	NotifyAdapter
+	SendWithResult(ctx context.Context, content dto.Content) (dto.SendResult, error)
+}
+
 // NotifyService defines the core logic for handling notifications.
 type NotifyService interface {
 	Send(ctx context.Context, content dto.Content) error
+	SendWithResult(ctx context.Context, content dto.Content) (dto.SendResult, error)
 	Alert(ctx context.Context, subject, message string) error
 	Info(ctx context.Context, subject, message string) error
 	Notify(ctx context.Context, subject, message string, level dto.Level) error
 	ServiceName() string
+	CircuitStates() map[string]CircuitState
 }
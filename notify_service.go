@@ -3,12 +3,15 @@ package sen
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
 
 	adapter "github.com/lugondev/send-sen/adapters/notify"
 	"github.com/lugondev/send-sen/dto"
 
 	logger "github.com/lugondev/go-log"
 	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/ratelimit"
 )
 
 // notifyService implements the Service interface.
@@ -17,67 +20,164 @@ type notifyService struct {
 	adapter NotifyAdapter
 	logger  logger.Logger
 	name    config.NotifyChannel
+	dedup   *deduper
+	limits  rateLimits
+	// breakers holds the circuit breaker of each provider, for CircuitStates.
+	breakers map[string]interface{ State() CircuitState }
 }
 
 // NewNotifyService creates a new instance of Service.
-func NewNotifyService(cfg config.Config, logger logger.Logger) (NotifyService, error) {
+// Each channel is wrapped with the cfg.Retry policy and the cfg.Breaker circuit
+// breaker, and when several channels are configured (cfg.Adapter.NotifyChain) they
+// are wrapped in a NotifyFailover, in order.
+// Messages with an IdempotencyKey are deduplicated through the cfg.Idempotency store,
+// and sends are throttled by the cfg.RateLimit limits.
+func NewNotifyService(cfg config.Config, logger logger.Logger, opts ...ServiceOption) (NotifyService, error) {
 	ctx := context.Background()
+	options := newServiceOptions(opts)
+	limits := newRateLimits("notify", cfg.RateLimit, ratelimit.New(options.rateLimitStore))
+	breakers := make(map[string]interface{ State() CircuitState })
 	logger.Debug(ctx, "Registered notify adapter", map[string]any{
-		"channel": cfg.Adapter.Notify,
+		"channel": cfg.Adapter.NotifyChannels(),
 	})
 
-	var notifyAdapter NotifyAdapter
-	if cfg.Adapter.Notify == config.NotifyTelegram {
-		telegramAdapter, err := adapter.NewTelegramAdapter(cfg.Telegram, logger)
+	var providers []Provider[NotifyAdapter]
+	var names []string
+	for _, channel := range cfg.Adapter.NotifyChannels() {
+		notifyAdapter, err := newNotifyAdapter(cfg, channel, logger, options.httpClient(string(channel)))
 		if err != nil {
-			logger.Error(ctx, "Failed to create Telegram adapter", map[string]any{
-				"error": err,
-			})
-			return nil, fmt.Errorf("failed to create Telegram adapter: %w", err)
-		} else {
-			notifyAdapter = telegramAdapter
-			logger.Info(ctx, "Using Telegram adapter for notifications", map[string]any{
-				"chat_id": cfg.Telegram.ChatID,
+			logger.Error(ctx, "Failed to create notify adapter", map[string]any{
+				"channel": channel,
+				"error":   err,
 			})
+			return nil, err
 		}
-	}
-	if notifyAdapter == nil {
-		notifyAdapter = adapter.NewMockLogAdapter(logger)
-		logger.Info(ctx, "Using MockLog adapter for notifications", map[string]any{
-			"channel": cfg.Adapter.Notify,
+		if cfg.Retry.MaxAttempts > 1 {
+			notifyAdapter = NewNotifyRetry(notifyAdapter, cfg.Retry, logger)
+		}
+		if cfg.Breaker.FailureRatio > 0 {
+			breaker := NewNotifyCircuitBreaker(notifyAdapter, string(channel), cfg.Breaker, logger)
+			breakers[string(channel)] = breaker
+			notifyAdapter = breaker
+		}
+		if limit, ok := limits.providerLimit(string(channel)); ok {
+			notifyAdapter = NewNotifyRateLimit(notifyAdapter, limits.limiter, string(channel), limit)
+		}
+		logger.Info(ctx, "Using notify adapter for notifications", map[string]any{
+			"channel": channel,
 		})
+		providers = append(providers, Provider[NotifyAdapter]{Name: string(channel), Adapter: notifyAdapter})
+		names = append(names, string(channel))
+	}
+
+	var notifyAdapter NotifyAdapter
+	switch len(providers) {
+	case 0:
+		return nil, fmt.Errorf("no notify channel configured: set adapter.notify or adapter.notifyChain (%q for testing)", config.NotifyMock)
+	case 1:
+		notifyAdapter = providers[0].Adapter
+	default:
+		notifyAdapter = NewNotifyFailover(logger, providers...)
+	}
+	name := config.NotifyChannel(strings.Join(names, ","))
+
+	serviceLogger := logger.WithFields(map[string]any{
+		"service": "notify_service_" + name,
+	})
+	dedup, err := newDeduper(cfg.Idempotency, options.dedupStore, serviceLogger)
+	if err != nil {
+		return nil, err
 	}
 
 	return &notifyService{
-		adapter: notifyAdapter,
-		logger: logger.WithFields(map[string]any{
-			"service": "notify_service_" + cfg.Adapter.Notify,
-		}),
-		name: cfg.Adapter.Notify,
+		adapter:  notifyAdapter,
+		logger:   serviceLogger,
+		name:     name,
+		dedup:    dedup,
+		limits:   limits,
+		breakers: breakers,
 	}, nil
 }
 
+// newNotifyAdapter creates the adapter for a single notify channel, sending
+// through httpClient when it is not nil.
+func newNotifyAdapter(cfg config.Config, channel config.NotifyChannel, logger logger.Logger, httpClient *http.Client) (NotifyAdapter, error) {
+	var opts []adapter.Option
+	if httpClient != nil {
+		opts = append(opts, adapter.WithHTTPClient(httpClient))
+	}
+	switch channel {
+	case config.NotifyTelegram:
+		telegramAdapter, err := adapter.NewTelegramAdapter(cfg.Telegram, logger, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Telegram adapter: %w", err)
+		}
+		return telegramAdapter, nil
+	case config.NotifySlack:
+		slackAdapter, err := adapter.NewSlackAdapter(cfg.Slack, logger, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Slack adapter: %w", err)
+		}
+		return slackAdapter, nil
+	case config.NotifyDiscord:
+		discordAdapter, err := adapter.NewDiscordAdapter(cfg.Discord, logger, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Discord adapter: %w", err)
+		}
+		return discordAdapter, nil
+	case config.NotifyTeams:
+		teamsAdapter, err := adapter.NewTeamsAdapter(cfg.Teams, logger, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Teams adapter: %w", err)
+		}
+		return teamsAdapter, nil
+	case config.NotifyMock:
+		return adapter.NewMockLogAdapter(logger), nil
+	default:
+		return nil, fmt.Errorf("unsupported notify channel %q", channel)
+	}
+}
+
 // Send finds the appropriate adapter based on the notification's channel
 func (s *notifyService) Send(ctx context.Context, content dto.Content) error {
+	_, err := s.SendWithResult(ctx, content)
+	return err
+}
+
+// SendWithResult sends the notification and returns the channel's send result.
+func (s *notifyService) SendWithResult(ctx context.Context, content dto.Content) (dto.SendResult, error) {
 	if content.Message == "" {
-		return fmt.Errorf("notification message cannot be empty")
+		return dto.SendResult{}, fmt.Errorf("notification message cannot be empty")
 	}
 
-	s.logger.Info(ctx, "Sending notification via adapter", map[string]any{
-		"sub": content.Subject, // Subject might be empty
-		"msg": content.Message,
-	})
+	return s.dedup.do(ctx, "notify", content.IdempotencyKey, func() (dto.SendResult, error) {
+		// Notifications go to a fixed channel, so only the global limit applies.
+		if err := s.limits.allow(ctx); err != nil {
+			return dto.SendResult{}, err
+		}
 
-	err := s.adapter.Send(ctx, content)
-	if err != nil {
-		s.logger.Error(ctx, "Failed to send notification", map[string]any{
-			"error": err,
+		s.logger.Info(ctx, "Sending notification via adapter", map[string]any{
+			"sub": content.Subject, // Subject might be empty
+			"msg": content.Message,
 		})
-		return fmt.Errorf("failed to send notification: %w", err)
-	}
 
-	s.logger.Info(ctx, "Notification sent successfully")
-	return nil
+		result, err := sendNotify(ctx, s.adapter, content)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to send notification", map[string]any{
+				"error": err,
+			})
+			return dto.SendResult{}, fmt.Errorf("failed to send notification: %w", err)
+		}
+		if result.Provider == "" {
+			result.Provider = string(s.name)
+		}
+
+		s.logger.Info(ctx, "Notification sent successfully", map[string]any{
+			"provider":   result.Provider,
+			"message_id": result.MessageID,
+		})
+		return result, nil
+	})
 }
 
 // Alert sends a notification with Error level
@@ -114,3 +214,9 @@ func (s *notifyService) Notify(ctx context.Context, subject, message string, lev
 func (s *notifyService) ServiceName() string {
 	return string(s.name)
 }
+
+// CircuitStates reports the circuit breaker state of each provider, for health checks.
+// It is empty when cfg.Breaker is disabled.
+func (s *notifyService) CircuitStates() map[string]CircuitState {
+	return circuitStates(s.breakers)
+}
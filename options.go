@@ -0,0 +1,81 @@
+package sen
+
+import (
+	"net/http"
+
+	"github.com/lugondev/send-sen/dedup"
+	"github.com/lugondev/send-sen/ratelimit"
+	"github.com/lugondev/send-sen/templates"
+)
+
+// ServiceOption customizes NewEmailService, NewSMSService and NewNotifyService.
+type ServiceOption func(*serviceOptions)
+
+type serviceOptions struct {
+	dedupStore     dedup.Store
+	rateLimitStore ratelimit.Store
+	templates      *templates.Registry
+	httpClients    map[string]*http.Client // Keyed by provider name, "" for every provider
+}
+
+func newServiceOptions(opts []ServiceOption) serviceOptions {
+	var o serviceOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithDedupStore replaces the idempotency store selected by cfg.Idempotency,
+// e.g. with one shared by several instances.
+func WithDedupStore(store dedup.Store) ServiceOption {
+	return func(o *serviceOptions) {
+		o.dedupStore = store
+	}
+}
+
+// WithRateLimitStore replaces the default in-memory rate limit store, e.g. with
+// one shared by several instances so limits hold across them.
+func WithRateLimitStore(store ratelimit.Store) ServiceOption {
+	return func(o *serviceOptions) {
+		o.rateLimitStore = store
+	}
+}
+
+// WithTemplates makes NewEmailService and NewSMSService render templates and
+// messages from registry instead of loading cfg.Templates, e.g. to serve templates embedded in the application:
+//
+//	registry, err := templates.Load(templates.Funcs(cfg.Templates), templates.Builtin(), appTemplates)
+func WithTemplates(registry *templates.Registry) ServiceOption {
+	return func(o *serviceOptions) {
+		o.templates = registry
+	}
+}
+
+// WithHTTPClient makes the HTTP provider adapters send their requests through
+// client, e.g. one with a corporate proxy, client certificates or a custom CA
+// bundle. It applies to the named providers ("sendgrid", "twilio",
+// "telegram", ...), or to every provider when none is named; a named client
+// wins over the one for every provider. Provider timeouts from cfg still apply.
+// API base URLs are set in cfg, e.g. cfg.SendGrid.BaseURL.
+func WithHTTPClient(client *http.Client, providers ...string) ServiceOption {
+	return func(o *serviceOptions) {
+		if o.httpClients == nil {
+			o.httpClients = make(map[string]*http.Client)
+		}
+		if len(providers) == 0 {
+			o.httpClients[""] = client
+		}
+		for _, provider := range providers {
+			o.httpClients[provider] = client
+		}
+	}
+}
+
+// httpClient returns the HTTP client for provider, or nil for the adapter's default.
+func (o serviceOptions) httpClient(provider string) *http.Client {
+	if client, ok := o.httpClients[provider]; ok {
+		return client
+	}
+	return o.httpClients[""]
+}
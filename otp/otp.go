@@ -0,0 +1,269 @@
+// Package otp issues and verifies one-time passwords delivered through the
+// SMS and email services.
+package otp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/config"
+)
+
+// Default OTP settings, used for zero fields of config.OTPConfig.
+const (
+	defaultLength         = 6
+	defaultTTL            = 10 * time.Minute
+	defaultMaxAttempts    = 5
+	defaultResendCooldown = time.Minute
+)
+
+// Code alphabets. The alphanumeric one leaves out 0, 1, I and O, which are easily misread.
+const (
+	numericAlphabet      = "0123456789"
+	alphanumericAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+)
+
+// Verification and issuing errors.
+var (
+	// ErrCooldown means a code was sent to the recipient too recently.
+	ErrCooldown = errors.New("otp: resend cooldown in effect")
+	// ErrNoCode means no code is pending for the recipient.
+	ErrNoCode = errors.New("otp: no pending code")
+	// ErrExpired means the pending code outlived its TTL.
+	ErrExpired = errors.New("otp: code expired")
+	// ErrInvalidCode means the code does not match.
+	ErrInvalidCode = errors.New("otp: invalid code")
+	// ErrTooManyAttempts means the code was locked after too many wrong guesses.
+	ErrTooManyAttempts = errors.New("otp: too many attempts")
+)
+
+// Channel is how a code is delivered.
+type Channel string
+
+const (
+	ChannelSMS   Channel = "sms"
+	ChannelEmail Channel = "email"
+)
+
+// Manager generates, delivers and verifies one-time passwords.
+type Manager struct {
+	cfg      config.OTPConfig
+	alphabet string
+	store    Store
+	sms      sen.SMSService
+	email    sen.EmailService
+	logger   logger.Logger
+
+	// mu serializes read-modify-write cycles on the store.
+	mu sync.Mutex
+}
+
+// NewManager creates a Manager that keeps codes in store and delivers them
+// through the given services. Either service may be nil if its channel is unused.
+func NewManager(cfg config.OTPConfig, store Store, sms sen.SMSService, email sen.EmailService, logger logger.Logger) (*Manager, error) {
+	if store == nil {
+		return nil, fmt.Errorf("otp store is required")
+	}
+	if sms == nil && email == nil {
+		return nil, fmt.Errorf("an SMS or email service is required")
+	}
+
+	var alphabet string
+	switch cfg.Charset {
+	case "", config.OTPCharsetNumeric:
+		alphabet = numericAlphabet
+	case config.OTPCharsetAlphanumeric:
+		alphabet = alphanumericAlphabet
+	default:
+		return nil, fmt.Errorf("unsupported otp charset: %s", cfg.Charset)
+	}
+	if cfg.Length <= 0 {
+		cfg.Length = defaultLength
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.ResendCooldown <= 0 {
+		cfg.ResendCooldown = defaultResendCooldown
+	}
+
+	return &Manager{
+		cfg:      cfg,
+		alphabet: alphabet,
+		store:    store,
+		sms:      sms,
+		email:    email,
+		logger: logger.WithFields(map[string]any{
+			"service": "otp",
+		}),
+	}, nil
+}
+
+// Send generates a new code for the recipient, replacing any pending one, and
+// delivers it with the configured TTL stated in the message. It fails with
+// ErrCooldown if the previous code was sent less than ResendCooldown ago.
+func (m *Manager) Send(ctx context.Context, channel Channel, to string) error {
+	if err := m.checkChannel(channel); err != nil {
+		return err
+	}
+	key := recordKey(channel, to)
+
+	m.mu.Lock()
+	now := time.Now()
+	previous, found, err := m.store.Get(ctx, key)
+	if err != nil {
+		m.mu.Unlock()
+		return fmt.Errorf("failed to load otp record: %w", err)
+	}
+	if found {
+		if wait := previous.SentAt.Add(m.cfg.ResendCooldown).Sub(now); wait > 0 {
+			m.mu.Unlock()
+			return fmt.Errorf("%w: retry in %s", ErrCooldown, wait.Round(time.Second))
+		}
+	}
+
+	code, err := m.generate()
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		m.mu.Unlock()
+		return fmt.Errorf("failed to generate otp salt: %w", err)
+	}
+	record := Record{
+		Hash:      hashCode(salt, code),
+		Salt:      salt,
+		SentAt:    now,
+		ExpiresAt: now.Add(m.cfg.TTL),
+	}
+	if err := m.store.Put(ctx, key, record); err != nil {
+		m.mu.Unlock()
+		return fmt.Errorf("failed to store otp record: %w", err)
+	}
+	m.mu.Unlock()
+
+	switch channel {
+	case ChannelSMS:
+		err = m.sms.SendCodeWithTTL(ctx, to, code, m.cfg.TTL)
+	case ChannelEmail:
+		err = m.email.SendVerificationCodeWithTTL(ctx, to, code, m.cfg.TTL)
+	}
+	if err != nil {
+		// An undelivered code must not hold the recipient in the cooldown.
+		m.mu.Lock()
+		if current, ok, getErr := m.store.Get(ctx, key); getErr == nil && ok && hmac.Equal(current.Hash, record.Hash) {
+			_ = m.store.Delete(ctx, key)
+		}
+		m.mu.Unlock()
+		return fmt.Errorf("failed to deliver otp: %w", err)
+	}
+
+	m.logger.Info(ctx, "OTP sent", map[string]any{"channel": channel, "to": to})
+	return nil
+}
+
+// Verify checks code against the pending code for the recipient. A correct
+// code is consumed. Each wrong guess counts towards MaxAttempts, after which
+// the code is locked until a new one is sent.
+func (m *Manager) Verify(ctx context.Context, channel Channel, to, code string) error {
+	key := recordKey(channel, to)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, found, err := m.store.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to load otp record: %w", err)
+	}
+	if !found {
+		return ErrNoCode
+	}
+	if !time.Now().Before(record.ExpiresAt) {
+		if err := m.store.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete otp record: %w", err)
+		}
+		return ErrExpired
+	}
+	if record.Attempts >= m.cfg.MaxAttempts {
+		return ErrTooManyAttempts
+	}
+
+	if hmac.Equal(hashCode(record.Salt, m.normalize(code)), record.Hash) {
+		if err := m.store.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete otp record: %w", err)
+		}
+		return nil
+	}
+
+	// Keep the locked record rather than deleting it, so the resend cooldown still applies.
+	record.Attempts++
+	if err := m.store.Put(ctx, key, record); err != nil {
+		return fmt.Errorf("failed to store otp record: %w", err)
+	}
+	m.logger.Warn(ctx, "OTP verification failed", map[string]any{
+		"channel":  channel,
+		"to":       to,
+		"attempts": record.Attempts,
+	})
+	if record.Attempts >= m.cfg.MaxAttempts {
+		return ErrTooManyAttempts
+	}
+	return ErrInvalidCode
+}
+
+func (m *Manager) checkChannel(channel Channel) error {
+	switch {
+	case channel == ChannelSMS && m.sms != nil, channel == ChannelEmail && m.email != nil:
+		return nil
+	default:
+		return fmt.Errorf("otp channel %q is not configured", channel)
+	}
+}
+
+// generate returns a uniformly random code from the configured alphabet.
+func (m *Manager) generate() (string, error) {
+	max := big.NewInt(int64(len(m.alphabet)))
+	code := make([]byte, m.cfg.Length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate otp code: %w", err)
+		}
+		code[i] = m.alphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// normalize forgives surrounding spaces and, for alphanumeric codes, case.
+func (m *Manager) normalize(code string) string {
+	code = strings.TrimSpace(code)
+	if m.alphabet == alphanumericAlphabet {
+		code = strings.ToUpper(code)
+	}
+	return code
+}
+
+func recordKey(channel Channel, to string) string {
+	return string(channel) + ":" + to
+}
+
+func hashCode(salt []byte, code string) []byte {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(code))
+	return mac.Sum(nil)
+}
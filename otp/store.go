@@ -0,0 +1,70 @@
+package otp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Record is the stored state of an issued code. Only a salted hash of the code is kept.
+type Record struct {
+	Hash      []byte
+	Salt      []byte
+	SentAt    time.Time
+	ExpiresAt time.Time
+	Attempts  int // Failed verifications so far
+}
+
+// Store persists issued codes keyed by channel and recipient. Implementations
+// must be safe for concurrent use.
+type Store interface {
+	// Get returns the record for key, if any.
+	Get(ctx context.Context, key string) (Record, bool, error)
+	// Put inserts or replaces the record for key.
+	Put(ctx context.Context, key string, record Record) error
+	// Delete removes the record for key.
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryStore keeps records in memory. Issued codes are lost on restart, and
+// expired codes are purged as new ones are stored.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]Record)}
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(_ context.Context, key string) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	return record, ok, nil
+}
+
+// Put implements Store.
+func (s *MemoryStore) Put(_ context.Context, key string, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Codes that are never verified would otherwise stay in memory for good.
+	now := time.Now()
+	for k, r := range s.records {
+		if !now.Before(r.ExpiresAt) {
+			delete(s.records, k)
+		}
+	}
+	s.records[key] = record
+	return nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
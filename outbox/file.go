@@ -0,0 +1,277 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// failedDir is the subdirectory holding failed messages, so polls only scan
+// and decode the pending ones.
+const failedDir = "failed"
+
+// corruptSuffix is appended to message files that cannot be decoded. They are
+// set aside for inspection instead of blocking every claim.
+const corruptSuffix = ".corrupt"
+
+// FileStore keeps each message as a JSON file in a directory, so queued
+// messages survive process restarts. Writes go to a temporary file that is
+// synced and renamed into place, so a crash never leaves a half-written
+// message. Failed messages are kept in the failed subdirectory, and files that
+// cannot be decoded are renamed to *.json.corrupt.
+//
+// The scheduling fields of pending messages are indexed in memory when the
+// store is opened, so claims only read the messages they lease. The directory
+// must be used by a single process at a time.
+type FileStore struct {
+	mu  sync.Mutex
+	dir string
+	// pending indexes the pending messages by ID, without their payload.
+	pending map[string]Message
+}
+
+// NewFileStore creates a store in dir, creating the directory if needed, and
+// indexes the pending messages left by a previous run.
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("outbox directory is required")
+	}
+	if err := os.MkdirAll(filepath.Join(dir, failedDir), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+	s := &FileStore{dir: dir, pending: make(map[string]Message)}
+	if err := s.index(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Put implements Store.
+func (s *FileStore) Put(_ context.Context, msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(msg)
+}
+
+// Claim implements Store.
+func (s *FileStore) Claim(_ context.Context, now time.Time, limit int, lease time.Duration) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []Message
+	for _, msg := range s.pending {
+		if msg.due(now) {
+			due = append(due, msg)
+		}
+	}
+	var claimed []Message
+	for _, header := range oldestFirst(due, 0) {
+		if limit > 0 && len(claimed) == limit {
+			break
+		}
+		msg, err := s.read(s.path(header.ID, StatusPending))
+		if errors.Is(err, ErrNotFound) {
+			delete(s.pending, header.ID) // Removed behind the store's back
+			continue
+		}
+		var corrupt *corruptError
+		if errors.As(err, &corrupt) {
+			if err := s.setAside(corrupt.path); err != nil {
+				return nil, err
+			}
+			delete(s.pending, header.ID)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		msg.LeaseUntil = now.Add(lease)
+		if err := s.write(msg); err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, msg)
+	}
+	return claimed, nil
+}
+
+// Get implements Store.
+func (s *FileStore) Get(_ context.Context, id string) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg, err := s.read(s.path(id, StatusPending))
+	if errors.Is(err, ErrNotFound) {
+		return s.read(s.path(id, StatusFailed))
+	}
+	return msg, err
+}
+
+// Delete implements Store.
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.remove(id, StatusPending, StatusFailed); err != nil {
+		return err
+	}
+	delete(s.pending, id)
+	return nil
+}
+
+// path returns the file of the message with the given ID and status.
+func (s *FileStore) path(id string, status Status) string {
+	name := filepath.Base(id) + ".json"
+	if status == StatusFailed {
+		return filepath.Join(s.dir, failedDir, name)
+	}
+	return filepath.Join(s.dir, name)
+}
+
+func (s *FileStore) remove(id string, statuses ...Status) error {
+	for _, status := range statuses {
+		if err := os.Remove(s.path(id, status)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to delete outbox message %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func (s *FileStore) write(msg Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox message %s: %w", msg.ID, err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write outbox message %s: %w", msg.ID, err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write outbox message %s: %w", msg.ID, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync outbox message %s: %w", msg.ID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write outbox message %s: %w", msg.ID, err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(msg.ID, msg.Status)); err != nil {
+		return fmt.Errorf("failed to write outbox message %s: %w", msg.ID, err)
+	}
+	// A message moves between the directories when it fails, or is put back as pending.
+	other := StatusFailed
+	if msg.Status == StatusFailed {
+		other = StatusPending
+		delete(s.pending, msg.ID)
+	} else {
+		s.pending[msg.ID] = msg.header()
+	}
+	return s.remove(msg.ID, other)
+}
+
+func (s *FileStore) read(path string) (Message, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Message{}, ErrNotFound
+	}
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to read outbox message: %w", err)
+	}
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return Message{}, &corruptError{path: path, err: err}
+	}
+	return msg, nil
+}
+
+// corruptError reports a message file that cannot be decoded.
+type corruptError struct {
+	path string
+	err  error
+}
+
+func (e *corruptError) Error() string {
+	return fmt.Sprintf("failed to decode outbox message %s: %v", filepath.Base(e.path), e.err)
+}
+
+func (e *corruptError) Unwrap() error {
+	return e.err
+}
+
+// index loads the scheduling fields of the messages in the top-level
+// directory. Files that cannot be decoded, e.g. truncated by a full disk, are
+// renamed to *.json.corrupt and skipped, so one bad file does not stop delivery.
+func (s *FileStore) index() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to list outbox directory: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		path := filepath.Join(s.dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read outbox message: %w", err)
+		}
+		// Decoding into the header skips the payload, e.g. attachment contents.
+		var header messageHeader
+		if err := json.Unmarshal(data, &header); err != nil || header.ID == "" {
+			if err := s.setAside(path); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg := header.message(); msg.Status == StatusPending {
+			s.pending[msg.ID] = msg
+		}
+	}
+	return nil
+}
+
+// setAside renames a message file that cannot be decoded to *.json.corrupt.
+func (s *FileStore) setAside(path string) error {
+	if err := os.Rename(path, path+corruptSuffix); err != nil {
+		return fmt.Errorf("failed to set aside corrupt outbox message %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// messageHeader is the part of a message file needed to schedule it.
+type messageHeader struct {
+	ID          string    `json:"id"`
+	Kind        Kind      `json:"kind"`
+	Status      Status    `json:"status"`
+	Attempts    int       `json:"attempts"`
+	CreatedAt   time.Time `json:"createdAt"`
+	NextAttempt time.Time `json:"nextAttempt"`
+	LeaseUntil  time.Time `json:"leaseUntil,omitempty"`
+}
+
+func (h messageHeader) message() Message {
+	return Message{
+		ID:          h.ID,
+		Kind:        h.Kind,
+		Status:      h.Status,
+		Attempts:    h.Attempts,
+		CreatedAt:   h.CreatedAt,
+		NextAttempt: h.NextAttempt,
+		LeaseUntil:  h.LeaseUntil,
+	}
+}
+
+// header returns the message without its payload, for the index.
+func (m Message) header() Message {
+	m.Email, m.SMS, m.Content, m.LastError = nil, nil, nil, ""
+	return m
+}
@@ -0,0 +1,79 @@
+package outbox
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps messages in memory. Queued messages are lost on restart.
+type MemoryStore struct {
+	mu       sync.Mutex
+	messages map[string]Message
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{messages: make(map[string]Message)}
+}
+
+// Put implements Store.
+func (s *MemoryStore) Put(_ context.Context, msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages[msg.ID] = msg
+	return nil
+}
+
+// Claim implements Store.
+func (s *MemoryStore) Claim(_ context.Context, now time.Time, limit int, lease time.Duration) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []Message
+	for _, msg := range s.messages {
+		if msg.due(now) {
+			due = append(due, msg)
+		}
+	}
+	claimed := oldestFirst(due, limit)
+	for i := range claimed {
+		claimed[i].LeaseUntil = now.Add(lease)
+		s.messages[claimed[i].ID] = claimed[i]
+	}
+	return claimed, nil
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(_ context.Context, id string) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	msg, ok := s.messages[id]
+	if !ok {
+		return Message{}, ErrNotFound
+	}
+	return msg, nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.messages, id)
+	return nil
+}
+
+// oldestFirst sorts messages by due time, then creation time, and keeps at most limit of them.
+func oldestFirst(messages []Message, limit int) []Message {
+	sort.Slice(messages, func(i, j int) bool {
+		if !messages[i].NextAttempt.Equal(messages[j].NextAttempt) {
+			return messages[i].NextAttempt.Before(messages[j].NextAttempt)
+		}
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+	if limit > 0 && len(messages) > limit {
+		messages = messages[:limit]
+	}
+	return messages
+}
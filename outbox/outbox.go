@@ -0,0 +1,349 @@
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+)
+
+// Default outbox settings, used for zero fields of config.OutboxConfig.
+const (
+	defaultWorkers        = 4
+	defaultPollInterval   = time.Second
+	defaultMaxAttempts    = 5
+	defaultInitialBackoff = 5 * time.Second
+	defaultMaxBackoff     = 10 * time.Minute
+	defaultLease          = time.Minute
+)
+
+// ErrClosed is returned by the Enqueue methods once Shutdown has been called.
+var ErrClosed = errors.New("outbox: closed")
+
+// Senders are the channels the outbox delivers through. The email, SMS and
+// notify services satisfy these interfaces. A nil sender rejects enqueues of
+// its kind.
+type Senders struct {
+	Email  sen.EmailResultAdapter
+	SMS    sen.SMSResultAdapter
+	Notify sen.NotifyResultAdapter
+}
+
+// Outbox persists messages and delivers them in the background.
+type Outbox struct {
+	store   Store
+	senders Senders
+	cfg     config.OutboxConfig
+	logger  logger.Logger
+
+	mu      sync.Mutex
+	started bool
+	wake    chan struct{} // Signals idle workers that a message was enqueued
+	stop    chan struct{} // Closed by Shutdown; workers exit once nothing is due
+	ctx     context.Context
+	cancel  context.CancelFunc // Aborts in-flight sends when Shutdown times out
+	wg      sync.WaitGroup
+}
+
+// New creates an outbox that delivers messages from store through senders.
+// Call Start to begin delivery.
+func New(cfg config.OutboxConfig, store Store, senders Senders, logger logger.Logger) (*Outbox, error) {
+	if store == nil {
+		return nil, fmt.Errorf("outbox store is required")
+	}
+	if senders.Email == nil && senders.SMS == nil && senders.Notify == nil {
+		return nil, fmt.Errorf("at least one outbox sender is required")
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultWorkers
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaultInitialBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = defaultLease
+	}
+
+	return &Outbox{
+		store:   store,
+		senders: senders,
+		cfg:     cfg,
+		logger: logger.WithFields(map[string]any{
+			"service": "outbox",
+		}),
+		wake: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+	}, nil
+}
+
+// EnqueueEmail stores an email for asynchronous delivery and returns its outbox ID.
+// Attachments are checked against the limits of the email sender (or the default
+// limits when it does not expose them) and their readers are read before
+// EnqueueEmail returns, so oversized emails are refused instead of failing later.
+func (o *Outbox) EnqueueEmail(ctx context.Context, email dto.Email) (string, error) {
+	if o.senders.Email == nil {
+		return "", fmt.Errorf("outbox has no email sender")
+	}
+	attachments, err := o.prepareAttachments(email.Attachments)
+	if err != nil {
+		return "", err
+	}
+	email.Attachments = attachments
+	return o.enqueue(ctx, Message{Kind: KindEmail, Email: &email})
+}
+
+// EnqueueSMS stores an SMS for asynchronous delivery and returns its outbox ID.
+func (o *Outbox) EnqueueSMS(ctx context.Context, sms dto.SMS) (string, error) {
+	if o.senders.SMS == nil {
+		return "", fmt.Errorf("outbox has no SMS sender")
+	}
+	return o.enqueue(ctx, Message{Kind: KindSMS, SMS: &sms})
+}
+
+// EnqueueNotify stores a notification for asynchronous delivery and returns its outbox ID.
+func (o *Outbox) EnqueueNotify(ctx context.Context, content dto.Content) (string, error) {
+	if o.senders.Notify == nil {
+		return "", fmt.Errorf("outbox has no notify sender")
+	}
+	return o.enqueue(ctx, Message{Kind: KindNotify, Content: &content})
+}
+
+// Get returns a queued or failed message. Delivered messages are removed from
+// the store, so Get returns ErrNotFound for them.
+func (o *Outbox) Get(ctx context.Context, id string) (Message, error) {
+	return o.store.Get(ctx, id)
+}
+
+func (o *Outbox) enqueue(ctx context.Context, msg Message) (string, error) {
+	select {
+	case <-o.stop:
+		return "", ErrClosed
+	default:
+	}
+	id, err := newID()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	msg.ID = id
+	msg.Status = StatusPending
+	msg.CreatedAt = now
+	msg.NextAttempt = now
+	if err := o.store.Put(ctx, msg); err != nil {
+		return "", fmt.Errorf("failed to enqueue %s message: %w", msg.Kind, err)
+	}
+
+	o.logger.Debug(ctx, "Message enqueued", map[string]any{"id": id, "kind": msg.Kind})
+	select {
+	case o.wake <- struct{}{}:
+	default: // A wake-up is already pending
+	}
+	return id, nil
+}
+
+// Start launches the worker pool. Messages left in the store by a previous
+// run are delivered as well.
+func (o *Outbox) Start() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.started {
+		return fmt.Errorf("outbox already started")
+	}
+	o.started = true
+	o.ctx, o.cancel = context.WithCancel(context.Background())
+
+	for range o.cfg.Workers {
+		o.wg.Add(1)
+		go o.worker()
+	}
+	o.logger.Info(o.ctx, "Outbox started", map[string]any{"workers": o.cfg.Workers})
+	return nil
+}
+
+// Shutdown stops accepting work, failing later enqueues with ErrClosed, and waits for the workers to deliver every
+// message that is currently due. Messages waiting for a retry stay in the store
+// for the next run. If ctx ends first, in-flight sends are cancelled and their
+// messages are redelivered after a restart.
+func (o *Outbox) Shutdown(ctx context.Context) error {
+	o.mu.Lock()
+	if !o.started {
+		o.mu.Unlock()
+		return nil
+	}
+	select {
+	case <-o.stop:
+	default:
+		close(o.stop)
+	}
+	o.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		o.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		o.cancel()
+		o.logger.Info(ctx, "Outbox drained and stopped")
+		return nil
+	case <-ctx.Done():
+		o.cancel()
+		<-done
+		return fmt.Errorf("outbox shutdown interrupted before draining: %w", ctx.Err())
+	}
+}
+
+// worker delivers due messages until Shutdown is called and nothing is left to deliver.
+func (o *Outbox) worker() {
+	defer o.wg.Done()
+	timer := time.NewTimer(o.cfg.PollInterval)
+	defer timer.Stop()
+
+	for {
+		if o.ctx.Err() != nil {
+			return
+		}
+		claimed, err := o.store.Claim(o.ctx, time.Now(), 1, o.cfg.Lease)
+		if err != nil {
+			o.logger.Error(o.ctx, "Failed to claim outbox messages", map[string]any{"error": err})
+		}
+		if len(claimed) > 0 {
+			o.deliver(claimed[0])
+			continue
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(o.cfg.PollInterval)
+		select {
+		case <-o.stop:
+			return
+		case <-o.ctx.Done():
+			return
+		case <-o.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// deliver sends a claimed message, then removes it or schedules the next attempt.
+// The send must end before the lease does, or another worker would claim the
+// message again and the provider would see a duplicate.
+func (o *Outbox) deliver(msg Message) {
+	ctx := o.ctx
+	sendCtx, cancel := context.WithTimeout(ctx, o.sendTimeout())
+	result, err := o.send(sendCtx, msg)
+	leaseExpired := sendCtx.Err() != nil && ctx.Err() == nil
+	cancel()
+	if err == nil {
+		if err := o.store.Delete(ctx, msg.ID); err != nil {
+			// The lease expires and the message is sent again; providers may see a duplicate.
+			o.logger.Error(ctx, "Failed to remove delivered outbox message", map[string]any{"id": msg.ID, "error": err})
+			return
+		}
+		o.logger.Info(ctx, "Outbox message delivered", map[string]any{
+			"id":         msg.ID,
+			"kind":       msg.Kind,
+			"provider":   result.Provider,
+			"message_id": result.MessageID,
+		})
+		return
+	}
+	if ctx.Err() != nil {
+		// Shutdown timed out mid-send; keep the lease so the message is retried after a restart.
+		return
+	}
+
+	msg.Attempts++
+	msg.LastError = err.Error()
+	msg.LeaseUntil = time.Time{}
+	fields := map[string]any{"id": msg.ID, "kind": msg.Kind, "attempt": msg.Attempts, "error": err}
+	if leaseExpired {
+		err = fmt.Errorf("send did not finish within the %s lease: %w", o.cfg.Lease, err)
+		msg.LastError = err.Error()
+		fields["error"] = err
+	}
+	if !(sen.IsTransient(err) || leaseExpired) || msg.Attempts >= o.cfg.MaxAttempts {
+		msg.Status = StatusFailed
+		o.logger.Error(ctx, "Outbox message failed permanently", fields)
+	} else {
+		wait := o.backoff(msg.Attempts)
+		if retryAfter, ok := sen.RetryAfter(err); ok && retryAfter > wait {
+			wait = retryAfter
+		}
+		msg.NextAttempt = time.Now().Add(wait)
+		fields["wait"] = wait.String()
+		o.logger.Warn(ctx, "Outbox message delivery failed, will retry", fields)
+	}
+	if err := o.store.Put(ctx, msg); err != nil {
+		o.logger.Error(ctx, "Failed to update outbox message", map[string]any{"id": msg.ID, "error": err})
+	}
+}
+
+func (o *Outbox) send(ctx context.Context, msg Message) (dto.SendResult, error) {
+	switch {
+	case msg.Kind == KindEmail && msg.Email != nil && o.senders.Email != nil:
+		return o.senders.Email.SendEmailWithResult(ctx, *msg.Email)
+	case msg.Kind == KindSMS && msg.SMS != nil && o.senders.SMS != nil:
+		return o.senders.SMS.SendWithResult(ctx, *msg.SMS)
+	case msg.Kind == KindNotify && msg.Content != nil && o.senders.Notify != nil:
+		return o.senders.Notify.SendWithResult(ctx, *msg.Content)
+	default:
+		return dto.SendResult{}, errors.New("no sender for " + string(msg.Kind) + " message")
+	}
+}
+
+// sendTimeout bounds a send to the lease, minus a margin for updating the
+// store before another worker may claim the message.
+func (o *Outbox) sendTimeout() time.Duration {
+	return o.cfg.Lease - o.cfg.Lease/10
+}
+
+// backoff returns the exponential delay before the retry following the given attempt.
+func (o *Outbox) backoff(attempt int) time.Duration {
+	wait := o.cfg.InitialBackoff
+	for i := 1; i < attempt && wait < o.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, o.cfg.MaxBackoff)
+}
+
+// prepareAttachments runs the email service's attachment checks, and reads
+// every Reader into Content since readers cannot be stored.
+func (o *Outbox) prepareAttachments(attachments []dto.Attachment) ([]dto.Attachment, error) {
+	if preparer, ok := o.senders.Email.(sen.AttachmentPreparer); ok {
+		return preparer.PrepareAttachments(attachments)
+	}
+	return sen.PrepareAttachments(attachments, config.AttachmentConfig{})
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate outbox message ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,80 @@
+// Package outbox queues messages for asynchronous delivery. Enqueued messages
+// are persisted in a Store and delivered by a worker pool through the
+// configured email, SMS and notify services, with retries.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+)
+
+// ErrNotFound is returned by Store.Get for an unknown message ID.
+var ErrNotFound = errors.New("outbox: message not found")
+
+// Kind is the channel a message is delivered through.
+type Kind string
+
+const (
+	KindEmail  Kind = "email"
+	KindSMS    Kind = "sms"
+	KindNotify Kind = "notify"
+)
+
+// Status is the delivery state of a stored message.
+type Status string
+
+const (
+	StatusPending Status = "pending" // Waiting for (re)delivery
+	StatusFailed  Status = "failed"  // Permanently failed or out of attempts; kept for inspection
+)
+
+// Message is a queued send. Exactly one of Email, SMS or Content is set, matching Kind.
+type Message struct {
+	ID          string       `json:"id"`
+	Kind        Kind         `json:"kind"`
+	Email       *dto.Email   `json:"email,omitempty"`
+	SMS         *dto.SMS     `json:"sms,omitempty"`
+	Content     *dto.Content `json:"content,omitempty"`
+	Status      Status       `json:"status"`
+	Attempts    int          `json:"attempts"`
+	LastError   string       `json:"lastError,omitempty"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	NextAttempt time.Time    `json:"nextAttempt"`
+	LeaseUntil  time.Time    `json:"leaseUntil,omitempty"`
+}
+
+// due reports whether a worker may claim the message at now.
+func (m Message) due(now time.Time) bool {
+	return m.Status == StatusPending && !m.NextAttempt.After(now) && !m.LeaseUntil.After(now)
+}
+
+// Store persists queued messages. Implementations must be safe for concurrent use.
+type Store interface {
+	// Put inserts or replaces a message.
+	Put(ctx context.Context, msg Message) error
+	// Claim leases up to limit pending messages due at now, oldest first. Claimed
+	// messages are hidden from other workers until the lease expires, so a message
+	// held by a crashed worker is delivered again.
+	Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]Message, error)
+	// Get returns the message with the given ID, or ErrNotFound.
+	Get(ctx context.Context, id string) (Message, error)
+	// Delete removes a delivered message.
+	Delete(ctx context.Context, id string) error
+}
+
+// NewStore creates the store selected by cfg.Store.
+func NewStore(cfg config.OutboxConfig) (Store, error) {
+	switch cfg.Store {
+	case "", config.OutboxStoreMemory:
+		return NewMemoryStore(), nil
+	case config.OutboxStoreFile:
+		return NewFileStore(cfg.Dir)
+	default:
+		return nil, fmt.Errorf("unsupported outbox store: %s", cfg.Store)
+	}
+}
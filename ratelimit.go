@@ -0,0 +1,136 @@
+package sen
+
+import (
+	"context"
+
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/ratelimit"
+)
+
+// rateLimits applies the global and per-recipient limits of a service.
+type rateLimits struct {
+	limiter *ratelimit.Limiter
+	cfg     config.RateLimitConfig
+	kind    string
+}
+
+func newRateLimits(kind string, cfg config.RateLimitConfig, limiter *ratelimit.Limiter) rateLimits {
+	return rateLimits{limiter: limiter, cfg: cfg, kind: kind}
+}
+
+// allow takes a token from each recipient's bucket, then from the service's
+// global bucket. Recipients are checked first so a limited recipient cannot
+// drain the global bucket shared with everyone else; when a check fails, the
+// tokens already taken are refunded if the store supports it.
+func (l rateLimits) allow(ctx context.Context, recipients ...string) error {
+	for i, recipient := range recipients {
+		if err := l.limiter.Allow(ctx, "recipient", recipient, l.cfg.Recipient); err != nil {
+			l.refund(ctx, recipients[:i])
+			return err
+		}
+	}
+	if err := l.limiter.Allow(ctx, "global", l.kind, l.cfg.Global); err != nil {
+		l.refund(ctx, recipients)
+		return err
+	}
+	return nil
+}
+
+// refund gives back the recipient tokens taken by a send that was not allowed.
+func (l rateLimits) refund(ctx context.Context, recipients []string) {
+	for _, recipient := range recipients {
+		// A failed refund only makes the limit stricter, so it is not reported.
+		_ = l.limiter.Refund(ctx, "recipient", recipient, l.cfg.Recipient)
+	}
+}
+
+// providerLimit returns the limit configured for provider and whether one is enabled.
+func (l rateLimits) providerLimit(provider string) (config.RateLimit, bool) {
+	limit, ok := l.cfg.Providers[provider]
+	return limit, ok && limit.Limit > 0
+}
+
+// EmailRateLimit is an EmailAdapter that enforces a provider's send quota,
+// failing with a *RateLimitError instead of calling the provider once it is spent.
+type EmailRateLimit struct {
+	adapter  EmailAdapter
+	limiter  *ratelimit.Limiter
+	provider string
+	limit    config.RateLimit
+}
+
+// NewEmailRateLimit wraps adapter with the provider's limit.
+func NewEmailRateLimit(adapter EmailAdapter, limiter *ratelimit.Limiter, provider string, limit config.RateLimit) *EmailRateLimit {
+	return &EmailRateLimit{adapter: adapter, limiter: limiter, provider: provider, limit: limit}
+}
+
+// SendEmail sends the email if the provider's quota allows it.
+func (r *EmailRateLimit) SendEmail(ctx context.Context, email dto.Email) error {
+	_, err := r.SendEmailWithResult(ctx, email)
+	return err
+}
+
+// SendEmailWithResult sends the email if the provider's quota allows it and returns the provider's result.
+func (r *EmailRateLimit) SendEmailWithResult(ctx context.Context, email dto.Email) (dto.SendResult, error) {
+	if err := r.limiter.Allow(ctx, "provider", r.provider, r.limit); err != nil {
+		return dto.SendResult{}, err
+	}
+	return sendEmail(ctx, r.adapter, email)
+}
+
+// SMSRateLimit is an SMSAdapter that enforces a provider's send quota,
+// failing with a *RateLimitError instead of calling the provider once it is spent.
+type SMSRateLimit struct {
+	adapter  SMSAdapter
+	limiter  *ratelimit.Limiter
+	provider string
+	limit    config.RateLimit
+}
+
+// NewSMSRateLimit wraps adapter with the provider's limit.
+func NewSMSRateLimit(adapter SMSAdapter, limiter *ratelimit.Limiter, provider string, limit config.RateLimit) *SMSRateLimit {
+	return &SMSRateLimit{adapter: adapter, limiter: limiter, provider: provider, limit: limit}
+}
+
+// Send sends the SMS if the provider's quota allows it.
+func (r *SMSRateLimit) Send(ctx context.Context, sms dto.SMS) error {
+	_, err := r.SendWithResult(ctx, sms)
+	return err
+}
+
+// SendWithResult sends the SMS if the provider's quota allows it and returns the provider's result.
+func (r *SMSRateLimit) SendWithResult(ctx context.Context, sms dto.SMS) (dto.SendResult, error) {
+	if err := r.limiter.Allow(ctx, "provider", r.provider, r.limit); err != nil {
+		return dto.SendResult{}, err
+	}
+	return sendSMS(ctx, r.adapter, sms)
+}
+
+// NotifyRateLimit is a NotifyAdapter that enforces a channel's send quota,
+// failing with a *RateLimitError instead of calling the channel once it is spent.
+type NotifyRateLimit struct {
+	adapter  NotifyAdapter
+	limiter  *ratelimit.Limiter
+	provider string
+	limit    config.RateLimit
+}
+
+// NewNotifyRateLimit wraps adapter with the channel's limit.
+func NewNotifyRateLimit(adapter NotifyAdapter, limiter *ratelimit.Limiter, provider string, limit config.RateLimit) *NotifyRateLimit {
+	return &NotifyRateLimit{adapter: adapter, limiter: limiter, provider: provider, limit: limit}
+}
+
+// Send sends the notification if the channel's quota allows it.
+func (r *NotifyRateLimit) Send(ctx context.Context, content dto.Content) error {
+	_, err := r.SendWithResult(ctx, content)
+	return err
+}
+
+// SendWithResult sends the notification if the channel's quota allows it and returns the channel's result.
+func (r *NotifyRateLimit) SendWithResult(ctx context.Context, content dto.Content) (dto.SendResult, error) {
+	if err := r.limiter.Allow(ctx, "provider", r.provider, r.limit); err != nil {
+		return dto.SendResult{}, err
+	}
+	return sendNotify(ctx, r.adapter, content)
+}
@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/lugondev/send-sen/config"
+)
+
+// sweepInterval bounds how often refilled buckets are purged.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps token buckets in memory, per process.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   config.RateLimit
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+// Take implements Store.
+func (s *MemoryStore) Take(_ context.Context, key string, limit config.RateLimit) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	capacity := float64(limit.Limit)
+	rate := capacity / float64(limit.Per) // Tokens per nanosecond
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+	b.tokens = min(capacity, b.tokens+rate*float64(now.Sub(b.updated)))
+	b.updated = now
+
+	if b.tokens < 1 {
+		return false, time.Duration(math.Ceil((1 - b.tokens) / rate)), nil
+	}
+	b.tokens--
+	return true, 0, nil
+}
+
+// Refund implements Refunder.
+func (s *MemoryStore) Refund(_ context.Context, key string, limit config.RateLimit) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b, ok := s.buckets[key]; ok {
+		b.tokens = min(float64(limit.Limit), b.tokens+1)
+	}
+	return nil
+}
+
+// sweep drops buckets that have refilled completely, since they are
+// indistinguishable from new ones.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) >= b.limit.Per {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,108 @@
+// Package ratelimit throttles sends with token buckets kept in a pluggable Store.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/errs"
+)
+
+// defaultPer is the refill interval when config.RateLimit.Per is zero.
+const defaultPer = time.Minute
+
+// Store keeps token buckets. Implementations must be safe for concurrent use.
+type Store interface {
+	// Take removes one token from the bucket for key, refilling it at
+	// limit.Limit tokens per limit.Per. If the bucket is empty it returns
+	// false and the wait until a token is available.
+	Take(ctx context.Context, key string, limit config.RateLimit) (bool, time.Duration, error)
+}
+
+// Refunder is implemented by stores that can give back a token taken by Take,
+// so a send rejected by a later limit does not use up the earlier ones.
+type Refunder interface {
+	// Refund returns one token to the bucket for key, up to limit.Limit.
+	Refund(ctx context.Context, key string, limit config.RateLimit) error
+}
+
+// Error is returned when a send exceeds a limit. It matches errs.ErrRateLimited
+// with errors.Is and reports the wait through RetryAfter.
+type Error struct {
+	// Scope is the limit that was hit: "recipient", "global" or "provider".
+	Scope string
+	// Key identifies the bucket, e.g. the recipient or provider name.
+	Key string
+	// RetryDelay is the wait until the next send is allowed.
+	RetryDelay time.Duration
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %s %s, retry in %s", e.Scope, e.Key, e.RetryDelay.Round(time.Millisecond))
+}
+
+// Unwrap makes the error match errs.ErrRateLimited.
+func (e *Error) Unwrap() error {
+	return errs.ErrRateLimited
+}
+
+// Temporary reports that the send may succeed later.
+func (e *Error) Temporary() bool {
+	return true
+}
+
+// RetryAfter returns the wait until the next send is allowed.
+func (e *Error) RetryAfter() time.Duration {
+	return e.RetryDelay
+}
+
+// Limiter checks sends against token buckets in a Store.
+type Limiter struct {
+	store Store
+}
+
+// New creates a Limiter backed by store, or by a MemoryStore if store is nil.
+func New(store Store) *Limiter {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Limiter{store: store}
+}
+
+// Allow takes a token from the scope/key bucket. It returns an *Error if the
+// bucket is empty. Disabled limits (Limit 0) always allow.
+func (l *Limiter) Allow(ctx context.Context, scope, key string, limit config.RateLimit) error {
+	if limit.Limit <= 0 {
+		return nil
+	}
+	if limit.Per <= 0 {
+		limit.Per = defaultPer
+	}
+	ok, wait, err := l.store.Take(ctx, scope+":"+key, limit)
+	if err != nil {
+		return fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	if !ok {
+		return &Error{Scope: scope, Key: key, RetryDelay: wait}
+	}
+	return nil
+}
+
+// Refund gives back a token taken by Allow from the scope/key bucket. It does
+// nothing for disabled limits or stores that do not implement Refunder.
+func (l *Limiter) Refund(ctx context.Context, scope, key string, limit config.RateLimit) error {
+	refunder, ok := l.store.(Refunder)
+	if !ok || limit.Limit <= 0 {
+		return nil
+	}
+	if limit.Per <= 0 {
+		limit.Per = defaultPer
+	}
+	if err := refunder.Refund(ctx, scope+":"+key, limit); err != nil {
+		return fmt.Errorf("failed to refund rate limit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package sen
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/lugondev/send-sen/dto"
+)
+
+// sendEmail sends the email through adapter and returns the provider's result.
+// Adapters that do not implement EmailResultAdapter report every recipient as accepted.
+func sendEmail(ctx context.Context, adapter EmailAdapter, email dto.Email) (dto.SendResult, error) {
+	if resultAdapter, ok := adapter.(EmailResultAdapter); ok {
+		return resultAdapter.SendEmailWithResult(ctx, email)
+	}
+	if err := adapter.SendEmail(ctx, email); err != nil {
+		return dto.SendResult{}, err
+	}
+	return dto.SendResult{
+		Accepted:  slices.Concat(email.To, email.Cc, email.Bcc),
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// sendSMS sends the SMS through adapter and returns the provider's result.
+// Adapters that do not implement SMSResultAdapter report the recipient as accepted.
+func sendSMS(ctx context.Context, adapter SMSAdapter, sms dto.SMS) (dto.SendResult, error) {
+	if resultAdapter, ok := adapter.(SMSResultAdapter); ok {
+		return resultAdapter.SendWithResult(ctx, sms)
+	}
+	if err := adapter.Send(ctx, sms); err != nil {
+		return dto.SendResult{}, err
+	}
+	return dto.SendResult{
+		Accepted:  []string{sms.To},
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// sendNotify sends the notification through adapter and returns the provider's result.
+func sendNotify(ctx context.Context, adapter NotifyAdapter, content dto.Content) (dto.SendResult, error) {
+	if resultAdapter, ok := adapter.(NotifyResultAdapter); ok {
+		return resultAdapter.SendWithResult(ctx, content)
+	}
+	if err := adapter.Send(ctx, content); err != nil {
+		return dto.SendResult{}, err
+	}
+	return dto.SendResult{Timestamp: time.Now()}, nil
+}
@@ -0,0 +1,233 @@
+package sen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+)
+
+// Default retry policy values, used for zero fields of config.RetryConfig.
+const (
+	defaultRetryInitialBackoff = 200 * time.Millisecond
+	defaultRetryMaxBackoff     = 5 * time.Second
+	defaultRetryMultiplier     = 2.0
+	defaultRetryJitter         = 0.2
+)
+
+// IsTransient reports whether err is a temporary failure worth retrying:
+// network errors and timeouts, and errors that declare themselves temporary
+// (Temporary() bool) or carry a retry delay (RetryAfter() time.Duration).
+// Context cancellation is never transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) && temporary.Temporary() {
+		return true
+	}
+	if _, ok := RetryAfter(err); ok {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// RetryAfter returns the delay requested by the provider (e.g. from a Retry-After
+// header) when err carries one.
+func RetryAfter(err error) (time.Duration, bool) {
+	var retryAfter interface{ RetryAfter() time.Duration }
+	if errors.As(err, &retryAfter) && retryAfter.RetryAfter() > 0 {
+		return retryAfter.RetryAfter(), true
+	}
+	return 0, false
+}
+
+// retrier runs a send function under a retry policy.
+type retrier struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	multiplier     float64
+	jitter         float64
+	logger         logger.Logger
+}
+
+func newRetrier(kind string, cfg config.RetryConfig, logger logger.Logger) retrier {
+	r := retrier{
+		maxAttempts:    cfg.MaxAttempts,
+		initialBackoff: cfg.InitialBackoff,
+		maxBackoff:     cfg.MaxBackoff,
+		multiplier:     cfg.Multiplier,
+		jitter:         cfg.Jitter,
+		logger: logger.WithFields(map[string]any{
+			"service": kind + "_retry",
+		}),
+	}
+	if r.maxAttempts < 1 {
+		r.maxAttempts = 1
+	}
+	if r.initialBackoff <= 0 {
+		r.initialBackoff = defaultRetryInitialBackoff
+	}
+	if r.maxBackoff <= 0 {
+		r.maxBackoff = defaultRetryMaxBackoff
+	}
+	if r.multiplier < 1 {
+		r.multiplier = defaultRetryMultiplier
+	}
+	if r.jitter <= 0 || r.jitter > 1 {
+		r.jitter = defaultRetryJitter
+	}
+	return r
+}
+
+// run calls send until it succeeds, fails with a non-transient error, runs out of
+// attempts, or the next wait would outlive the context.
+func (r retrier) run(ctx context.Context, send func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = send()
+		if err == nil || !IsTransient(err) || ctx.Err() != nil {
+			return err
+		}
+		if attempt >= r.maxAttempts {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		wait := r.backoff(attempt)
+		if retryAfter, ok := RetryAfter(err); ok && retryAfter > wait {
+			wait = retryAfter
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return fmt.Errorf("not retrying, next attempt in %s would exceed the deadline: %w", wait, err)
+		}
+
+		r.logger.Warn(ctx, "Transient send failure, retrying", map[string]any{
+			"attempt": attempt,
+			"wait":    wait.String(),
+			"error":   err,
+		})
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the jittered exponential delay before the given retry.
+func (r retrier) backoff(attempt int) time.Duration {
+	delay := float64(r.initialBackoff) * math.Pow(r.multiplier, float64(attempt-1))
+	delay = math.Min(delay, float64(r.maxBackoff))
+	// Spread retries uniformly within ±jitter of the nominal delay.
+	delay *= 1 + r.jitter*(2*rand.Float64()-1)
+	return time.Duration(delay)
+}
+
+// EmailRetry is an EmailAdapter that retries transient failures of the wrapped adapter.
+type EmailRetry struct {
+	adapter EmailAdapter
+	retrier retrier
+}
+
+// NewEmailRetry wraps adapter with the given retry policy.
+func NewEmailRetry(adapter EmailAdapter, cfg config.RetryConfig, logger logger.Logger) *EmailRetry {
+	return &EmailRetry{adapter: adapter, retrier: newRetrier("email", cfg, logger)}
+}
+
+// SendEmail sends the email, retrying transient failures.
+func (r *EmailRetry) SendEmail(ctx context.Context, email dto.Email) error {
+	_, err := r.SendEmailWithResult(ctx, email)
+	return err
+}
+
+// SendEmailWithResult sends the email, retrying transient failures, and returns the provider's result.
+func (r *EmailRetry) SendEmailWithResult(ctx context.Context, email dto.Email) (dto.SendResult, error) {
+	var result dto.SendResult
+	err := r.retrier.run(ctx, func() error {
+		var err error
+		result, err = sendEmail(ctx, r.adapter, email)
+		return err
+	})
+	return result, err
+}
+
+// SMSRetry is an SMSAdapter that retries transient failures of the wrapped adapter.
+type SMSRetry struct {
+	adapter SMSAdapter
+	retrier retrier
+}
+
+// NewSMSRetry wraps adapter with the given retry policy.
+func NewSMSRetry(adapter SMSAdapter, cfg config.RetryConfig, logger logger.Logger) *SMSRetry {
+	return &SMSRetry{adapter: adapter, retrier: newRetrier("sms", cfg, logger)}
+}
+
+// Send sends the SMS, retrying transient failures.
+func (r *SMSRetry) Send(ctx context.Context, sms dto.SMS) error {
+	_, err := r.SendWithResult(ctx, sms)
+	return err
+}
+
+// SendWithResult sends the SMS, retrying transient failures, and returns the provider's result.
+func (r *SMSRetry) SendWithResult(ctx context.Context, sms dto.SMS) (dto.SendResult, error) {
+	var result dto.SendResult
+	err := r.retrier.run(ctx, func() error {
+		var err error
+		result, err = sendSMS(ctx, r.adapter, sms)
+		return err
+	})
+	return result, err
+}
+
+// NotifyRetry is a NotifyAdapter that retries transient failures of the wrapped adapter.
+type NotifyRetry struct {
+	adapter NotifyAdapter
+	retrier retrier
+}
+
+// NewNotifyRetry wraps adapter with the given retry policy.
+func NewNotifyRetry(adapter NotifyAdapter, cfg config.RetryConfig, logger logger.Logger) *NotifyRetry {
+	return &NotifyRetry{adapter: adapter, retrier: newRetrier("notify", cfg, logger)}
+}
+
+// Send sends the notification, retrying transient failures.
+func (r *NotifyRetry) Send(ctx context.Context, content dto.Content) error {
+	_, err := r.SendWithResult(ctx, content)
+	return err
+}
+
+// SendWithResult sends the notification, retrying transient failures, and returns the provider's result.
+func (r *NotifyRetry) SendWithResult(ctx context.Context, content dto.Content) (dto.SendResult, error) {
+	var result dto.SendResult
+	err := r.retrier.run(ctx, func() error {
+		var err error
+		result, err = sendNotify(ctx, r.adapter, content)
+		return err
+	})
+	return result, err
+}
@@ -2,6 +2,7 @@ package sen
 
 import (
 	"context"
+	"time"
 
 	"github.com/lugondev/send-sen/dto"
 )
@@ -11,9 +12,18 @@ type SMSAdapter interface {
 	Send(ctx context.Context, sms dto.SMS) error
 }
 
+// SMSResultAdapter is implemented by SMS adapters that report the provider's send result.
+type SMSResultAdapter interface {
+	SMSAdapter
+	SendWithResult(ctx context.Context, sms dto.SMS) (dto.SendResult, error)
+}
+
 // SMSService defines the core logic for handling SMS messages.
 type SMSService interface {
 	Send(ctx context.Context, sms dto.SMS) error
+	SendWithResult(ctx context.Context, sms dto.SMS) (dto.SendResult, error)
 	SendCode(ctx context.Context, to string, code string) error
+	SendCodeWithTTL(ctx context.Context, to string, code string, ttl time.Duration) error
 	ServiceName() string
+	CircuitStates() map[string]CircuitState
 }
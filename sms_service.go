@@ -3,12 +3,17 @@ package sen
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	adapter "github.com/lugondev/send-sen/adapters/sms"
 	"github.com/lugondev/send-sen/dto"
 
 	logger "github.com/lugondev/go-log"
 	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/ratelimit"
+	"github.com/lugondev/send-sen/templates"
 )
 
 // smsService implements the Service interface.
@@ -17,86 +22,206 @@ type smsService struct {
 	logger  logger.Logger
 	name    config.SMSProvider
 	from    string
+	dedup   *deduper
+	limits  rateLimits
+	// breakers holds the circuit breaker of each provider, for CircuitStates.
+	breakers map[string]interface{ State() CircuitState }
+	// templates holds the message catalogs of SendCode.
+	templates *templates.Registry
 }
 
 // NewSMSService creates a new instance of Service.
-// It requires an Adapter to be provided.
-func NewSMSService(cfg config.Config, logger logger.Logger) (SMSService, error) {
+// Each provider is wrapped with the cfg.Retry policy and the cfg.Breaker circuit
+// breaker, and when several providers are configured (cfg.Adapter.SMSChain) they
+// are wrapped in an SMSFailover, in order.
+// Messages with an IdempotencyKey are deduplicated through the cfg.Idempotency store,
+// and sends are throttled by the cfg.RateLimit limits. SendCode texts come from the
+// cfg.Templates message catalogs.
+func NewSMSService(cfg config.Config, logger logger.Logger, opts ...ServiceOption) (SMSService, error) {
 	ctx := context.Background()
-	var smsAdapter SMSAdapter
+	options := newServiceOptions(opts)
+	limits := newRateLimits("sms", cfg.RateLimit, ratelimit.New(options.rateLimitStore))
+	breakers := make(map[string]interface{ State() CircuitState })
+	var providers []Provider[SMSAdapter]
+	var names []string
 	var from string
-	if cfg.Adapter.SMS == config.SMSProviderBrevo {
-		brevoAdapter, err := adapter.NewBrevoAdapter(cfg.Brevo, logger)
+	for _, provider := range cfg.Adapter.SMSProviders() {
+		smsAdapter, sender, err := newSMSAdapter(cfg, provider, logger, options.httpClient(string(provider)))
 		if err != nil {
-			return nil, fmt.Errorf("failed to create Brevo SMS adapter: %w", err)
+			return nil, err
 		}
-		from = cfg.Brevo.SMSSender
-		logger.Info(ctx, "Using Brevo adapter for SMS sending")
-		smsAdapter = brevoAdapter
-	} else if cfg.Adapter.SMS == config.SMSProviderTwilio {
-		twilioAdapter, err := adapter.NewTwilioAdapter(cfg.Twilio, logger)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create Twilio SMS adapter: %w", err)
+		if cfg.Retry.MaxAttempts > 1 {
+			smsAdapter = NewSMSRetry(smsAdapter, cfg.Retry, logger)
+		}
+		if cfg.Breaker.FailureRatio > 0 {
+			breaker := NewSMSCircuitBreaker(smsAdapter, string(provider), cfg.Breaker, logger)
+			breakers[string(provider)] = breaker
+			smsAdapter = breaker
 		}
-		from = cfg.Twilio.FromNumber
-		logger.Info(ctx, "Using Twilio adapter for SMS sending")
-		smsAdapter = twilioAdapter
+		if limit, ok := limits.providerLimit(string(provider)); ok {
+			smsAdapter = NewSMSRateLimit(smsAdapter, limits.limiter, string(provider), limit)
+		}
+		logger.Info(ctx, "Using SMS adapter for SMS sending", map[string]any{
+			"adapter": provider,
+		})
+		if from == "" {
+			from = sender
+		}
+		providers = append(providers, Provider[SMSAdapter]{Name: string(provider), Adapter: smsAdapter})
+		names = append(names, string(provider))
+	}
+
+	var smsAdapter SMSAdapter
+	switch len(providers) {
+	case 0:
+		return nil, fmt.Errorf("no SMS provider configured: set adapter.sms or adapter.smsChain (%q for testing)", config.SMSProviderMock)
+	case 1:
+		smsAdapter = providers[0].Adapter
+	default:
+		smsAdapter = NewSMSFailover(logger, providers...)
+	}
+	name := config.SMSProvider(strings.Join(names, ","))
+	serviceLogger := logger.WithFields(map[string]any{
+		"service": "sms_service_" + name,
+	})
+	dedup, err := newDeduper(cfg.Idempotency, options.dedupStore, serviceLogger)
+	if err != nil {
+		return nil, err
 	}
-	if smsAdapter == nil {
-		smsAdapter = adapter.NewMockSMSAdapter(logger)
-		logger.Info(ctx, "Using MockSMS adapter for SMS sending")
-		from = "MockSender"
+	registry := options.templates
+	if registry == nil {
+		if registry, err = templates.New(cfg.Templates); err != nil {
+			return nil, fmt.Errorf("failed to load SMS messages: %w", err)
+		}
 	}
 	logger.Info(ctx, "SMS service initialized")
 
 	return &smsService{
-		adapter: smsAdapter,
-		logger: logger.WithFields(map[string]any{
-			"service": "sms_service_" + cfg.Adapter.SMS,
-		}),
-		name: cfg.Adapter.SMS,
-		from: from,
+		adapter:   smsAdapter,
+		logger:    serviceLogger,
+		name:      name,
+		from:      from,
+		dedup:     dedup,
+		limits:    limits,
+		breakers:  breakers,
+		templates: registry,
 	}, nil
 }
 
+// newSMSAdapter creates the adapter for a single SMS provider, along with its configured sender,
+// sending through httpClient when it is not nil.
+func newSMSAdapter(cfg config.Config, provider config.SMSProvider, logger logger.Logger, httpClient *http.Client) (SMSAdapter, string, error) {
+	var opts []adapter.Option
+	if httpClient != nil {
+		opts = append(opts, adapter.WithHTTPClient(httpClient))
+	}
+	switch provider {
+	case config.SMSProviderBrevo:
+		brevoAdapter, err := adapter.NewBrevoAdapter(cfg.Brevo, logger, opts...)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create Brevo SMS adapter: %w", err)
+		}
+		return brevoAdapter, cfg.Brevo.SMSSender, nil
+	case config.SMSProviderTwilio:
+		twilioAdapter, err := adapter.NewTwilioAdapter(cfg.Twilio, logger, opts...)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create Twilio SMS adapter: %w", err)
+		}
+		return twilioAdapter, cfg.Twilio.FromNumber, nil
+	case config.SMSProviderVonage:
+		vonageAdapter, err := adapter.NewVonageAdapter(cfg.Vonage, logger, opts...)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create Vonage SMS adapter: %w", err)
+		}
+		return vonageAdapter, cfg.Vonage.From, nil
+	case config.SMSProviderMessageBird:
+		messageBirdAdapter, err := adapter.NewMessageBirdAdapter(cfg.MessageBird, logger, opts...)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create MessageBird SMS adapter: %w", err)
+		}
+		return messageBirdAdapter, cfg.MessageBird.Originator, nil
+	case config.SMSProviderMock:
+		return adapter.NewMockSMSAdapter(logger), "MockSender", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported SMS provider %q", provider)
+	}
+}
+
 // Send validates the SMS data and delegates the sending task to the adapter.
 func (s *smsService) Send(ctx context.Context, sms dto.SMS) error {
+	_, err := s.SendWithResult(ctx, sms)
+	return err
+}
+
+// SendWithResult validates the SMS data, delegates the sending task to the adapter
+// and returns the provider's send result.
+func (s *smsService) SendWithResult(ctx context.Context, sms dto.SMS) (dto.SendResult, error) {
 	if sms.To == "" {
-		return fmt.Errorf("sms recipient ('To' phone number) cannot be empty")
+		return dto.SendResult{}, fmt.Errorf("sms recipient ('To' phone number) cannot be empty")
 	}
 	if sms.Message == "" {
-		return fmt.Errorf("sms message cannot be empty")
+		return dto.SendResult{}, fmt.Errorf("sms message cannot be empty")
 	}
 	if s.from == "" {
-		return fmt.Errorf("sms sender ('From') cannot be empty")
+		return dto.SendResult{}, fmt.Errorf("sms sender ('From') cannot be empty")
 	}
 
-	s.logger.Info(ctx, "Attempting to send SMS via adapter", map[string]any{
-		"to":   sms.To,
-		"from": s.from,
-	})
+	return s.dedup.do(ctx, "sms", sms.IdempotencyKey, func() (dto.SendResult, error) {
+		if err := s.limits.allow(ctx, sms.To); err != nil {
+			s.logger.Warn(ctx, "SMS rate limited", map[string]any{"to": sms.To, "error": err})
+			return dto.SendResult{}, err
+		}
 
-	// Delegate to the adapter
-	err := s.adapter.Send(ctx, sms)
-	if err != nil {
-		s.logger.Error(ctx, "Failed to send SMS via adapter", map[string]any{"error": err})
-		return fmt.Errorf("failed to send SMS via adapter: %w", err)
-	}
+		s.logger.Info(ctx, "Attempting to send SMS via adapter", map[string]any{
+			"to":   sms.To,
+			"from": s.from,
+		})
 
-	s.logger.Info(ctx, "SMS potentially sent successfully via adapter", map[string]any{"to": sms.To})
-	return nil
+		// Delegate to the adapter
+		result, err := sendSMS(ctx, s.adapter, sms)
+		if err != nil {
+			s.logger.Error(ctx, "Failed to send SMS via adapter", map[string]any{"error": err})
+			return dto.SendResult{}, fmt.Errorf("failed to send SMS via adapter: %w", err)
+		}
+		if result.Provider == "" {
+			result.Provider = string(s.name)
+		}
+
+		s.logger.Info(ctx, "SMS potentially sent successfully via adapter", map[string]any{
+			"to":         sms.To,
+			"provider":   result.Provider,
+			"message_id": result.MessageID,
+		})
+		return result, nil
+	})
 }
 
-// SendCode sends an SMS with a verification code.
+// SendCode sends an SMS with a verification code valid for DefaultCodeTTL.
 func (s *smsService) SendCode(ctx context.Context, to string, code string) error {
+	return s.SendCodeWithTTL(ctx, to, code, DefaultCodeTTL)
+}
+
+// SendCodeWithTTL sends an SMS with a verification code that expires after ttl.
+// The text is the "sms.verification_code" message in the locale of ctx, see WithLocale.
+func (s *smsService) SendCodeWithTTL(ctx context.Context, to string, code string, ttl time.Duration) error {
+	locale := LocaleFromContext(ctx)
 	s.logger.Info(ctx, "Sending verification code via SMS", map[string]any{
-		"to": to,
+		"to":     to,
+		"locale": locale,
+	})
+
+	text, err := s.templates.Message(locale, templates.SMSVerificationCode, map[string]string{
+		"Code":   code,
+		"Expiry": s.templates.FormatDuration(locale, ttl),
 	})
+	if err != nil {
+		return fmt.Errorf("failed to render verification code SMS: %w", err)
+	}
 
 	// Create the SMS message
 	message := dto.SMS{
 		To:      to,
-		Message: fmt.Sprintf("Your verification code is: %s. This code will expire in 10 minutes.", code),
+		Message: text,
 	}
 
 	// Send the SMS
@@ -107,3 +232,9 @@ func (s *smsService) SendCode(ctx context.Context, to string, code string) error
 func (s *smsService) ServiceName() string {
 	return string(s.name)
 }
+
+// CircuitStates reports the circuit breaker state of each provider, for health checks.
+// It is empty when cfg.Breaker is disabled.
+func (s *smsService) CircuitStates() map[string]CircuitState {
+	return circuitStates(s.breakers)
+}
@@ -0,0 +1,152 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Catalog keys used by the registry itself.
+const (
+	// dateTimeKey holds the Go time layout used by FormatTime.
+	dateTimeKey = "format.datetime"
+	// durationKeyPrefix prefixes the unit names used by FormatDuration, e.g.
+	// "duration.minute.one" = "%d minute" and "duration.minute.other" = "%d minutes".
+	durationKeyPrefix = "duration."
+)
+
+// defaultDateTimeLayout is used when no catalog defines format.datetime.
+const defaultDateTimeLayout = "January 2, 2006 at 15:04 MST"
+
+// normalizeLocale turns "vi_VN" or "VI-vn" into "vi-vn".
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// chain returns the locales to try for locale, most specific first: the
+// locale and its parents ("vi-vn", "vi"), then the default locale and its
+// parents, then the root.
+func (r *Registry) chain(locale string) []string {
+	var chain []string
+	seen := map[string]bool{"": true}
+	for _, l := range []string{locale, r.defaultLocale} {
+		l = normalizeLocale(l)
+		for l != "" {
+			if !seen[l] {
+				seen[l] = true
+				chain = append(chain, l)
+			}
+			i := strings.LastIndex(l, "-")
+			if i < 0 {
+				break
+			}
+			l = l[:i]
+		}
+	}
+	return append(chain, "")
+}
+
+// message looks key up along the fallback chain of locale.
+func (r *Registry) message(locale, key string) (*texttemplate.Template, bool) {
+	for _, candidate := range r.chain(locale) {
+		if t, ok := r.messages[candidate][key]; ok {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func (r *Registry) executeMessage(w io.Writer, locale, key string, data any) error {
+	t, ok := r.message(locale, key)
+	if !ok {
+		return fmt.Errorf("message %q not found", key)
+	}
+	return t.Execute(w, data)
+}
+
+// Message renders the catalog entry key in the closest available locale (see
+// RenderLocale) with data. Catalogs are messages.json files mapping keys to
+// text/template strings, at the root and under locales/<locale>/.
+func (r *Registry) Message(locale, key string, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := r.executeMessage(&buf, locale, key, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// FormatTime formats t with the "format.datetime" layout of the closest
+// available locale. Go layouts only know English month and day names, so
+// translations should use numeric layouts such as "15:04 02/01/2006".
+func (r *Registry) FormatTime(locale string, t time.Time) string {
+	layout, err := r.Message(locale, dateTimeKey, nil)
+	if err != nil || layout == "" {
+		layout = defaultDateTimeLayout
+	}
+	return t.Format(layout)
+}
+
+// FormatDuration renders d like FormatDuration, with the unit names of the
+// closest available locale.
+func (r *Registry) FormatDuration(locale string, d time.Duration) string {
+	return formatDuration(d, func(unit string, n int64) string {
+		plural := "other"
+		if n == 1 {
+			plural = "one"
+		}
+		pattern, err := r.Message(locale, durationKeyPrefix+unit+"."+plural, nil)
+		if err != nil || !strings.Contains(pattern, "%d") {
+			return englishUnit(unit, n)
+		}
+		return fmt.Sprintf(pattern, n)
+	})
+}
+
+// FormatDuration renders a lifetime in English for messages, e.g. "10 minutes",
+// "1 hour 30 minutes" or "45 seconds". It is rounded down to the largest two
+// units and never shorter than one second.
+func FormatDuration(d time.Duration) string {
+	return formatDuration(d, englishUnit)
+}
+
+func englishUnit(unit string, n int64) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+func formatDuration(d time.Duration, name func(unit string, n int64) string) string {
+	if d < time.Second {
+		d = time.Second
+	}
+	units := []struct {
+		size time.Duration
+		name string
+	}{
+		{24 * time.Hour, "day"},
+		{time.Hour, "hour"},
+		{time.Minute, "minute"},
+		{time.Second, "second"},
+	}
+
+	var parts []string
+	for _, unit := range units {
+		n := d / unit.size
+		if n == 0 {
+			if len(parts) > 0 {
+				break
+			}
+			continue
+		}
+		d -= n * unit.size
+		parts = append(parts, name(unit.name, int64(n)))
+		if len(parts) == 2 || d == 0 {
+			break
+		}
+	}
+	return strings.Join(parts, " ")
+}
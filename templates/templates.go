@@ -0,0 +1,464 @@
+// Package templates loads named email templates from file systems, parses
+// them once and renders their subject, HTML and text parts.
+//
+// A template named "welcome" is made of the files welcome.subject.tmpl,
+// welcome.html.tmpl and welcome.text.tmpl; at least one body part is
+// required. Names may contain directories ("billing/invoice"). Files starting
+// with an underscore are shared partials: _*.html.tmpl files are available to
+// every HTML part and _*.text.tmpl files to every text part, so a layout can be
+// defined once and used with {{template "layout" .}}.
+//
+// Templates at the root are the default language. Translations live under
+// locales/<locale>/ with the same layout, plus their own partials; see
+// RenderLocale for how a locale is resolved. Short texts (subjects, SMS
+// bodies, date formats) come from messages.json catalogs, see Message.
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/mailhtml"
+)
+
+// Built-in template names, used by the EmailService helpers.
+const (
+	Welcome          = "welcome"
+	PasswordReset    = "password_reset"
+	VerificationCode = "verification_code"
+	LoginWarning     = "login_warning"
+)
+
+// SMSVerificationCode is the catalog key of the SMSService.SendCode text.
+const SMSVerificationCode = "sms.verification_code"
+
+// defaultProductName is rendered by {{product}} when cfg.ProductName is empty.
+const defaultProductName = "MyService"
+
+// File name suffixes of the template parts, and the catalog file name.
+const (
+	subjectSuffix = ".subject.tmpl"
+	htmlSuffix    = ".html.tmpl"
+	textSuffix    = ".text.tmpl"
+	catalogFile   = "messages.json"
+	localesDir    = "locales/"
+)
+
+// Directory patterns skip files starting with "_", so translations are listed
+// file by file to keep their layouts.
+//
+//go:embed builtin/*.tmpl builtin/messages.json builtin/locales/*/*
+var builtin embed.FS
+
+// Builtin returns the built-in welcome, password reset, verification code and
+// login warning templates, their layout and message catalogs.
+func Builtin() fs.FS {
+	sub, _ := fs.Sub(builtin, "builtin")
+	return sub
+}
+
+// Rendered is the output of a template.
+type Rendered struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Registry holds parsed templates and message catalogs by locale. It is safe
+// for concurrent use.
+type Registry struct {
+	// Keyed by lower-case locale, "" being the root (default language).
+	templates     map[string]map[string]*emailTemplate
+	messages      map[string]map[string]*texttemplate.Template
+	defaultLocale string
+}
+
+type emailTemplate struct {
+	subject *texttemplate.Template // nil if the subject comes from the catalog
+	html    *htmltemplate.Template // nil if the template has no HTML part
+	text    *texttemplate.Template // nil if the template has no text part
+}
+
+// New loads the built-in templates, overridden by the templates in cfg.Dir
+// if it is set. {{product}} renders cfg.ProductName, and cfg.Locale is used
+// when no locale is requested.
+func New(cfg config.TemplateConfig) (*Registry, error) {
+	layers := []fs.FS{Builtin()}
+	if cfg.Dir != "" {
+		layers = append(layers, os.DirFS(cfg.Dir))
+	}
+	registry, err := Load(Funcs(cfg), layers...)
+	if err != nil {
+		return nil, err
+	}
+	registry.SetDefaultLocale(cfg.Locale)
+	return registry, nil
+}
+
+// Funcs returns the template functions New provides, for callers of Load that
+// override the built-in templates.
+func Funcs(cfg config.TemplateConfig) map[string]any {
+	productName := cfg.ProductName
+	if productName == "" {
+		productName = defaultProductName
+	}
+	return map[string]any{
+		"product": func() string { return productName },
+		// sanitize embeds user-supplied HTML, e.g. {{sanitize .Comment}}, keeping
+		// only safe formatting (see mailhtml.Sanitize).
+		"sanitize": func(fragment string) htmltemplate.HTML {
+			return htmltemplate.HTML(mailhtml.Sanitize(fragment))
+		},
+	}
+}
+
+// source holds the files of one locale ("" for the root).
+type source struct {
+	files   map[string][]byte // Path relative to the locale directory -> content
+	catalog map[string]string
+}
+
+// Load parses the templates and catalogs of the given file systems. A later
+// file system overrides earlier ones: a template whose HTML or text part it
+// provides is taken from it alone, so the parts of one email never come from
+// different layers (a template overridden with only an HTML part gets its text
+// derived from that HTML when sent). Partials and subject files replace the
+// file with the same path, and catalog entries are merged key by key. funcs are
+// made available to every template and message.
+func Load(funcs map[string]any, layers ...fs.FS) (*Registry, error) {
+	sources := map[string]*source{"": {files: make(map[string][]byte), catalog: make(map[string]string)}}
+	for _, fsys := range layers {
+		layer := make(map[string]map[string][]byte) // Locale -> path -> content
+		err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			locale, rel := "", name
+			if strings.HasPrefix(name, localesDir) {
+				locale, rel, _ = strings.Cut(strings.TrimPrefix(name, localesDir), "/")
+				locale = normalizeLocale(locale)
+			}
+			if path.Base(rel) != catalogFile && !strings.HasSuffix(rel, ".tmpl") {
+				return nil
+			}
+			data, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return err
+			}
+
+			src := sources[locale]
+			if src == nil {
+				src = &source{files: make(map[string][]byte), catalog: make(map[string]string)}
+				sources[locale] = src
+			}
+			if rel != catalogFile {
+				if layer[locale] == nil {
+					layer[locale] = make(map[string][]byte)
+				}
+				layer[locale][rel] = data
+				return nil
+			}
+			var catalog map[string]string
+			if err := json.Unmarshal(data, &catalog); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			for key, value := range catalog {
+				src.catalog[key] = value
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read templates: %w", err)
+		}
+		for locale, files := range layer {
+			overrideFiles(sources[locale].files, files)
+		}
+	}
+
+	registry := &Registry{
+		templates: make(map[string]map[string]*emailTemplate, len(sources)),
+		messages:  make(map[string]map[string]*texttemplate.Template, len(sources)),
+	}
+	root := sources[""]
+	for _, locale := range sortedKeys(sources) {
+		src := sources[locale]
+		messages, err := parseCatalog(src.catalog, funcs)
+		if err != nil {
+			return nil, fmt.Errorf("messages %s: %w", localeName(locale), err)
+		}
+		registry.messages[locale] = messages
+
+		// Translations see the root partials, overridden by their own.
+		var partials []map[string][]byte
+		if locale != "" {
+			partials = append(partials, root.files)
+		}
+		templates, err := parseTemplates(src.files, partials, funcs)
+		if err != nil {
+			return nil, err
+		}
+		registry.templates[locale] = templates
+	}
+
+	// Every template must have a subject, from its own file or the catalog.
+	for _, locale := range sortedKeys(registry.templates) {
+		for _, name := range sortedKeys(registry.templates[locale]) {
+			if registry.templates[locale][name].subject != nil {
+				continue
+			}
+			if _, ok := registry.message(locale, name+".subject"); !ok {
+				return nil, fmt.Errorf("template %s: missing %s%s or a %q message", localePath(locale, name), name, subjectSuffix, name+".subject")
+			}
+		}
+	}
+	return registry, nil
+}
+
+// overrideFiles adds the files of a layer to files. The earlier parts of a
+// template whose HTML or text part the layer provides are dropped first.
+func overrideFiles(files, layer map[string][]byte) {
+	for name := range layer {
+		suffix, ok := partSuffix(name)
+		if !ok || suffix == subjectSuffix || strings.HasPrefix(path.Base(name), "_") {
+			continue
+		}
+		templateName := strings.TrimSuffix(name, suffix)
+		for _, part := range []string{subjectSuffix, htmlSuffix, textSuffix} {
+			delete(files, templateName+part)
+		}
+	}
+	maps.Copy(files, layer)
+}
+
+// parseTemplates parses the templates in files. Partials are taken from
+// shared (in order) and then from files, later definitions winning.
+func parseTemplates(files map[string][]byte, shared []map[string][]byte, funcs map[string]any) (map[string]*emailTemplate, error) {
+	htmlBase := htmltemplate.New("").Funcs(funcs)
+	textBase := texttemplate.New("").Funcs(funcs)
+	addPartial := func(name, suffix string, src []byte) error {
+		var err error
+		switch suffix {
+		case htmlSuffix:
+			_, err = htmlBase.New(name).Parse(string(src))
+		case textSuffix:
+			_, err = textBase.New(name).Parse(string(src))
+		default:
+			err = fmt.Errorf("partials must be HTML or text parts")
+		}
+		if err != nil {
+			return fmt.Errorf("template %s: %w", name, err)
+		}
+		return nil
+	}
+
+	for _, layer := range shared {
+		for _, name := range sortedKeys(layer) {
+			if suffix, ok := partSuffix(name); ok && strings.HasPrefix(path.Base(name), "_") {
+				if err := addPartial(name, suffix, layer[name]); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	parts := make(map[string]map[string][]byte) // Template name -> suffix -> source
+	for _, name := range sortedKeys(files) {
+		suffix, ok := partSuffix(name)
+		if !ok {
+			return nil, fmt.Errorf("template %s: file name must end in %s, %s or %s", name, subjectSuffix, htmlSuffix, textSuffix)
+		}
+		if strings.HasPrefix(path.Base(name), "_") {
+			if err := addPartial(name, suffix, files[name]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		templateName := strings.TrimSuffix(name, suffix)
+		if parts[templateName] == nil {
+			parts[templateName] = make(map[string][]byte)
+		}
+		parts[templateName][suffix] = files[name]
+	}
+
+	templates := make(map[string]*emailTemplate, len(parts))
+	for name, sources := range parts {
+		t, err := parse(name, sources, funcs, htmlBase, textBase)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+		templates[name] = t
+	}
+	return templates, nil
+}
+
+func parse(name string, sources map[string][]byte, funcs map[string]any, htmlBase *htmltemplate.Template, textBase *texttemplate.Template) (*emailTemplate, error) {
+	if sources[htmlSuffix] == nil && sources[textSuffix] == nil {
+		return nil, fmt.Errorf("missing %s%s or %s%s", name, htmlSuffix, name, textSuffix)
+	}
+
+	var t emailTemplate
+	var err error
+	if source, ok := sources[subjectSuffix]; ok {
+		if t.subject, err = texttemplate.New(name).Funcs(funcs).Parse(string(source)); err != nil {
+			return nil, err
+		}
+	}
+	// Each template gets its own copy of the partials, so the blocks it defines
+	// (e.g. "body" for the layout) do not leak into other templates.
+	if source, ok := sources[htmlSuffix]; ok {
+		if t.html, err = htmlBase.Clone(); err != nil {
+			return nil, err
+		}
+		if t.html, err = t.html.New(name).Parse(string(source)); err != nil {
+			return nil, err
+		}
+	}
+	if source, ok := sources[textSuffix]; ok {
+		if t.text, err = textBase.Clone(); err != nil {
+			return nil, err
+		}
+		if t.text, err = t.text.New(name).Parse(string(source)); err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
+
+func parseCatalog(catalog map[string]string, funcs map[string]any) (map[string]*texttemplate.Template, error) {
+	messages := make(map[string]*texttemplate.Template, len(catalog))
+	for key, value := range catalog {
+		t, err := texttemplate.New(key).Funcs(funcs).Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		messages[key] = t
+	}
+	return messages, nil
+}
+
+// SetDefaultLocale sets the locale used when none is requested. Call it before
+// the registry is used.
+func (r *Registry) SetDefaultLocale(locale string) {
+	r.defaultLocale = locale
+}
+
+// Has reports whether a template with the given name is registered in the root language.
+func (r *Registry) Has(name string) bool {
+	_, ok := r.templates[""][name]
+	return ok
+}
+
+// Names returns the template names registered in the root language, sorted.
+func (r *Registry) Names() []string {
+	return sortedKeys(r.templates[""])
+}
+
+// Locales returns the locales with translations, sorted.
+func (r *Registry) Locales() []string {
+	var locales []string
+	for _, locale := range sortedKeys(r.templates) {
+		if locale != "" {
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
+
+// Render executes the named template in the default locale.
+func (r *Registry) Render(name string, data any) (Rendered, error) {
+	return r.RenderLocale("", name, data)
+}
+
+// RenderLocale executes the named template in the closest available locale:
+// for "vi-VN" it tries vi-VN, then vi, then the root templates. An empty locale
+// uses the registry's default locale. The subject comes from the template's
+// subject file or, failing that, from the "<name>.subject" message.
+func (r *Registry) RenderLocale(locale, name string, data any) (Rendered, error) {
+	t, ok := r.resolve(locale, name)
+	if !ok {
+		return Rendered{}, fmt.Errorf("template %q not found", name)
+	}
+
+	var rendered Rendered
+	var buf bytes.Buffer
+	var err error
+	if t.subject != nil {
+		err = t.subject.Execute(&buf, data)
+	} else {
+		err = r.executeMessage(&buf, locale, name+".subject", data)
+	}
+	if err != nil {
+		return Rendered{}, fmt.Errorf("failed to render %s subject: %w", name, err)
+	}
+	// Subjects are a single line.
+	rendered.Subject = strings.Join(strings.Fields(buf.String()), " ")
+
+	if t.html != nil {
+		buf.Reset()
+		if err := t.html.Execute(&buf, data); err != nil {
+			return Rendered{}, fmt.Errorf("failed to render %s HTML: %w", name, err)
+		}
+		rendered.HTML = strings.TrimSpace(buf.String())
+	}
+	if t.text != nil {
+		buf.Reset()
+		if err := t.text.Execute(&buf, data); err != nil {
+			return Rendered{}, fmt.Errorf("failed to render %s text: %w", name, err)
+		}
+		rendered.Text = strings.TrimSpace(buf.String())
+	}
+	return rendered, nil
+}
+
+// resolve returns the template from the first locale of the fallback chain that has it.
+func (r *Registry) resolve(locale, name string) (*emailTemplate, bool) {
+	for _, candidate := range r.chain(locale) {
+		if t, ok := r.templates[candidate][name]; ok {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func partSuffix(name string) (string, bool) {
+	for _, suffix := range []string{subjectSuffix, htmlSuffix, textSuffix} {
+		if strings.HasSuffix(name, suffix) {
+			return suffix, true
+		}
+	}
+	return "", false
+}
+
+func localePath(locale, name string) string {
+	if locale == "" {
+		return name
+	}
+	return localesDir + locale + "/" + name
+}
+
+func localeName(locale string) string {
+	if locale == "" {
+		return "(root)"
+	}
+	return locale
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
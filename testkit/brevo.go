@@ -0,0 +1,135 @@
+package testkit
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	brevo "github.com/getbrevo/brevo-go/lib"
+	"github.com/lugondev/send-sen/config"
+)
+
+const (
+	brevoEmailPath = "/smtp/email"
+	brevoSMSPath   = "/transactionalSMS/sms"
+)
+
+// Brevo fakes the Brevo transactional email and SMS APIs.
+type Brevo struct {
+	*Server
+	APIKey string
+}
+
+// NewBrevo starts a Brevo fake that is closed when the test ends. It accepts
+// requests authorized with APIKey, and rejects emails to addresses without an
+// @ and SMS to recipients that are not phone numbers.
+func NewBrevo(t testing.TB) *Brevo {
+	f := &Brevo{Server: &Server{}, APIKey: "xkeysib-testkit"}
+	f.authorize = func(r *http.Request) *Fault {
+		if r.Header.Get("api-key") != f.APIKey {
+			return &Fault{Status: http.StatusUnauthorized, Code: "unauthorized", Message: "Key not found"}
+		}
+		return nil
+	}
+	f.writeFault = func(w http.ResponseWriter, fault Fault) {
+		code := fault.Code
+		if code == "" {
+			code = strings.ToLower(strings.ReplaceAll(http.StatusText(fault.Status), " ", "_"))
+		}
+		writeJSON(w, fault.Status, map[string]string{"code": code, "message": fault.message()})
+	}
+	f.route = func(r *http.Request) (route, bool) {
+		switch r.URL.Path {
+		case brevoEmailPath:
+			return route{method: http.MethodPost, message: true, handle: f.sendEmail}, true
+		case brevoSMSPath:
+			return route{method: http.MethodPost, message: true, handle: f.sendSMS}, true
+		}
+		return route{}, false
+	}
+	f.start(t)
+	return f
+}
+
+// Config returns a Brevo config pointing at the fake.
+func (f *Brevo) Config() config.BrevoConfig {
+	return config.BrevoConfig{APIKey: f.APIKey, SenderEmail: "noreply@example.com", SenderName: "Send Sen", SMSSender: "SendSen", BaseURL: f.URL}
+}
+
+// Emails returns the emails the fake accepted, in order.
+func (f *Brevo) Emails() []brevo.SendSmtpEmail {
+	var emails []brevo.SendSmtpEmail
+	for _, r := range f.acceptedRequests(brevoEmailPath) {
+		var email brevo.SendSmtpEmail
+		if err := r.JSON(&email); err == nil {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// SMS returns the text messages the fake accepted, in order.
+func (f *Brevo) SMS() []brevo.SendTransacSms {
+	var messages []brevo.SendTransacSms
+	for _, r := range f.acceptedRequests(brevoSMSPath) {
+		var sms brevo.SendTransacSms
+		if err := r.JSON(&sms); err == nil {
+			messages = append(messages, sms)
+		}
+	}
+	return messages
+}
+
+func (f *Brevo) sendEmail(w http.ResponseWriter, r Request) {
+	var email brevo.SendSmtpEmail
+	if err := r.JSON(&email); err != nil {
+		f.writeFault(w, Fault{Status: http.StatusBadRequest, Code: "bad_request", Message: "Input must be a valid JSON object"})
+		return
+	}
+	if len(email.To) == 0 {
+		f.writeFault(w, Fault{Status: http.StatusBadRequest, Code: "missing_parameter", Message: "to is missing"})
+		return
+	}
+	for _, to := range email.To {
+		if !strings.Contains(to.Email, "@") {
+			f.writeFault(w, Fault{Status: http.StatusBadRequest, Code: "invalid_parameter", Message: "email is not valid in to"})
+			return
+		}
+	}
+	writeJSON(w, http.StatusCreated, brevo.CreateSmtpEmail{MessageId: fmt.Sprintf("<testkit-%d@smtp-relay.mailin.fr>", f.nextID())})
+}
+
+func (f *Brevo) sendSMS(w http.ResponseWriter, r Request) {
+	var sms brevo.SendTransacSms
+	if err := r.JSON(&sms); err != nil {
+		f.writeFault(w, Fault{Status: http.StatusBadRequest, Code: "bad_request", Message: "Input must be a valid JSON object"})
+		return
+	}
+	if !isPhoneNumber(sms.Recipient) {
+		f.writeFault(w, Fault{Status: http.StatusBadRequest, Code: "invalid_parameter", Message: "Invalid recipient number"})
+		return
+	}
+	id := f.nextID()
+	writeJSON(w, http.StatusCreated, brevo.SendSms{
+		Reference:   fmt.Sprintf("testkit-%d", id),
+		MessageId:   int64(id),
+		SmsCount:    1,
+		UsedCredits: 1,
+	})
+}
+
+// isPhoneNumber reports whether number looks like an international phone
+// number: 8 to 15 digits, optionally after a +.
+func isPhoneNumber(number string) bool {
+	digits := strings.TrimPrefix(number, "+")
+	if len(digits) < 8 || len(digits) > 15 {
+		return false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package testkit
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/lugondev/send-sen/config"
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+)
+
+const sendGridMailPath = "/v3/mail/send"
+
+// SendGrid fakes the SendGrid v3 mail/send API.
+type SendGrid struct {
+	*Server
+	APIKey string
+}
+
+// NewSendGrid starts a SendGrid fake that is closed when the test ends. It
+// accepts requests authorized with APIKey, and rejects messages without
+// recipients or with a recipient that is not an email address.
+func NewSendGrid(t testing.TB) *SendGrid {
+	f := &SendGrid{Server: &Server{}, APIKey: "SG.testkit"}
+	f.authorize = func(r *http.Request) *Fault {
+		if r.Header.Get("Authorization") != "Bearer "+f.APIKey {
+			return &Fault{Status: http.StatusUnauthorized, Message: "The provided authorization grant is invalid, expired, or revoked"}
+		}
+		return nil
+	}
+	f.writeFault = func(w http.ResponseWriter, fault Fault) {
+		var field any
+		if fault.Field != "" {
+			field = fault.Field
+		}
+		writeJSON(w, fault.Status, map[string]any{
+			"errors": []map[string]any{{"message": fault.message(), "field": field, "help": nil}},
+		})
+	}
+	f.route = func(r *http.Request) (route, bool) {
+		if r.URL.Path != sendGridMailPath {
+			return route{}, false
+		}
+		return route{method: http.MethodPost, message: true, handle: f.send}, true
+	}
+	f.start(t)
+	return f
+}
+
+// Config returns a SendGrid config pointing at the fake.
+func (f *SendGrid) Config() config.SendGridConfig {
+	return config.SendGridConfig{APIKey: f.APIKey, FromEmail: "noreply@example.com", FromName: "Send Sen", BaseURL: f.URL}
+}
+
+// Messages returns the messages the fake accepted, in order.
+func (f *SendGrid) Messages() []mail.SGMailV3 {
+	var messages []mail.SGMailV3
+	for _, r := range f.acceptedRequests(sendGridMailPath) {
+		var message mail.SGMailV3
+		if err := r.JSON(&message); err == nil {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+func (f *SendGrid) send(w http.ResponseWriter, r Request) {
+	var message mail.SGMailV3
+	if err := r.JSON(&message); err != nil {
+		f.writeFault(w, Fault{Status: http.StatusBadRequest, Message: "Bad Request"})
+		return
+	}
+	if len(message.Personalizations) == 0 || len(message.Personalizations[0].To) == 0 {
+		f.writeFault(w, Fault{Status: http.StatusBadRequest, Field: "personalizations.0.to", Message: "The to array is required for all personalization objects, and must have at least one email object with a valid email address."})
+		return
+	}
+	for i, p := range message.Personalizations {
+		for j, to := range p.To {
+			if !strings.Contains(to.Address, "@") {
+				f.writeFault(w, Fault{Status: http.StatusBadRequest, Field: fmt.Sprintf("personalizations.%d.to.%d.email", i, j), Message: "Does not contain a valid address."})
+				return
+			}
+		}
+	}
+	w.Header().Set("X-Message-Id", fmt.Sprintf("testkit-%d", f.nextID()))
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,108 @@
+package testkit
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lugondev/send-sen/config"
+)
+
+// Telegram fakes the Telegram Bot API methods used by the notify adapter:
+// getMe and sendMessage.
+type Telegram struct {
+	*Server
+	BotToken string
+	ChatID   int64
+}
+
+// NewTelegram starts a Telegram fake that is closed when the test ends. It
+// answers requests for BotToken, and only knows the chat ChatID; messages to
+// other chats fail with "chat not found".
+func NewTelegram(t testing.TB) *Telegram {
+	f := &Telegram{Server: &Server{}, BotToken: "123456:testkit", ChatID: 42}
+	f.authorize = func(r *http.Request) *Fault {
+		if !strings.HasPrefix(r.URL.Path, "/bot"+f.BotToken+"/") {
+			return &Fault{Status: http.StatusUnauthorized, Message: "Unauthorized"}
+		}
+		return nil
+	}
+	f.writeFault = func(w http.ResponseWriter, fault Fault) {
+		body := map[string]any{"ok": false, "error_code": fault.Status, "description": fault.message()}
+		if fault.RetryAfter > 0 {
+			seconds := int(fault.RetryAfter.Round(time.Second) / time.Second)
+			body["parameters"] = map[string]int{"retry_after": seconds}
+			if fault.Message == "" {
+				body["description"] = fmt.Sprintf("Too Many Requests: retry after %d", seconds)
+			}
+		}
+		// The Bot API reports errors in the body; the bot library ignores the status.
+		writeJSON(w, fault.Status, body)
+	}
+	f.route = func(r *http.Request) (route, bool) {
+		_, method, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		if !ok {
+			return route{}, false
+		}
+		switch method {
+		case "getMe":
+			return route{method: http.MethodPost, handle: f.getMe}, true
+		case "sendMessage":
+			return route{method: http.MethodPost, message: true, handle: f.sendMessage}, true
+		}
+		return route{}, false
+	}
+	f.start(t)
+	return f
+}
+
+func (f *Telegram) methodPath(method string) string {
+	return "/bot" + f.BotToken + "/" + method
+}
+
+// Config returns a Telegram config pointing at the fake.
+func (f *Telegram) Config() config.TelegramConfig {
+	return config.TelegramConfig{BotToken: f.BotToken, ChatID: strconv.FormatInt(f.ChatID, 10), BaseURL: f.URL}
+}
+
+// Messages returns the sendMessage forms (chat_id, text, parse_mode, ...) the
+// fake accepted, in order.
+func (f *Telegram) Messages() []url.Values {
+	var messages []url.Values
+	for _, r := range f.acceptedRequests(f.methodPath("sendMessage")) {
+		messages = append(messages, r.Form)
+	}
+	return messages
+}
+
+func (f *Telegram) getMe(w http.ResponseWriter, _ Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":     true,
+		"result": map[string]any{"id": 123456, "is_bot": true, "first_name": "Send Sen", "username": "send_sen_bot"},
+	})
+}
+
+func (f *Telegram) sendMessage(w http.ResponseWriter, r Request) {
+	chatID, err := strconv.ParseInt(r.Form.Get("chat_id"), 10, 64)
+	if err != nil || chatID != f.ChatID {
+		f.writeFault(w, Fault{Status: http.StatusBadRequest, Message: "Bad Request: chat not found"})
+		return
+	}
+	if strings.TrimSpace(r.Form.Get("text")) == "" {
+		f.writeFault(w, Fault{Status: http.StatusBadRequest, Message: "Bad Request: message text is empty"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok": true,
+		"result": map[string]any{
+			"message_id": f.nextID(),
+			"date":       time.Now().Unix(),
+			"chat":       map[string]any{"id": f.ChatID, "type": "private"},
+			"text":       r.Form.Get("text"),
+		},
+	})
+}
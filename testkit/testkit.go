@@ -0,0 +1,247 @@
+// Package testkit provides local fakes of the provider APIs, so adapters and
+// services can be tested offline. Each fake is an httptest server speaking
+// just enough of the provider API for the adapters in this module: it records
+// every request, checks credentials and can be told to fail or rate-limit.
+//
+// Point an adapter at a fake through the BaseURL of its config:
+//
+//	fake := testkit.NewSendGrid(t)
+//	adapter, err := email.NewSendGridAdapter(fake.Config(), log)
+package testkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Request is a request received by a fake.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Form   url.Values // Parsed form-encoded body (Twilio, Telegram), nil otherwise
+	Body   []byte
+	Status int // Status code the fake answered with
+}
+
+// JSON decodes the JSON body of the request into v.
+func (r Request) JSON(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Fault is an error response a fake returns instead of accepting a message.
+// The fake renders it in the provider's own error format.
+type Fault struct {
+	Status     int           // HTTP status, e.g. 400, 401, 429 or 503
+	Code       string        // Provider error code, e.g. "21211" for Twilio or "invalid_parameter" for Brevo
+	Field      string        // Field the error is reported against (SendGrid only)
+	Message    string        // Error message, defaults to the status text
+	RetryAfter time.Duration // Sent as Retry-After, and as retry_after by Telegram
+}
+
+// RateLimited returns a 429 fault asking the client to retry after retryAfter.
+func RateLimited(retryAfter time.Duration) Fault {
+	return Fault{Status: http.StatusTooManyRequests, RetryAfter: retryAfter}
+}
+
+// Unavailable returns a 503 fault, a transient provider outage.
+func Unavailable() Fault {
+	return Fault{Status: http.StatusServiceUnavailable}
+}
+
+func (f Fault) message() string {
+	if f.Message != "" {
+		return f.Message
+	}
+	return http.StatusText(f.Status)
+}
+
+// route handles one API endpoint of a fake. Faults and rate limits only apply
+// to message routes, not to lookups such as Telegram's getMe.
+type route struct {
+	method  string
+	message bool
+	handle  func(w http.ResponseWriter, r Request)
+}
+
+// Server is the part shared by every fake: the httptest server, the recorded
+// requests and the faults to return.
+type Server struct {
+	*httptest.Server
+
+	// authorize checks the credentials of a request, returning a fault when they are wrong.
+	authorize func(r *http.Request) *Fault
+	// writeFault renders a fault in the provider's error format.
+	writeFault func(w http.ResponseWriter, fault Fault)
+	// route finds the endpoint for a request.
+	route func(r *http.Request) (route, bool)
+
+	mu         sync.Mutex
+	requests   []Request
+	faults     []Fault
+	limit      int // Messages accepted before rate limiting, 0 for no limit
+	retryAfter time.Duration
+	accepted   int
+	ids        int
+}
+
+func (s *Server) start(t testing.TB) {
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeFault(w, Fault{Status: http.StatusBadRequest, Message: err.Error()})
+		return
+	}
+	request := Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Body: body}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/x-www-form-urlencoded" {
+		request.Form, _ = url.ParseQuery(string(body))
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	// The status is recorded as it is written, before the client sees the response.
+	s.mu.Lock()
+	s.requests = append(s.requests, request)
+	index := len(s.requests) - 1
+	s.mu.Unlock()
+	recorder := &statusRecorder{ResponseWriter: w, record: func(status int) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if index < len(s.requests) {
+			s.requests[index].Status = status
+		}
+	}}
+	s.handle(recorder, r, request)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, request Request) {
+	rt, ok := s.route(r)
+	if !ok || rt.method != r.Method {
+		s.writeFault(w, Fault{Status: http.StatusNotFound})
+		return
+	}
+	if fault := s.authorize(r); fault != nil {
+		s.writeFault(w, *fault)
+		return
+	}
+	if rt.message {
+		if fault, ok := s.nextFault(); ok {
+			if fault.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter.Round(time.Second)/time.Second)))
+			}
+			s.writeFault(w, fault)
+			return
+		}
+	}
+	rt.handle(w, request)
+}
+
+// nextFault returns the fault for the next message: a queued one, or a rate
+// limit once the limit is reached. Otherwise the message counts as accepted.
+func (s *Server) nextFault() (Fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.faults) > 0 {
+		fault := s.faults[0]
+		s.faults = s.faults[1:]
+		return fault, true
+	}
+	if s.limit > 0 && s.accepted >= s.limit {
+		return RateLimited(s.retryAfter), true
+	}
+	s.accepted++
+	return Fault{}, false
+}
+
+// nextID returns a new sequence number for message IDs.
+func (s *Server) nextID() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ids++
+	return s.ids
+}
+
+// Requests returns every request received so far, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// FailNext makes the next messages fail with faults, one fault per message.
+func (s *Server) FailNext(faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, faults...)
+}
+
+// SetRateLimit accepts limit more messages, then rate-limits every message
+// with a 429 asking to retry after retryAfter. A limit of 0 removes the limit.
+func (s *Server) SetRateLimit(limit int, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limit = limit
+	s.retryAfter = retryAfter
+	s.accepted = 0
+}
+
+// Reset forgets the recorded requests, queued faults and rate limit.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+	s.faults = nil
+	s.limit = 0
+	s.accepted = 0
+}
+
+// acceptedRequests returns the requests to path that the fake answered with a 2xx status.
+func (s *Server) acceptedRequests(path string) []Request {
+	var accepted []Request
+	for _, r := range s.Requests() {
+		if r.Path == path && r.Status >= 200 && r.Status < 300 {
+			accepted = append(accepted, r)
+		}
+	}
+	return accepted
+}
+
+// statusRecorder records the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	record  func(status int)
+	written bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.written {
+		r.written = true
+		r.record(status)
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if !r.written {
+		r.WriteHeader(http.StatusOK)
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,97 @@
+package testkit
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lugondev/send-sen/config"
+)
+
+// Twilio fakes the Twilio Programmable Messaging API.
+type Twilio struct {
+	*Server
+	AccountSid string
+	AuthToken  string // The Twilio SDK only sends alphanumeric credentials
+}
+
+// NewTwilio starts a Twilio fake that is closed when the test ends. It accepts
+// requests for AccountSid authenticated with AccountSid and AuthToken, and
+// rejects messages to recipients that are not phone numbers (error 21211).
+func NewTwilio(t testing.TB) *Twilio {
+	f := &Twilio{Server: &Server{}, AccountSid: "ACtestkit", AuthToken: "testkittoken"}
+	f.authorize = func(r *http.Request) *Fault {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != f.AccountSid || password != f.AuthToken {
+			return &Fault{Status: http.StatusUnauthorized, Code: "20003", Message: "Authenticate"}
+		}
+		return nil
+	}
+	f.writeFault = func(w http.ResponseWriter, fault Fault) {
+		code, _ := strconv.Atoi(fault.Code)
+		if code == 0 {
+			code = map[int]int{http.StatusUnauthorized: 20003, http.StatusNotFound: 20404, http.StatusTooManyRequests: 20429}[fault.Status]
+		}
+		writeJSON(w, fault.Status, map[string]any{
+			"code":      code,
+			"message":   fault.message(),
+			"more_info": fmt.Sprintf("https://www.twilio.com/docs/errors/%d", code),
+			"status":    fault.Status,
+		})
+	}
+	f.route = func(r *http.Request) (route, bool) {
+		if r.URL.Path != f.messagesPath() {
+			return route{}, false
+		}
+		return route{method: http.MethodPost, message: true, handle: f.send}, true
+	}
+	f.start(t)
+	return f
+}
+
+func (f *Twilio) messagesPath() string {
+	return "/2010-04-01/Accounts/" + f.AccountSid + "/Messages.json"
+}
+
+// Config returns a Twilio config pointing at the fake.
+func (f *Twilio) Config() config.TwilioConfig {
+	return config.TwilioConfig{AccountSid: f.AccountSid, AuthToken: f.AuthToken, FromNumber: "+15005550006", BaseURL: f.URL}
+}
+
+// Messages returns the forms (To, From, Body, ...) of the messages the fake
+// accepted, in order.
+func (f *Twilio) Messages() []url.Values {
+	var messages []url.Values
+	for _, r := range f.acceptedRequests(f.messagesPath()) {
+		messages = append(messages, r.Form)
+	}
+	return messages
+}
+
+func (f *Twilio) send(w http.ResponseWriter, r Request) {
+	to := r.Form.Get("To")
+	if !isPhoneNumber(to) {
+		f.writeFault(w, Fault{Status: http.StatusBadRequest, Code: "21211", Message: fmt.Sprintf("The 'To' number %s is not a valid phone number.", to)})
+		return
+	}
+	if r.Form.Get("Body") == "" {
+		f.writeFault(w, Fault{Status: http.StatusBadRequest, Code: "21602", Message: "Message body is required."})
+		return
+	}
+	sid := fmt.Sprintf("SM%032x", f.nextID())
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"sid":          sid,
+		"account_sid":  f.AccountSid,
+		"to":           to,
+		"from":         r.Form.Get("From"),
+		"body":         r.Form.Get("Body"),
+		"status":       "queued",
+		"num_segments": "1",
+		"date_created": time.Now().UTC().Format(time.RFC1123Z),
+		"uri":          strings.TrimSuffix(f.messagesPath(), ".json") + "/" + sid + ".json",
+	})
+}
@@ -6,35 +6,41 @@ import (
 
 	"github.com/lugondev/send-sen/adapters/email"
 	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/testkit"
 
 	logger "github.com/lugondev/go-log"
-	"github.com/lugondev/send-sen/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewBrevoAdapter(t *testing.T) {
-	// Load real config
-	cfg, err := config.LoadConfig("../../config")
-	assert.NoError(t, err, "Failed to load config")
+	fake := testkit.NewBrevo(t)
 
 	// Initialize logger
 	log, err := logger.NewLogger(&logger.Option{
-		Format:       cfg.Log.Format,
+		Format:       "console",
 		ScopeName:    "send-sen",
 		ScopeVersion: "v0.1.1",
 	})
-	assert.NoError(t, err, "Failed to create logger")
+	require.NoError(t, err, "Failed to create logger")
 
-	brevoAdapter, err := email.NewBrevoAdapter(cfg.Brevo, log)
-	assert.NoError(t, err, "Failed to create Brevo adapter")
+	brevoAdapter, err := email.NewBrevoAdapter(fake.Config(), log)
+	require.NoError(t, err, "Failed to create Brevo adapter")
 	assert.NotNil(t, brevoAdapter, "Brevo adapter should not be nil")
 
 	// Create a message using the adapter's Email type
 	err = brevoAdapter.SendEmail(context.Background(), dto.Email{
-		To:      []string{"lugondev@gmail.com"},
+		To:      []string{"alice@example.com"},
 		Subject: "Test Message",
 		Body:    "This is a test email",
 		Html:    "<p>This is a test email</p>",
 	})
-	assert.NoError(t, err, "Failed to send email")
+	require.NoError(t, err, "Failed to send email")
+
+	emails := fake.Emails()
+	require.Len(t, emails, 1)
+	assert.Equal(t, "Test Message", emails[0].Subject)
+	assert.Equal(t, "alice@example.com", emails[0].To[0].Email)
+	assert.Equal(t, "This is a test email", emails[0].TextContent)
+	assert.Equal(t, "<p>This is a test email</p>", emails[0].HtmlContent)
 }
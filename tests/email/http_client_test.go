@@ -0,0 +1,74 @@
+package email
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/adapters/email"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// markingTransport tags every request so the stub can tell the injected client was used.
+type markingTransport struct{}
+
+func (markingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("X-Injected-Client", "yes")
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestEmailAdapters_HTTPClientAndBaseURL(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "yes", r.Header.Get("X-Injected-Client"))
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/v3/mail/send":
+			w.Header().Set("X-Message-Id", "sg-1")
+			w.WriteHeader(http.StatusAccepted)
+		case "/smtp/email":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"messageId":"<brevo-1@smtp-relay.mailin.fr>"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	opts := []email.Option{
+		email.WithHTTPClient(&http.Client{Transport: markingTransport{}}),
+		email.WithBaseURL(server.URL),
+	}
+
+	adapters := []struct {
+		name string
+		new  func() (sen.EmailAdapter, error)
+		path string
+	}{
+		{"sendgrid", func() (sen.EmailAdapter, error) {
+			return email.NewSendGridAdapter(config.SendGridConfig{APIKey: "SG.test", FromEmail: "noreply@example.com", BaseURL: "https://api.sendgrid.invalid"}, log, opts...)
+		}, "/v3/mail/send"},
+		{"brevo", func() (sen.EmailAdapter, error) {
+			return email.NewBrevoAdapter(config.BrevoConfig{APIKey: "xkeysib-test", SenderEmail: "noreply@example.com"}, log, opts...)
+		}, "/smtp/email"},
+	}
+	for _, a := range adapters {
+		t.Run(a.name, func(t *testing.T) {
+			paths = nil
+			emailAdapter, err := a.new()
+			require.NoError(t, err)
+			err = emailAdapter.SendEmail(context.Background(), dto.Email{To: []string{"alice@example.com"}, Subject: "Hello", Body: "Hello"})
+			require.NoError(t, err)
+			assert.Equal(t, []string{a.path}, paths)
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package email
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/adapters/email"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMailgunAdapter_SendEmail(t *testing.T) {
+	var form map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/mg.example.com/messages", r.URL.Path)
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "api", user)
+		assert.Equal(t, "key-test", pass)
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		form = r.MultipartForm.Value
+		_, _ = w.Write([]byte(`{"id":"<20240101.1@mg.example.com>","message":"Queued. Thank you."}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	mailgunAdapter, err := email.NewMailgunAdapter(config.MailgunConfig{
+		APIKey:    "key-test",
+		Domain:    "mg.example.com",
+		BaseURL:   server.URL,
+		FromEmail: "noreply@mg.example.com",
+		FromName:  "Send Sen",
+		Tags:      []string{"transactional", "welcome"},
+		Variables: map[string]string{"user_id": "42"},
+	}, log)
+	require.NoError(t, err)
+
+	result, err := mailgunAdapter.SendEmailWithResult(context.Background(), dto.Email{
+		To:      []string{"alice@example.com", "dave@example.com"},
+		Cc:      []string{"bob@example.com"},
+		Bcc:     []string{"carol@example.com"},
+		Subject: "Test Message",
+		Body:    "This is a test email",
+		Html:    "<p>This is a test email</p>",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{`"Send Sen" <noreply@mg.example.com>`}, form["from"])
+	assert.Equal(t, []string{"alice@example.com", "dave@example.com"}, form["to"])
+	assert.Equal(t, []string{"bob@example.com"}, form["cc"])
+	assert.Equal(t, []string{"carol@example.com"}, form["bcc"])
+	assert.Equal(t, []string{"Test Message"}, form["subject"])
+	assert.Equal(t, []string{"This is a test email"}, form["text"])
+	assert.Equal(t, []string{"<p>This is a test email</p>"}, form["html"])
+	assert.Equal(t, []string{"transactional", "welcome"}, form["o:tag"])
+	assert.Equal(t, []string{"42"}, form["v:user_id"])
+
+	assert.Equal(t, "mailgun", result.Provider)
+	assert.Equal(t, "<20240101.1@mg.example.com>", result.MessageID)
+	assert.Equal(t, "Queued. Thank you.", result.Status)
+	assert.Equal(t, []string{"alice@example.com", "dave@example.com", "bob@example.com", "carol@example.com"}, result.Accepted)
+}
+
+func TestMailgunAdapter_MessageFields(t *testing.T) {
+	var form map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		form = r.MultipartForm.Value
+		_, _ = w.Write([]byte(`{"id":"<20240101.1@mg.example.com>","message":"Queued. Thank you."}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	mailgunAdapter, err := email.NewMailgunAdapter(config.MailgunConfig{
+		APIKey:    "key-test",
+		Domain:    "mg.example.com",
+		BaseURL:   server.URL,
+		FromEmail: "noreply@mg.example.com",
+		Tags:      []string{"transactional"},
+		Variables: map[string]string{"app": "send-sen", "user_id": "0"},
+	}, log)
+	require.NoError(t, err)
+
+	err = mailgunAdapter.SendEmail(context.Background(), dto.Email{
+		From:     "Support <support@mg.example.com>",
+		ReplyTo:  "tickets@example.com",
+		To:       []string{"alice@example.com"},
+		Subject:  "Your ticket",
+		Body:     "We are on it",
+		Headers:  map[string]string{"X-Ticket-ID": "T-1"},
+		Tags:     []string{"support"},
+		Metadata: map[string]string{"user_id": "42"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Support <support@mg.example.com>"}, form["from"])
+	assert.Equal(t, []string{"tickets@example.com"}, form["h:Reply-To"])
+	assert.Equal(t, []string{"T-1"}, form["h:X-Ticket-ID"])
+	assert.Equal(t, []string{"transactional", "support"}, form["o:tag"])
+	assert.Equal(t, []string{"send-sen"}, form["v:app"])
+	assert.Equal(t, []string{"42"}, form["v:user_id"])
+}
+
+func TestMailgunAdapter_Errors(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	_, err = email.NewMailgunAdapter(config.MailgunConfig{
+		APIKey:    "key-test",
+		Domain:    "mg.example.com",
+		Region:    "apac",
+		FromEmail: "noreply@mg.example.com",
+	}, log)
+	assert.Error(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message":"Invalid private key"}`))
+	}))
+	defer server.Close()
+
+	mailgunAdapter, err := email.NewMailgunAdapter(config.MailgunConfig{
+		APIKey:    "key-wrong",
+		Domain:    "mg.example.com",
+		BaseURL:   server.URL,
+		FromEmail: "noreply@mg.example.com",
+	}, log)
+	require.NoError(t, err)
+
+	err = mailgunAdapter.SendEmail(context.Background(), dto.Email{
+		To:      []string{"alice@example.com"},
+		Subject: "Test Message",
+		Body:    "This is a test email",
+	})
+	assert.ErrorContains(t, err, "Invalid private key")
+	assert.ErrorIs(t, err, sen.ErrAuthentication)
+
+	// Throttled requests carry the provider's Retry-After hint
+	throttled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"message":"Too many requests"}`))
+	}))
+	defer throttled.Close()
+
+	mailgunAdapter, err = email.NewMailgunAdapter(config.MailgunConfig{
+		APIKey:    "key-test",
+		Domain:    "mg.example.com",
+		BaseURL:   throttled.URL,
+		FromEmail: "noreply@mg.example.com",
+	}, log)
+	require.NoError(t, err)
+
+	err = mailgunAdapter.SendEmail(context.Background(), dto.Email{
+		To:      []string{"alice@example.com"},
+		Subject: "Test Message",
+		Body:    "This is a test email",
+	})
+	assert.ErrorIs(t, err, sen.ErrRateLimited)
+	assert.True(t, sen.IsTransient(err))
+	retryAfter, ok := sen.RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, retryAfter)
+}
+
+func TestMailgunEmailService(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"id":"<1@mg.example.com>","message":"Queued. Thank you."}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	emailService, err := sen.NewEmailService(config.Config{
+		Adapter: config.AdapterConfig{Email: config.EmailMailgun},
+		Mailgun: config.MailgunConfig{
+			APIKey:    "key-test",
+			Domain:    "mg.example.com",
+			BaseURL:   server.URL,
+			FromEmail: "noreply@mg.example.com",
+		},
+	}, log)
+	require.NoError(t, err)
+
+	err = emailService.SendWelcome(context.Background(), "alice@example.com", "Alice")
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+}
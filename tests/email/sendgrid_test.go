@@ -2,38 +2,183 @@ package email
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
 	"github.com/lugondev/send-sen/adapters/email"
 	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/testkit"
 
 	"github.com/lugondev/send-sen/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewSendgridAdapter(t *testing.T) {
-	// Load real config
-	cfg, err := config.LoadConfig("../../config")
-	assert.NoError(t, err, "Failed to load config")
+	fake := testkit.NewSendGrid(t)
 
 	// Initialize logger
 	log, err := logger.NewLogger(&logger.Option{
-		Format:       cfg.Log.Format,
+		Format:       "console",
 		ScopeName:    "send-sen",
 		ScopeVersion: "v0.1.1",
 	})
-	assert.NoError(t, err, "Failed to create logger")
+	require.NoError(t, err, "Failed to create logger")
 
-	sendgridAdapter, err := email.NewSendGridAdapter(cfg.SendGrid, log)
-	assert.NoError(t, err, "Failed to create SendGrid adapter")
+	sendgridAdapter, err := email.NewSendGridAdapter(fake.Config(), log)
+	require.NoError(t, err, "Failed to create SendGrid adapter")
 	assert.NotNil(t, sendgridAdapter, "SendGrid adapter should not be nil")
 
 	err = sendgridAdapter.SendEmail(context.Background(), dto.Email{
-		To:      []string{"lugondev@gmail.com"},
+		To:      []string{"alice@example.com"},
 		Subject: "Test Message",
 		Body:    "This is a test email",
 		Html:    "<p>This is a test email</p>",
 	})
-	assert.NoError(t, err, "Failed to send email")
+	require.NoError(t, err, "Failed to send email")
+
+	messages := fake.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "Test Message", messages[0].Subject)
+	assert.Equal(t, "alice@example.com", messages[0].Personalizations[0].To[0].Address)
+}
+
+func TestSendGridAdapter_FakeFaults(t *testing.T) {
+	fake := testkit.NewSendGrid(t)
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	sendgridAdapter, err := email.NewSendGridAdapter(fake.Config(), log)
+	require.NoError(t, err)
+	message := dto.Email{To: []string{"alice@example.com"}, Subject: "Test Message", Body: "Hello"}
+
+	fake.FailNext(testkit.RateLimited(2*time.Second), testkit.Unavailable())
+	err = sendgridAdapter.SendEmail(context.Background(), message)
+	assert.ErrorIs(t, err, sen.ErrRateLimited)
+	var providerErr *sen.ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, 2*time.Second, providerErr.RetryDelay)
+	assert.ErrorIs(t, sendgridAdapter.SendEmail(context.Background(), message), sen.ErrTransient)
+	assert.NoError(t, sendgridAdapter.SendEmail(context.Background(), message))
+
+	message.To = []string{"not-an-address"}
+	assert.ErrorIs(t, sendgridAdapter.SendEmail(context.Background(), message), sen.ErrInvalidRecipient)
+	assert.Len(t, fake.Requests(), 4)
+	assert.Len(t, fake.Messages(), 1)
+}
+
+// sendGridRequest is the part of the v3 mail/send body the tests inspect.
+type sendGridRequest struct {
+	From    struct{ Email, Name string } `json:"from"`
+	Content []struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	} `json:"content"`
+	Personalizations []struct {
+		To []struct{ Email string } `json:"to"`
+	} `json:"personalizations"`
+}
+
+func newSendGridStub(t *testing.T, received *sendGridRequest, status int, body string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v3/mail/send", r.URL.Path)
+		assert.Equal(t, "Bearer SG.test", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(received))
+		w.Header().Set("X-Message-Id", "sg-message-1")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestSendGridAdapter(t *testing.T, baseURL string) *email.SendGridAdapter {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	sendgridAdapter, err := email.NewSendGridAdapter(config.SendGridConfig{
+		APIKey:    "SG.test",
+		FromEmail: "noreply@example.com",
+		FromName:  "Send Sen",
+		BaseURL:   baseURL,
+	}, log)
+	require.NoError(t, err)
+	return sendgridAdapter
+}
+
+func TestSendGridAdapter_Content(t *testing.T) {
+	tests := []struct {
+		name  string
+		email dto.Email
+		want  [][2]string // Content type and value, in order
+	}{
+		{
+			name:  "text and HTML",
+			email: dto.Email{Body: "Hello Alice", Html: "<p>Hello <b>Alice</b></p>"},
+			want:  [][2]string{{"text/plain", "Hello Alice"}, {"text/html", "<p>Hello <b>Alice</b></p>"}},
+		},
+		{
+			name:  "HTML only generates the text part",
+			email: dto.Email{Html: "<html><head><title>Hi</title><style>p{}</style></head><body><h1>Welcome</h1><p>Hello <b>Alice</b> &amp; Bob,<br>see you soon</p></body></html>"},
+			want: [][2]string{
+				{"text/plain", "Welcome\n=======\n\nHello Alice & Bob,\nsee you soon"},
+				{"text/html", "<html><head><title>Hi</title><style>p{}</style></head><body><h1>Welcome</h1><p>Hello <b>Alice</b> &amp; Bob,<br>see you soon</p></body></html>"},
+			},
+		},
+		{
+			name:  "text only",
+			email: dto.Email{Body: "Hello Alice"},
+			want:  [][2]string{{"text/plain", "Hello Alice"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received sendGridRequest
+			server := newSendGridStub(t, &received, http.StatusAccepted, "")
+			sendgridAdapter := newTestSendGridAdapter(t, server.URL)
+
+			tt.email.To = []string{"alice@example.com"}
+			tt.email.Subject = "Test Message"
+			result, err := sendgridAdapter.SendEmailWithResult(context.Background(), tt.email)
+			require.NoError(t, err)
+			assert.Equal(t, "sg-message-1", result.MessageID)
+			assert.Equal(t, "noreply@example.com", received.From.Email)
+			require.Len(t, received.Personalizations, 1)
+			assert.Equal(t, "alice@example.com", received.Personalizations[0].To[0].Email)
+
+			var content [][2]string
+			for _, c := range received.Content {
+				content = append(content, [2]string{c.Type, c.Value})
+			}
+			assert.Equal(t, tt.want, content)
+		})
+	}
+}
+
+func TestSendGridAdapter_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		kind   error
+	}{
+		{"invalid recipient", http.StatusBadRequest, `{"errors":[{"message":"Does not contain a valid address.","field":"personalizations.0.to.0.email"}]}`, sen.ErrInvalidRecipient},
+		{"unauthorized", http.StatusUnauthorized, `{"errors":[{"message":"The provided authorization grant is invalid"}]}`, sen.ErrAuthentication},
+		{"rate limited", http.StatusTooManyRequests, `{"errors":[{"message":"too many requests"}]}`, sen.ErrRateLimited},
+		{"server error", http.StatusInternalServerError, `{"errors":[{"message":"internal error"}]}`, sen.ErrTransient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received sendGridRequest
+			server := newSendGridStub(t, &received, tt.status, tt.body)
+			sendgridAdapter := newTestSendGridAdapter(t, server.URL)
+
+			err := sendgridAdapter.SendEmail(context.Background(), dto.Email{To: []string{"alice@example.com"}, Subject: "Test Message", Body: "Hello"})
+			assert.ErrorIs(t, err, tt.kind)
+		})
+	}
 }
@@ -0,0 +1,259 @@
+package email
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/adapters/email"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sesAuthorizationPattern = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=([^/]+)/(\d{8})/([^/]+)/ses/aws4_request, SignedHeaders=([^,]+), Signature=([0-9a-f]{64})$`)
+
+// verifySESSignature recomputes the SigV4 signature of a request received by the fake SES server.
+func verifySESSignature(t *testing.T, r *http.Request, body []byte, secret string) {
+	t.Helper()
+	match := sesAuthorizationPattern.FindStringSubmatch(r.Header.Get("Authorization"))
+	require.NotNil(t, match, "malformed Authorization header: %s", r.Header.Get("Authorization"))
+	date, region, signedHeaders, signature := match[2], match[3], match[4], match[5]
+
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + value + "\n")
+	}
+	bodyHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		r.Method, r.URL.EscapedPath(), r.URL.RawQuery,
+		canonicalHeaders.String(), signedHeaders, hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256", r.Header.Get("X-Amz-Date"),
+		date + "/" + region + "/ses/aws4_request", hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := []byte("AWS4" + secret)
+	for _, part := range []string{date, region, "ses", "aws4_request", stringToSign} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+	assert.Equal(t, hex.EncodeToString(key), signature, "SigV4 signature mismatch")
+}
+
+func TestSESAdapter_SendEmail(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v2/email/outbound-emails", r.URL.Path)
+		assert.Equal(t, "session-token", r.Header.Get("X-Amz-Security-Token"))
+		body, _ := io.ReadAll(r.Body)
+		verifySESSignature(t, r, body, "test-secret")
+		assert.Contains(t, r.Header.Get("Authorization"), "Credential=AKIDEXAMPLE/")
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/ses/aws4_request")
+		assert.NoError(t, json.Unmarshal(body, &received))
+		_, _ = w.Write([]byte(`{"MessageId":"0100018c-test"}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	sesAdapter, err := email.NewSESAdapter(config.SESConfig{
+		Region:           "eu-west-1",
+		AccessKeyID:      "AKIDEXAMPLE",
+		SecretAccessKey:  "test-secret",
+		SessionToken:     "session-token",
+		FromEmail:        "noreply@example.com",
+		FromName:         "Send Sen",
+		ConfigurationSet: "transactional",
+		Tags:             []config.SESTag{{Name: "env", Value: "test"}, {Name: "app", Value: "send-sen"}},
+		Endpoint:         server.URL,
+	}, log)
+	require.NoError(t, err)
+
+	err = sesAdapter.SendEmail(context.Background(), dto.Email{
+		To:      []string{"alice@example.com"},
+		Cc:      []string{"bob@example.com"},
+		Bcc:     []string{"carol@example.com"},
+		Subject: "Test Message",
+		Body:    "This is a test email",
+		Html:    "<p>This is a test email</p>",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, `"Send Sen" <noreply@example.com>`, received["FromEmailAddress"])
+	assert.Equal(t, "transactional", received["ConfigurationSetName"])
+	destination := received["Destination"].(map[string]any)
+	assert.Equal(t, []any{"alice@example.com"}, destination["ToAddresses"])
+	assert.Equal(t, []any{"bob@example.com"}, destination["CcAddresses"])
+	assert.Equal(t, []any{"carol@example.com"}, destination["BccAddresses"])
+	assert.Equal(t, []any{
+		map[string]any{"Name": "app", "Value": "send-sen"},
+		map[string]any{"Name": "env", "Value": "test"},
+	}, received["EmailTags"])
+
+	simple := received["Content"].(map[string]any)["Simple"].(map[string]any)
+	assert.Equal(t, "Test Message", simple["Subject"].(map[string]any)["Data"])
+	body := simple["Body"].(map[string]any)
+	assert.Equal(t, "This is a test email", body["Text"].(map[string]any)["Data"])
+	assert.Equal(t, "<p>This is a test email</p>", body["Html"].(map[string]any)["Data"])
+}
+
+func TestSESAdapter_MessageFields(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		_, _ = w.Write([]byte(`{"MessageId":"0100018c-test"}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	sesAdapter, err := email.NewSESAdapter(config.SESConfig{
+		Region:          "eu-west-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "test-secret",
+		FromEmail:       "noreply@example.com",
+		Tags:            []config.SESTag{{Name: "app", Value: "send-sen"}, {Name: "user_id", Value: "0"}},
+		Endpoint:        server.URL,
+	}, log)
+	require.NoError(t, err)
+
+	err = sesAdapter.SendEmail(context.Background(), dto.Email{
+		From:     "support@example.com",
+		ReplyTo:  "tickets@example.com",
+		To:       []string{"alice@example.com"},
+		Subject:  "Your ticket",
+		Body:     "We are on it",
+		Headers:  map[string]string{"X-Ticket-ID": "T-1"},
+		Tags:     []string{"support"},
+		Metadata: map[string]string{"user_id": "42"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "support@example.com", received["FromEmailAddress"])
+	assert.Equal(t, []any{"tickets@example.com"}, received["ReplyToAddresses"])
+	assert.Equal(t, []any{
+		map[string]any{"Name": "app", "Value": "send-sen"},
+		map[string]any{"Name": "support", "Value": "true"},
+		map[string]any{"Name": "user_id", "Value": "42"},
+	}, received["EmailTags"])
+	simple := received["Content"].(map[string]any)["Simple"].(map[string]any)
+	assert.Equal(t, []any{map[string]any{"Name": "X-Ticket-ID", "Value": "T-1"}}, simple["Headers"])
+}
+
+func TestSESAdapter_SanitizesTags(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		_, _ = w.Write([]byte(`{"MessageId":"0100018c-test"}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	cfg := config.SESConfig{
+		Region:          "eu-west-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "test-secret",
+		FromEmail:       "noreply@example.com",
+		Endpoint:        server.URL,
+	}
+
+	// Configured tags are checked up front.
+	cfg.Tags = []config.SESTag{{Name: "team name", Value: "billing"}}
+	_, err = email.NewSESAdapter(cfg, log)
+	assert.ErrorContains(t, err, "invalid SES tag")
+	cfg.Tags = []config.SESTag{{Name: "team", Value: "billing"}, {Name: "team", Value: "growth"}}
+	_, err = email.NewSESAdapter(cfg, log)
+	assert.ErrorContains(t, err, "duplicate SES tag")
+
+	cfg.Tags = nil
+	sesAdapter, err := email.NewSESAdapter(cfg, log)
+	require.NoError(t, err)
+	err = sesAdapter.SendEmail(context.Background(), dto.Email{
+		To:       []string{"alice@example.com"},
+		Subject:  "Welcome",
+		Body:     "Hello",
+		Tags:     []string{"welcome email", "café", "?"},
+		Metadata: map[string]string{"user.email": "alice@example.com", strings.Repeat("k", 300): "v"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{
+		map[string]any{"Name": "_", "Value": "true"},
+		map[string]any{"Name": "caf_", "Value": "true"},
+		map[string]any{"Name": strings.Repeat("k", 256), "Value": "v"},
+		map[string]any{"Name": "user_email", "Value": "alice_example_com"},
+		map[string]any{"Name": "welcome_email", "Value": "true"},
+	}, received["EmailTags"])
+}
+
+func TestSESConfig_TagNamesKeepTheirCase(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(`
+ses:
+    tags:
+        - name: 'AppName'
+          value: 'SendSen'
+`), 0o600))
+
+	cfg, err := config.LoadConfig(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []config.SESTag{{Name: "AppName", Value: "SendSen"}}, cfg.SES.Tags)
+}
+
+func TestSESAdapter_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Amzn-ErrorType", "MessageRejected:http://internal.amazon.com/coral/com.amazonaws.sesv2/")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message":"Email address is not verified."}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	_, err = email.NewSESAdapter(config.SESConfig{Region: "us-east-1", FromEmail: "noreply@example.com"}, log)
+	assert.Error(t, err)
+
+	sesAdapter, err := email.NewSESAdapter(config.SESConfig{
+		Region:          "us-east-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "test-secret",
+		FromEmail:       "noreply@example.com",
+		Endpoint:        server.URL,
+	}, log)
+	require.NoError(t, err)
+
+	err = sesAdapter.SendEmail(context.Background(), dto.Email{
+		To:      []string{"alice@example.com"},
+		Subject: "Test Message",
+		Body:    "This is a test email",
+	})
+	assert.ErrorContains(t, err, "MessageRejected")
+	assert.ErrorContains(t, err, "Email address is not verified.")
+	assert.ErrorIs(t, err, sen.ErrRejected)
+}
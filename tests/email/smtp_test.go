@@ -0,0 +1,459 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/adapters/email"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpMessage is a message accepted by the in-process SMTP server.
+type smtpMessage struct {
+	From       string
+	Recipients []string
+	Data       string
+	Auth       string
+	TLS        bool
+}
+
+// smtpTestServer is a minimal in-process SMTP server supporting EHLO, STARTTLS,
+// AUTH PLAIN/LOGIN/CRAM-MD5, MAIL, RCPT, DATA, RSET, NOOP and QUIT.
+type smtpTestServer struct {
+	listener    net.Listener
+	tlsConfig   *tls.Config
+	startTLS    bool
+	username    string
+	password    string
+	mu          sync.Mutex
+	messages    []smtpMessage
+	connections int
+}
+
+func newSMTPTestServer(t *testing.T, implicitTLS, startTLS bool) *smtpTestServer {
+	t.Helper()
+	s := &smtpTestServer{
+		tlsConfig: &tls.Config{Certificates: []tls.Certificate{selfSignedCert(t)}},
+		startTLS:  startTLS,
+		username:  "user",
+		password:  "secret",
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	if implicitTLS {
+		listener = tls.NewListener(listener, s.tlsConfig)
+	}
+	s.listener = listener
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.connections++
+			s.mu.Unlock()
+			go s.serve(conn, implicitTLS)
+		}
+	}()
+	return s
+}
+
+func (s *smtpTestServer) config() config.SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return config.SMTPConfig{
+		Host:               host,
+		Port:               p,
+		Username:           s.username,
+		Password:           s.password,
+		InsecureSkipVerify: true,
+		FromEmail:          "noreply@example.com",
+		FromName:           "Send Sen",
+	}
+}
+
+func (s *smtpTestServer) Messages() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+func (s *smtpTestServer) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+func (s *smtpTestServer) serve(conn net.Conn, isTLS bool) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(format string, args ...any) {
+		fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+	readLine := func() (string, error) {
+		line, err := r.ReadString('\n')
+		return strings.TrimRight(line, "\r\n"), err
+	}
+
+	reply("220 localhost ESMTP test")
+	var current smtpMessage
+	var auth string
+	for {
+		line, err := readLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			if s.startTLS && !isTLS {
+				reply("250-STARTTLS")
+			}
+			reply("250 AUTH PLAIN LOGIN CRAM-MD5")
+		case "STARTTLS":
+			reply("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, r, isTLS = tlsConn, bufio.NewReader(tlsConn), true
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			var user, pass string
+			switch strings.ToUpper(mechanism) {
+			case "PLAIN":
+				decoded, _ := base64.StdEncoding.DecodeString(initial)
+				parts := strings.Split(string(decoded), "\x00")
+				if len(parts) == 3 {
+					user, pass = parts[1], parts[2]
+				}
+			case "LOGIN":
+				reply("334 %s", base64.StdEncoding.EncodeToString([]byte("Username:")))
+				line, _ := readLine()
+				decoded, _ := base64.StdEncoding.DecodeString(line)
+				user = string(decoded)
+				reply("334 %s", base64.StdEncoding.EncodeToString([]byte("Password:")))
+				line, _ = readLine()
+				decoded, _ = base64.StdEncoding.DecodeString(line)
+				pass = string(decoded)
+			case "CRAM-MD5":
+				challenge := "<12345@localhost>"
+				reply("334 %s", base64.StdEncoding.EncodeToString([]byte(challenge)))
+				line, _ := readLine()
+				decoded, _ := base64.StdEncoding.DecodeString(line)
+				user, pass, _ = strings.Cut(string(decoded), " ")
+				mac := hmac.New(md5.New, []byte(s.password))
+				mac.Write([]byte(challenge))
+				if pass == hex.EncodeToString(mac.Sum(nil)) {
+					pass = s.password
+				}
+			}
+			if user != s.username || pass != s.password {
+				reply("535 Authentication failed")
+				continue
+			}
+			auth = strings.ToUpper(mechanism)
+			reply("235 Authentication successful")
+		case "MAIL":
+			current = smtpMessage{From: strings.Trim(strings.TrimPrefix(strings.Fields(arg)[0], "FROM:"), "<>"), Auth: auth, TLS: isTLS}
+			reply("250 OK")
+		case "RCPT":
+			rcpt := strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>")
+			if strings.HasSuffix(rcpt, "@invalid.test") {
+				reply("550 No such user")
+				continue
+			}
+			current.Recipients = append(current.Recipients, rcpt)
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := readLine()
+				if err != nil {
+					return
+				}
+				if line == "." {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, ".") + "\r\n")
+			}
+			current.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case "RSET":
+			current = smtpMessage{}
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func selfSignedCert(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestSMTPAdapter_Multipart(t *testing.T) {
+	server := newSMTPTestServer(t, false, true)
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	cfg := server.config()
+	cfg.Encryption = "starttls"
+	smtpAdapter, err := email.NewSMTPAdapter(cfg, log)
+	require.NoError(t, err)
+	defer smtpAdapter.Close()
+
+	err = smtpAdapter.SendEmail(context.Background(), dto.Email{
+		To:      []string{"alice@example.com"},
+		Cc:      []string{"bob@example.com"},
+		Bcc:     []string{"carol@example.com"},
+		Subject: "Xin chào – Test",
+		Body:    "This is a test email",
+		Html:    "<p>This is a test email</p>",
+	})
+	require.NoError(t, err)
+
+	messages := server.Messages()
+	require.Len(t, messages, 1)
+	msg := messages[0]
+	assert.True(t, msg.TLS)
+	assert.Equal(t, "PLAIN", msg.Auth)
+	assert.Equal(t, "noreply@example.com", msg.From)
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com", "carol@example.com"}, msg.Recipients)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(msg.Data))
+	require.NoError(t, err)
+	assert.Empty(t, parsed.Header.Get("Bcc"))
+	assert.Equal(t, "<bob@example.com>", parsed.Header.Get("Cc"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Xin chào – Test", subject)
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	mr := multipart.NewReader(parsed.Body, params["boundary"])
+	var parts []string
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, _ := io.ReadAll(part)
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts = append(parts, partType+": "+string(content))
+	}
+	assert.Equal(t, []string{
+		"text/plain: This is a test email",
+		"text/html: <p>This is a test email</p>",
+	}, parts)
+}
+
+func TestSMTPAdapter_MessageFields(t *testing.T) {
+	server := newSMTPTestServer(t, false, false)
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	smtpAdapter, err := email.NewSMTPAdapter(server.config(), log)
+	require.NoError(t, err)
+	defer smtpAdapter.Close()
+
+	err = smtpAdapter.SendEmail(context.Background(), dto.Email{
+		From:    "Support <support@example.com>",
+		ReplyTo: "tickets@example.com",
+		To:      []string{"alice@example.com"},
+		Subject: "Your ticket",
+		Body:    "We are on it",
+		Headers: map[string]string{"X-Ticket-ID": "T-1", "X-Queue": "Hỗ trợ"},
+	})
+	require.NoError(t, err)
+
+	messages := server.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "support@example.com", messages[0].From)
+	parsed, err := mail.ReadMessage(strings.NewReader(messages[0].Data))
+	require.NoError(t, err)
+	assert.Equal(t, `"Support" <support@example.com>`, parsed.Header.Get("From"))
+	assert.Equal(t, "<tickets@example.com>", parsed.Header.Get("Reply-To"))
+	assert.Equal(t, "T-1", parsed.Header.Get("X-Ticket-ID"))
+	queue, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("X-Queue"))
+	require.NoError(t, err)
+	assert.Equal(t, "Hỗ trợ", queue)
+}
+
+func TestSMTPAdapter_AuthMethods(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		authMethod  string
+		encryption  string
+		implicitTLS bool
+		wantAuth    string
+	}{
+		{name: "plain over implicit TLS", authMethod: "plain", encryption: "tls", implicitTLS: true, wantAuth: "PLAIN"},
+		{name: "login over implicit TLS", authMethod: "login", encryption: "tls", implicitTLS: true, wantAuth: "LOGIN"},
+		{name: "cram-md5 without TLS", authMethod: "cram-md5", encryption: "none", wantAuth: "CRAM-MD5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newSMTPTestServer(t, tt.implicitTLS, false)
+			cfg := server.config()
+			cfg.AuthMethod = tt.authMethod
+			cfg.Encryption = tt.encryption
+			smtpAdapter, err := email.NewSMTPAdapter(cfg, log)
+			require.NoError(t, err)
+			defer smtpAdapter.Close()
+
+			err = smtpAdapter.SendEmail(context.Background(), dto.Email{
+				To:      []string{"alice@example.com"},
+				Subject: "Test Message",
+				Body:    "This is a test email",
+			})
+			require.NoError(t, err)
+			require.Len(t, server.Messages(), 1)
+			assert.Equal(t, tt.wantAuth, server.Messages()[0].Auth)
+			assert.Equal(t, tt.implicitTLS, server.Messages()[0].TLS)
+		})
+	}
+
+	// Wrong credentials are reported as an error
+	server := newSMTPTestServer(t, true, false)
+	cfg := server.config()
+	cfg.Encryption = "tls"
+	cfg.Password = "wrong"
+	smtpAdapter, err := email.NewSMTPAdapter(cfg, log)
+	require.NoError(t, err)
+	err = smtpAdapter.SendEmail(context.Background(), dto.Email{To: []string{"alice@example.com"}, Subject: "x", Body: "x"})
+	assert.ErrorContains(t, err, "AUTH")
+	assert.ErrorIs(t, err, sen.ErrAuthentication)
+
+	// Unknown auth methods are rejected at construction
+	cfg.AuthMethod = "xoauth2"
+	_, err = email.NewSMTPAdapter(cfg, log)
+	assert.Error(t, err)
+}
+
+func TestSMTPAdapter_ConnectionReuse(t *testing.T) {
+	server := newSMTPTestServer(t, true, false)
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	cfg := server.config()
+	cfg.Encryption = "tls"
+	smtpAdapter, err := email.NewSMTPAdapter(cfg, log)
+	require.NoError(t, err)
+	defer smtpAdapter.Close()
+
+	for i := 0; i < 5; i++ {
+		err = smtpAdapter.SendEmail(context.Background(), dto.Email{
+			To:      []string{"alice@example.com"},
+			Subject: fmt.Sprintf("Burst %d", i),
+			Html:    "<p>burst</p>",
+		})
+		require.NoError(t, err)
+	}
+	assert.Len(t, server.Messages(), 5)
+	assert.Equal(t, 1, server.Connections())
+
+	// A rejected recipient must not poison the pooled session for the next send
+	err = smtpAdapter.SendEmail(context.Background(), dto.Email{
+		To:      []string{"nobody@invalid.test"},
+		Subject: "Rejected",
+		Body:    "x",
+	})
+	assert.ErrorContains(t, err, "550")
+	assert.ErrorIs(t, err, sen.ErrInvalidRecipient)
+	err = smtpAdapter.SendEmail(context.Background(), dto.Email{
+		To:      []string{"alice@example.com"},
+		Subject: "After rejection",
+		Body:    "x",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, server.Messages(), 6)
+}
+
+func TestSMTPAdapter_PartialRejection(t *testing.T) {
+	server := newSMTPTestServer(t, false, false)
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	cfg := server.config()
+	cfg.Encryption = "none"
+	cfg.AuthMethod = "cram-md5"
+	smtpAdapter, err := email.NewSMTPAdapter(cfg, log)
+	require.NoError(t, err)
+	defer smtpAdapter.Close()
+
+	// Refused recipients are reported while the others still get the message
+	result, err := smtpAdapter.SendEmailWithResult(context.Background(), dto.Email{
+		To:      []string{"alice@example.com", "nobody@invalid.test"},
+		Subject: "Partial",
+		Body:    "x",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "smtp", result.Provider)
+	assert.Equal(t, []string{"alice@example.com"}, result.Accepted)
+	assert.Equal(t, []string{"nobody@invalid.test"}, result.Rejected)
+
+	messages := server.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, []string{"alice@example.com"}, messages[0].Recipients)
+	parsed, err := mail.ReadMessage(strings.NewReader(messages[0].Data))
+	require.NoError(t, err)
+	assert.Equal(t, parsed.Header.Get("Message-ID"), result.MessageID)
+}
@@ -0,0 +1,109 @@
+package email
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/adapters/email"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSlowServer returns a provider stub that never answers; requests end when
+// the client gives up.
+func newSlowServer(t *testing.T) *httptest.Server {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(func() {
+		close(release)
+		server.Close()
+	})
+	return server
+}
+
+// newSilentSMTPServer returns the address of a server that accepts connections
+// but never sends its greeting.
+func newSilentSMTPServer(t *testing.T) (string, int) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { _ = conn.Close() })
+		}
+	}()
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return host, p
+}
+
+func TestEmailAdapters_Cancellation(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	adapters := []struct {
+		name string
+		new  func(t *testing.T, timeout time.Duration) (sen.EmailAdapter, error)
+	}{
+		{"sendgrid", func(t *testing.T, timeout time.Duration) (sen.EmailAdapter, error) {
+			return email.NewSendGridAdapter(config.SendGridConfig{APIKey: "SG.test", FromEmail: "noreply@example.com", BaseURL: newSlowServer(t).URL, Timeout: timeout}, log)
+		}},
+		{"brevo", func(t *testing.T, timeout time.Duration) (sen.EmailAdapter, error) {
+			return email.NewBrevoAdapter(config.BrevoConfig{APIKey: "xkeysib-test", SenderEmail: "noreply@example.com", BaseURL: newSlowServer(t).URL, Timeout: timeout}, log)
+		}},
+		{"mailgun", func(t *testing.T, timeout time.Duration) (sen.EmailAdapter, error) {
+			return email.NewMailgunAdapter(config.MailgunConfig{APIKey: "key-test", Domain: "mg.example.com", FromEmail: "noreply@mg.example.com", BaseURL: newSlowServer(t).URL, Timeout: timeout}, log)
+		}},
+		{"ses", func(t *testing.T, timeout time.Duration) (sen.EmailAdapter, error) {
+			return email.NewSESAdapter(config.SESConfig{Region: "eu-west-1", AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "test-secret", FromEmail: "noreply@example.com", Endpoint: newSlowServer(t).URL, Timeout: timeout}, log)
+		}},
+		{"smtp", func(t *testing.T, timeout time.Duration) (sen.EmailAdapter, error) {
+			host, port := newSilentSMTPServer(t)
+			return email.NewSMTPAdapter(config.SMTPConfig{Host: host, Port: port, Encryption: "none", AuthMethod: "none", FromEmail: "noreply@example.com", Timeout: timeout}, log)
+		}},
+	}
+	message := dto.Email{To: []string{"alice@example.com"}, Subject: "Slow", Body: "Hello"}
+
+	for _, a := range adapters {
+		t.Run(a.name+"/cancelled", func(t *testing.T) {
+			adapter, err := a.new(t, time.Minute)
+			require.NoError(t, err)
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+
+			start := time.Now()
+			err = adapter.SendEmail(ctx, message)
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Less(t, time.Since(start), 5*time.Second)
+		})
+		t.Run(a.name+"/timeout", func(t *testing.T) {
+			adapter, err := a.new(t, 50*time.Millisecond)
+			require.NoError(t, err)
+
+			start := time.Now()
+			err = adapter.SendEmail(context.Background(), message)
+			// A provider timeout is transient, unlike the caller's own deadline.
+			assert.ErrorIs(t, err, sen.ErrTransient)
+			assert.NotErrorIs(t, err, context.DeadlineExceeded)
+			assert.Less(t, time.Since(start), 5*time.Second)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package mailhtml_test
+
+import (
+	"testing"
+
+	"github.com/lugondev/send-sen/mailhtml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToText(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "document with headings, links and footnotes",
+			html: `<html><head><title>Hi</title><style>p{color:red}</style></head><body>
+				<h1>Welcome, Alice</h1>
+				<p>Thanks for joining <b>Acme</b>. Please <a href="https://acme.test/verify?t=1">verify your email</a>
+				or visit <a href="https://acme.test">https://acme.test</a>.</p>
+				<h3>Next steps</h3>
+				<p>Again: <a href="https://acme.test/verify?t=1">verify</a>, or write to <a href="mailto:help@acme.test">help@acme.test</a>.</p>
+				<script>alert(1)</script>
+			</body></html>`,
+			want: "Welcome, Alice\n==============\n\n" +
+				"Thanks for joining Acme. Please verify your email [1] or visit https://acme.test.\n\n" +
+				"Next steps\n\n" +
+				"Again: verify [1], or write to help@acme.test.\n\n" +
+				"[1] https://acme.test/verify?t=1",
+		},
+		{
+			name: "nested lists",
+			html: `<ol><li>Set up your profile</li><li>Invite your team<ul><li>Admins</li><li>Members</li></ul></li></ol><p>Done</p>`,
+			want: "1. Set up your profile\n2. Invite your team\n   * Admins\n   * Members\n\nDone",
+		},
+		{
+			name: "tables are flattened",
+			html: `<p>Your invoice</p><table><thead><tr><th>Item</th><th>Price</th></tr></thead><tbody><tr><td>Pro plan</td><td>$10</td></tr></tbody></table>`,
+			want: "Your invoice\n\nItem | Price\nPro plan | $10",
+		},
+		{
+			name: "line breaks, quotes, rules and preformatted text",
+			html: `<p>Line one<br>Line two<br><br>After blank</p><blockquote><p>Great tool!</p><p>A fan</p></blockquote><hr><pre>code  block
+  indented</pre>`,
+			want: "Line one\nLine two\n\nAfter blank\n\n> Great tool!\n>\n> A fan\n\n----------\n\ncode  block\n  indented",
+		},
+		{
+			name: "images and entities",
+			html: `<div><img src="cid:logo" alt="Acme logo"></div><div>Tom &amp; Jerry&nbsp;&lt;3</div><a href="https://acme.test/x"><img src="x.png"></a>`,
+			want: "Acme logo\nTom & Jerry <3\nhttps://acme.test/x",
+		},
+		{
+			name: "plain text",
+			html: "Just   some\ntext",
+			want: "Just some text",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mailhtml.ToText(tt.html))
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		want     string
+	}{
+		{
+			name:     "keeps formatting",
+			fragment: `<p>Hello <b>Alice</b>, <em>welcome</em></p><ul><li>one</li></ul>`,
+			want:     `<p>Hello <b>Alice</b>, <em>welcome</em></p><ul><li>one</li></ul>`,
+		},
+		{
+			name:     "drops scripts, styles and event handlers",
+			fragment: `<p style="color:red" onclick="steal()">Hi<script>alert(1)</script><style>p{}</style></p>`,
+			want:     `<p>Hi</p>`,
+		},
+		{
+			name:     "filters URL schemes",
+			fragment: `<a href="javascript:alert(1)">bad</a> <a href="https://acme.test" target="_blank">ok</a> <img src="data:image/png;base64,AAAA" alt="x"><img src="cid:logo">`,
+			want:     `<a>bad</a> <a href="https://acme.test">ok</a> <img alt="x"><img src="cid:logo">`,
+		},
+		{
+			name:     "unwraps unknown elements and escapes text",
+			fragment: `<font color="red">Tom &amp; <marquee>Jerry</marquee></font> 1 &lt; 2<!-- note --><iframe src="https://evil.test">x</iframe>`,
+			want:     `Tom &amp; Jerry 1 &lt; 2`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mailhtml.Sanitize(tt.fragment))
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/adapters/notify"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscordAdapter_Send(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("wait"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1234567890"}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	assert.NoError(t, err)
+
+	discordAdapter, err := notify.NewDiscordAdapter(config.DiscordConfig{
+		WebhookURL: server.URL + "/api/webhooks/1/token",
+		Username:   "send-sen",
+	}, log)
+	assert.NoError(t, err)
+
+	result, err := discordAdapter.SendWithResult(context.Background(), dto.Content{
+		Subject: "Error Alert",
+		Message: "This is a test error message",
+		Level:   dto.Error,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "discord", result.Provider)
+	assert.Equal(t, "1234567890", result.MessageID)
+
+	assert.Equal(t, "send-sen", received["username"])
+	embed := received["embeds"].([]any)[0].(map[string]any)
+	assert.Equal(t, "Error Alert", embed["title"])
+	assert.Equal(t, "This is a test error message", embed["description"])
+	assert.Equal(t, float64(0xF44336), embed["color"])
+}
+
+func TestDiscordAdapter_RateLimited(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"message":"You are being rate limited.","retry_after":0.01,"global":false}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	assert.NoError(t, err)
+
+	discordAdapter, err := notify.NewDiscordAdapter(config.DiscordConfig{WebhookURL: server.URL}, log)
+	assert.NoError(t, err)
+
+	// The adapter does not wait on its own; the wait is left to the retry decorator.
+	err = discordAdapter.Send(context.Background(), dto.Content{Message: "retry me", Level: dto.Info})
+	assert.ErrorIs(t, err, sen.ErrRateLimited)
+	retryAfter, ok := sen.RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Millisecond, retryAfter)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDiscordAdapter_RateLimitedRetriedByDecorator(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message":"You are being rate limited.","retry_after":0.01,"global":false}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1"}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	assert.NoError(t, err)
+	discordAdapter, err := notify.NewDiscordAdapter(config.DiscordConfig{WebhookURL: server.URL}, log)
+	assert.NoError(t, err)
+	retry := sen.NewNotifyRetry(discordAdapter, config.RetryConfig{MaxAttempts: 2, InitialBackoff: time.Millisecond}, log)
+
+	assert.NoError(t, retry.Send(context.Background(), dto.Content{Message: "retry me", Level: dto.Info}))
+	assert.Equal(t, 2, calls)
+}
+
+func TestDiscordAdapter_RateLimitedRetryAfterHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	assert.NoError(t, err)
+
+	discordAdapter, err := notify.NewDiscordAdapter(config.DiscordConfig{WebhookURL: server.URL}, log)
+	assert.NoError(t, err)
+
+	start := time.Now()
+	err = discordAdapter.Send(context.Background(), dto.Content{Message: "too fast"})
+	assert.ErrorIs(t, err, sen.ErrRateLimited)
+	retryAfter, _ := sen.RetryAfter(err)
+	assert.Equal(t, 30*time.Second, retryAfter)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,55 @@
+package notify_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	logger "github.com/lugondev/go-log"
+	"github.com/lugondev/send-sen/adapters/notify"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// markingTransport tags every request so the stub can tell the injected client was used.
+type markingTransport struct{}
+
+func (markingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("X-Injected-Client", "yes")
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestTelegramAdapter_HTTPClientAndBaseURL(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "yes", r.Header.Get("X-Injected-Client"))
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/bot123:test/getMe":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"Send Sen","username":"send_sen_bot"}}`))
+		case "/bot123:test/sendMessage":
+			assert.NoError(t, r.ParseForm())
+			assert.Equal(t, "42", r.PostForm.Get("chat_id"))
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":7,"date":0,"chat":{"id":42,"type":"private"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	telegramAdapter, err := notify.NewTelegramAdapter(config.TelegramConfig{BotToken: "123:test", ChatID: "42"}, log,
+		notify.WithHTTPClient(&http.Client{Transport: markingTransport{}}),
+		notify.WithBaseURL(server.URL),
+	)
+	require.NoError(t, err)
+
+	err = telegramAdapter.Send(context.Background(), dto.Content{Message: "Hello", Level: dto.Info})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/bot123:test/getMe", "/bot123:test/sendMessage"}, paths)
+}
@@ -0,0 +1,120 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/adapters/notify"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlackAdapter_Webhook(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Empty(t, r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	assert.NoError(t, err)
+
+	slackAdapter, err := notify.NewSlackAdapter(config.SlackConfig{WebhookURL: server.URL}, log)
+	assert.NoError(t, err)
+
+	err = slackAdapter.Send(context.Background(), dto.Content{
+		Subject: "Error Alert",
+		Message: "disk usage > 90% & rising",
+		Level:   dto.Error,
+	})
+	assert.NoError(t, err)
+
+	attachments := received["attachments"].([]any)
+	assert.Len(t, attachments, 1)
+	attachment := attachments[0].(map[string]any)
+	assert.Equal(t, "#F44336", attachment["color"])
+	assert.Equal(t, "Error Alert", attachment["title"])
+	assert.Equal(t, "disk usage &gt; 90% &amp; rising", attachment["text"])
+	assert.Nil(t, received["channel"])
+}
+
+func TestSlackAdapter_BotToken(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat.postMessage", r.URL.Path)
+		assert.Equal(t, "Bearer xoxb-test", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"channel":"C123","ts":"1712345678.000100"}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	assert.NoError(t, err)
+
+	slackAdapter, err := notify.NewSlackAdapter(config.SlackConfig{
+		BotToken:  "xoxb-test",
+		ChannelID: "C123",
+		APIURL:    server.URL,
+	}, log)
+	assert.NoError(t, err)
+
+	result, err := slackAdapter.SendWithResult(context.Background(), dto.Content{
+		Subject: "Warning Notice",
+		Message: "This is a test warning message",
+		Level:   dto.Warning,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1712345678.000100", result.MessageID)
+	assert.Equal(t, []string{"C123"}, result.Accepted)
+	assert.Equal(t, "C123", received["channel"])
+	assert.Equal(t, "#FF9800", received["attachments"].([]any)[0].(map[string]any)["color"])
+}
+
+func TestSlackAdapter_Errors(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	assert.NoError(t, err)
+
+	_, err = notify.NewSlackAdapter(config.SlackConfig{}, log)
+	assert.Error(t, err)
+	_, err = notify.NewSlackAdapter(config.SlackConfig{BotToken: "xoxb-test"}, log)
+	assert.Error(t, err)
+
+	// chat.postMessage reports failures with HTTP 200 and ok=false
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+	}))
+	defer apiServer.Close()
+
+	slackAdapter, err := notify.NewSlackAdapter(config.SlackConfig{
+		BotToken:  "xoxb-test",
+		ChannelID: "C404",
+		APIURL:    apiServer.URL,
+	}, log)
+	assert.NoError(t, err)
+	err = slackAdapter.Send(context.Background(), dto.Content{Message: "hello"})
+	assert.ErrorContains(t, err, "channel_not_found")
+	assert.ErrorIs(t, err, sen.ErrInvalidRecipient)
+
+	// Webhooks report failures with a non-200 status and a plain-text body
+	webhookServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("no_service"))
+	}))
+	defer webhookServer.Close()
+
+	slackAdapter, err = notify.NewSlackAdapter(config.SlackConfig{WebhookURL: webhookServer.URL}, log)
+	assert.NoError(t, err)
+	err = slackAdapter.Send(context.Background(), dto.Content{Message: "hello"})
+	assert.ErrorContains(t, err, "no_service")
+	assert.ErrorIs(t, err, sen.ErrInvalidRecipient)
+}
@@ -0,0 +1,75 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamsNotifyService_Alert(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	assert.NoError(t, err)
+
+	cfg := config.Config{
+		Adapter: config.AdapterConfig{Notify: config.NotifyTeams},
+		Teams:   config.TeamsConfig{WebhookURL: server.URL},
+	}
+	notifyService, err := sen.NewNotifyService(cfg, log)
+	assert.NoError(t, err)
+	assert.Equal(t, "teams", notifyService.ServiceName())
+
+	err = notifyService.Alert(context.Background(), "Error Alert", "Payment worker crashed")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "message", received["type"])
+	attachment := received["attachments"].([]any)[0].(map[string]any)
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", attachment["contentType"])
+
+	card := attachment["content"].(map[string]any)
+	assert.Equal(t, "AdaptiveCard", card["type"])
+	body := card["body"].([]any)
+	header := body[0].(map[string]any)
+	assert.Equal(t, "attention", header["style"])
+	assert.Equal(t, "Error Alert", header["items"].([]any)[0].(map[string]any)["text"])
+	assert.Equal(t, "Payment worker crashed", body[1].(map[string]any)["text"])
+}
+
+func TestTeamsNotifyService_WebhookError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("Webhook message delivery failed"))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	assert.NoError(t, err)
+
+	cfg := config.Config{
+		Adapter: config.AdapterConfig{Notify: config.NotifyTeams},
+		Teams:   config.TeamsConfig{WebhookURL: server.URL},
+	}
+	notifyService, err := sen.NewNotifyService(cfg, log)
+	assert.NoError(t, err)
+
+	err = notifyService.Info(context.Background(), "Deploy", "v1.2.3 is live")
+	assert.ErrorContains(t, err, "status 400")
+
+	// Missing webhook URL must fail construction rather than fall back to the mock
+	_, err = sen.NewNotifyService(config.Config{Adapter: config.AdapterConfig{Notify: config.NotifyTeams}}, log)
+	assert.Error(t, err)
+}
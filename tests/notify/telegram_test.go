@@ -3,34 +3,29 @@ package notify_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
 	"github.com/lugondev/send-sen/adapters/notify"
-	"github.com/lugondev/send-sen/config"
 	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/testkit"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestTelegramSendNotification_RealConfig(t *testing.T) {
-	t.Log("Testing Telegram notification with real config...")
-
-	// Skip if running in CI/CD
-	if testing.Short() {
-		t.Skip("Skipping integration test in short mode")
-	}
-
-	cfg, err := config.LoadConfig("../../config")
-	assert.NoError(t, err)
+func TestTelegramSendNotification(t *testing.T) {
+	fake := testkit.NewTelegram(t)
 
 	mockLogger, err := logger.NewLogger(&logger.Option{
-		Format:       cfg.Log.Format,
+		Format:       "console",
 		ScopeName:    "send-sen",
 		ScopeVersion: "v0.1.1",
 	})
-	assert.NoError(t, err)
+	require.NoError(t, err)
 
-	telegramAdapter, err := notify.NewTelegramAdapter(cfg.Telegram, mockLogger)
-	assert.NoError(t, err)
+	telegramAdapter, err := notify.NewTelegramAdapter(fake.Config(), mockLogger)
+	require.NoError(t, err)
 
 	// Test with explicit recipient
 	err = telegramAdapter.Send(context.Background(), dto.Content{
@@ -55,4 +50,37 @@ func TestTelegramSendNotification_RealConfig(t *testing.T) {
 		Level:   dto.Warning,
 	})
 	assert.NoError(t, err)
+
+	messages := fake.Messages()
+	require.Len(t, messages, 3)
+	assert.Equal(t, "42", messages[0].Get("chat_id"))
+	assert.Contains(t, messages[0].Get("text"), "Test message with subject from automated test")
+	assert.Equal(t, "HTML", messages[1].Get("parse_mode"))
+	assert.Contains(t, messages[2].Get("text"), "This is a test warning message")
+}
+
+func TestTelegramAdapter_FakeFaults(t *testing.T) {
+	fake := testkit.NewTelegram(t)
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	telegramAdapter, err := notify.NewTelegramAdapter(fake.Config(), log)
+	require.NoError(t, err)
+	message := dto.Content{Message: "Hello", Level: dto.Info}
+
+	fake.FailNext(testkit.RateLimited(3 * time.Second))
+	err = telegramAdapter.Send(context.Background(), message)
+	assert.ErrorIs(t, err, sen.ErrRateLimited)
+	var providerErr *sen.ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, 3*time.Second, providerErr.RetryDelay)
+
+	config := fake.Config()
+	config.ChatID = "7"
+	otherChat, err := notify.NewTelegramAdapter(config, log)
+	require.NoError(t, err)
+	assert.ErrorIs(t, otherChat.Send(context.Background(), message), sen.ErrInvalidRecipient)
+
+	config.BotToken = "123456:wrong"
+	_, err = notify.NewTelegramAdapter(config, log)
+	assert.Error(t, err)
 }
@@ -0,0 +1,89 @@
+package notify_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/adapters/notify"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSlowServer returns a provider stub that never answers; requests end when
+// the client gives up. The Telegram getMe call made by NewTelegramAdapter is
+// answered right away.
+func newSlowServer(t *testing.T) *httptest.Server {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/getMe") {
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"Send Sen","username":"send_sen_bot"}}`))
+			return
+		}
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(func() {
+		close(release)
+		server.Close()
+	})
+	return server
+}
+
+func TestNotifyAdapters_Cancellation(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	adapters := []struct {
+		name string
+		new  func(t *testing.T, timeout time.Duration) (sen.NotifyAdapter, error)
+	}{
+		{"telegram", func(t *testing.T, timeout time.Duration) (sen.NotifyAdapter, error) {
+			return notify.NewTelegramAdapter(config.TelegramConfig{BotToken: "123:test", ChatID: "42", BaseURL: newSlowServer(t).URL, Timeout: timeout}, log)
+		}},
+		{"slack", func(t *testing.T, timeout time.Duration) (sen.NotifyAdapter, error) {
+			return notify.NewSlackAdapter(config.SlackConfig{WebhookURL: newSlowServer(t).URL, Timeout: timeout}, log)
+		}},
+		{"discord", func(t *testing.T, timeout time.Duration) (sen.NotifyAdapter, error) {
+			return notify.NewDiscordAdapter(config.DiscordConfig{WebhookURL: newSlowServer(t).URL, Timeout: timeout}, log)
+		}},
+		{"teams", func(t *testing.T, timeout time.Duration) (sen.NotifyAdapter, error) {
+			return notify.NewTeamsAdapter(config.TeamsConfig{WebhookURL: newSlowServer(t).URL, Timeout: timeout}, log)
+		}},
+	}
+	message := dto.Content{Subject: "Slow", Message: "Hello", Level: dto.Info}
+
+	for _, a := range adapters {
+		t.Run(a.name+"/cancelled", func(t *testing.T) {
+			notifyAdapter, err := a.new(t, time.Minute)
+			require.NoError(t, err)
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+
+			start := time.Now()
+			err = notifyAdapter.Send(ctx, message)
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Less(t, time.Since(start), 5*time.Second)
+		})
+		t.Run(a.name+"/timeout", func(t *testing.T) {
+			notifyAdapter, err := a.new(t, 50*time.Millisecond)
+			require.NoError(t, err)
+
+			start := time.Now()
+			err = notifyAdapter.Send(context.Background(), message)
+			// A provider timeout is transient, unlike the caller's own deadline.
+			assert.ErrorIs(t, err, sen.ErrTransient)
+			assert.NotErrorIs(t, err, context.DeadlineExceeded)
+			assert.Less(t, time.Since(start), 5*time.Second)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package otp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/otp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSMSService captures the last code sent and optionally fails delivery.
+type stubSMSService struct {
+	code string
+	ttl  time.Duration
+	err  error
+}
+
+func (s *stubSMSService) Send(context.Context, dto.SMS) error { return nil }
+func (s *stubSMSService) SendWithResult(context.Context, dto.SMS) (dto.SendResult, error) {
+	return dto.SendResult{}, nil
+}
+func (s *stubSMSService) SendCode(ctx context.Context, to, code string) error {
+	return s.SendCodeWithTTL(ctx, to, code, 10*time.Minute)
+}
+func (s *stubSMSService) SendCodeWithTTL(_ context.Context, _ string, code string, ttl time.Duration) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.code, s.ttl = code, ttl
+	return nil
+}
+func (s *stubSMSService) ServiceName() string                        { return "stub" }
+func (s *stubSMSService) CircuitStates() map[string]sen.CircuitState { return nil }
+
+func newManager(t *testing.T, cfg config.OTPConfig, sms *stubSMSService) *otp.Manager {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	manager, err := otp.NewManager(cfg, otp.NewMemoryStore(), sms, nil, log)
+	require.NoError(t, err)
+	return manager
+}
+
+func TestManager_SendAndVerify(t *testing.T) {
+	sms := &stubSMSService{}
+	manager := newManager(t, config.OTPConfig{TTL: 5 * time.Minute}, sms)
+	ctx := context.Background()
+
+	require.NoError(t, manager.Send(ctx, otp.ChannelSMS, "+15550001"))
+	assert.Regexp(t, `^[0-9]{6}$`, sms.code)
+	assert.Equal(t, 5*time.Minute, sms.ttl)
+
+	assert.ErrorIs(t, manager.Verify(ctx, otp.ChannelSMS, "+15550002", sms.code), otp.ErrNoCode)
+	require.NoError(t, manager.Verify(ctx, otp.ChannelSMS, "+15550001", " "+sms.code+" "))
+	// Codes are single use.
+	assert.ErrorIs(t, manager.Verify(ctx, otp.ChannelSMS, "+15550001", sms.code), otp.ErrNoCode)
+}
+
+func TestManager_AlphanumericCodes(t *testing.T) {
+	sms := &stubSMSService{}
+	manager := newManager(t, config.OTPConfig{Length: 8, Charset: config.OTPCharsetAlphanumeric}, sms)
+
+	require.NoError(t, manager.Send(context.Background(), otp.ChannelSMS, "+15550001"))
+	assert.Regexp(t, `^[2-9A-HJ-NP-Z]{8}$`, sms.code)
+	assert.NoError(t, manager.Verify(context.Background(), otp.ChannelSMS, "+15550001", sms.code))
+}
+
+func TestManager_AttemptLimit(t *testing.T) {
+	sms := &stubSMSService{}
+	manager := newManager(t, config.OTPConfig{MaxAttempts: 2}, sms)
+	ctx := context.Background()
+	require.NoError(t, manager.Send(ctx, otp.ChannelSMS, "+15550001"))
+
+	wrong := "000000"
+	if sms.code == wrong {
+		wrong = "111111"
+	}
+	assert.ErrorIs(t, manager.Verify(ctx, otp.ChannelSMS, "+15550001", wrong), otp.ErrInvalidCode)
+	assert.ErrorIs(t, manager.Verify(ctx, otp.ChannelSMS, "+15550001", wrong), otp.ErrTooManyAttempts)
+	// Locked: even the right code is refused now.
+	assert.ErrorIs(t, manager.Verify(ctx, otp.ChannelSMS, "+15550001", sms.code), otp.ErrTooManyAttempts)
+}
+
+func TestManager_ExpiryAndCooldown(t *testing.T) {
+	sms := &stubSMSService{}
+	manager := newManager(t, config.OTPConfig{TTL: 20 * time.Millisecond, ResendCooldown: 50 * time.Millisecond}, sms)
+	ctx := context.Background()
+
+	require.NoError(t, manager.Send(ctx, otp.ChannelSMS, "+15550001"))
+	assert.ErrorIs(t, manager.Send(ctx, otp.ChannelSMS, "+15550001"), otp.ErrCooldown)
+	// The cooldown is per recipient.
+	require.NoError(t, manager.Send(ctx, otp.ChannelSMS, "+15550002"))
+
+	time.Sleep(30 * time.Millisecond)
+	assert.ErrorIs(t, manager.Verify(ctx, otp.ChannelSMS, "+15550002", sms.code), otp.ErrExpired)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.NoError(t, manager.Send(ctx, otp.ChannelSMS, "+15550001"))
+}
+
+func TestManager_FailedDeliveryDoesNotStartCooldown(t *testing.T) {
+	sms := &stubSMSService{err: errors.New("provider down")}
+	manager := newManager(t, config.OTPConfig{}, sms)
+	ctx := context.Background()
+
+	assert.ErrorContains(t, manager.Send(ctx, otp.ChannelSMS, "+15550001"), "provider down")
+	sms.err = nil
+	require.NoError(t, manager.Send(ctx, otp.ChannelSMS, "+15550001"))
+	assert.ErrorContains(t, manager.Send(ctx, otp.ChannelEmail, "alice@example.com"), "not configured")
+}
+
+func TestMemoryStore_PurgesExpiredRecords(t *testing.T) {
+	store := otp.NewMemoryStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, store.Put(ctx, "sms:+15550001", otp.Record{SentAt: now, ExpiresAt: now.Add(10 * time.Millisecond)}))
+	require.NoError(t, store.Put(ctx, "sms:+15550002", otp.Record{SentAt: now, ExpiresAt: now.Add(time.Hour)}))
+	time.Sleep(20 * time.Millisecond)
+
+	// Codes that are never verified are dropped once they expire.
+	require.NoError(t, store.Put(ctx, "sms:+15550003", otp.Record{SentAt: now, ExpiresAt: now.Add(time.Hour)}))
+	_, found, err := store.Get(ctx, "sms:+15550001")
+	require.NoError(t, err)
+	assert.False(t, found)
+	_, found, err = store.Get(ctx, "sms:+15550002")
+	require.NoError(t, err)
+	assert.True(t, found)
+}
@@ -0,0 +1,344 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSMS records delivered messages and fails with the queued errors first.
+type stubSMS struct {
+	mu   sync.Mutex
+	errs []error
+	sent []dto.SMS
+}
+
+func (s *stubSMS) SendWithResult(_ context.Context, sms dto.SMS) (dto.SendResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return dto.SendResult{}, err
+	}
+	s.sent = append(s.sent, sms)
+	return dto.SendResult{Provider: "stub", MessageID: sms.To}, nil
+}
+
+func (s *stubSMS) Send(ctx context.Context, sms dto.SMS) error {
+	_, err := s.SendWithResult(ctx, sms)
+	return err
+}
+
+func (s *stubSMS) delivered() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sent)
+}
+
+var fastOutbox = config.OutboxConfig{
+	Workers:        2,
+	PollInterval:   5 * time.Millisecond,
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+}
+
+func newLogger(t *testing.T) logger.Logger {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	return log
+}
+
+func TestOutbox_DeliversAndDrainsOnShutdown(t *testing.T) {
+	stub := &stubSMS{}
+	box, err := outbox.New(fastOutbox, outbox.NewMemoryStore(), outbox.Senders{SMS: stub}, newLogger(t))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	var ids []string
+	for _, to := range []string{"+15550001", "+15550002", "+15550003"} {
+		id, err := box.EnqueueSMS(ctx, dto.SMS{To: to, Message: "hello"})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	require.NoError(t, box.Start())
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	require.NoError(t, box.Shutdown(shutdownCtx))
+
+	assert.Equal(t, 3, stub.delivered())
+	for _, id := range ids {
+		_, err := box.Get(ctx, id)
+		assert.ErrorIs(t, err, outbox.ErrNotFound)
+	}
+}
+
+func TestOutbox_RetriesTransientFailures(t *testing.T) {
+	stub := &stubSMS{errs: []error{&sen.ProviderError{Provider: "stub", Kind: sen.ErrTransient}, &sen.ProviderError{Provider: "stub", Kind: sen.ErrRateLimited}}}
+	box, err := outbox.New(fastOutbox, outbox.NewMemoryStore(), outbox.Senders{SMS: stub}, newLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, box.Start())
+	defer box.Shutdown(context.Background())
+
+	id, err := box.EnqueueSMS(context.Background(), dto.SMS{To: "+15550001", Message: "hello"})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return stub.delivered() == 1 }, 2*time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		_, err := box.Get(context.Background(), id)
+		return errors.Is(err, outbox.ErrNotFound)
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestOutbox_MarksPermanentFailures(t *testing.T) {
+	stub := &stubSMS{errs: []error{&sen.ProviderError{Provider: "stub", Kind: sen.ErrInvalidRecipient}}}
+	box, err := outbox.New(fastOutbox, outbox.NewMemoryStore(), outbox.Senders{SMS: stub}, newLogger(t))
+	require.NoError(t, err)
+
+	id, err := box.EnqueueSMS(context.Background(), dto.SMS{To: "not-a-number", Message: "hello"})
+	require.NoError(t, err)
+	require.NoError(t, box.Start())
+	require.NoError(t, box.Shutdown(context.Background()))
+
+	msg, err := box.Get(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, outbox.StatusFailed, msg.Status)
+	assert.Equal(t, 1, msg.Attempts)
+	assert.Contains(t, msg.LastError, "invalid recipient")
+	assert.Zero(t, stub.delivered())
+}
+
+func TestOutbox_FileStoreSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// First run: enqueue without starting the workers, as if the process died.
+	store, err := outbox.NewFileStore(dir)
+	require.NoError(t, err)
+	box, err := outbox.New(fastOutbox, store, outbox.Senders{SMS: &stubSMS{}}, newLogger(t))
+	require.NoError(t, err)
+	id, err := box.EnqueueSMS(ctx, dto.SMS{To: "+15550001", Message: "hello"})
+	require.NoError(t, err)
+
+	// A message claimed by a worker that crashed is redelivered once its lease expires.
+	claimedID, err := box.EnqueueSMS(ctx, dto.SMS{To: "+15550002", Message: "hello"})
+	require.NoError(t, err)
+	claimed, err := store.Claim(ctx, time.Now(), 1, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, id, claimed[0].ID)
+	time.Sleep(20 * time.Millisecond)
+
+	// Second run on the same directory.
+	store, err = outbox.NewFileStore(dir)
+	require.NoError(t, err)
+	msg, err := store.Get(ctx, claimedID)
+	require.NoError(t, err)
+	assert.Equal(t, dto.SMS{To: "+15550002", Message: "hello"}, *msg.SMS)
+
+	stub := &stubSMS{}
+	box, err = outbox.New(fastOutbox, store, outbox.Senders{SMS: stub}, newLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, box.Start())
+	require.NoError(t, box.Shutdown(ctx))
+	assert.Equal(t, 2, stub.delivered())
+}
+
+func TestOutbox_RejectsKindsWithoutSender(t *testing.T) {
+	box, err := outbox.New(fastOutbox, outbox.NewMemoryStore(), outbox.Senders{SMS: &stubSMS{}}, newLogger(t))
+	require.NoError(t, err)
+	_, err = box.EnqueueEmail(context.Background(), dto.Email{To: []string{"alice@example.com"}})
+	assert.ErrorContains(t, err, "no email sender")
+
+	_, err = outbox.New(fastOutbox, outbox.NewMemoryStore(), outbox.Senders{}, newLogger(t))
+	assert.Error(t, err)
+}
+
+func TestOutbox_RejectsOversizedAttachmentsOnEnqueue(t *testing.T) {
+	ctx := context.Background()
+	emailService, err := sen.NewEmailService(config.Config{
+		Adapter:     config.AdapterConfig{Email: config.EmailMock},
+		Attachments: config.AttachmentConfig{MaxSize: 8},
+	}, newLogger(t))
+	require.NoError(t, err)
+	store := outbox.NewMemoryStore()
+	box, err := outbox.New(fastOutbox, store, outbox.Senders{Email: emailService}, newLogger(t))
+	require.NoError(t, err)
+
+	email := dto.Email{To: []string{"alice@example.com"}, Subject: "Report", Body: "Attached"}
+	email.Attachments = []dto.Attachment{{Filename: "report.csv", Reader: strings.NewReader("a,b,c\n1,2,3\n")}}
+	_, err = box.EnqueueEmail(ctx, email)
+	assert.ErrorIs(t, err, sen.ErrAttachmentTooLarge)
+	email.Attachments = []dto.Attachment{{Content: []byte("a,b")}}
+	_, err = box.EnqueueEmail(ctx, email)
+	assert.ErrorContains(t, err, "must have a filename")
+	claimed, err := store.Claim(ctx, time.Now(), 0, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	// Accepted attachments are stored read, with their content type set.
+	email.Attachments = []dto.Attachment{{Filename: "report.csv", Reader: strings.NewReader("a,b")}}
+	id, err := box.EnqueueEmail(ctx, email)
+	require.NoError(t, err)
+	msg, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a,b"), msg.Email.Attachments[0].Content)
+	assert.Equal(t, "text/csv; charset=utf-8", msg.Email.Attachments[0].ContentType)
+}
+
+func TestOutbox_FileStoreSetsAsideCorruptFiles(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	// Left behind by a crash of a previous run.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "truncated.json"), []byte(`{"id":"trunc`), 0o600))
+	store, err := outbox.NewFileStore(dir)
+	require.NoError(t, err)
+
+	stub := &stubSMS{}
+	box, err := outbox.New(fastOutbox, store, outbox.Senders{SMS: stub}, newLogger(t))
+	require.NoError(t, err)
+	_, err = box.EnqueueSMS(ctx, dto.SMS{To: "+15550001", Message: "hello"})
+	require.NoError(t, err)
+	require.NoError(t, box.Start())
+	require.NoError(t, box.Shutdown(ctx))
+
+	assert.Equal(t, 1, stub.delivered())
+	assert.NoFileExists(t, filepath.Join(dir, "truncated.json"))
+	assert.FileExists(t, filepath.Join(dir, "truncated.json.corrupt"))
+}
+
+func TestOutbox_FileStoreClaimReadsOnlyLeasedMessages(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	store, err := outbox.NewFileStore(dir)
+	require.NoError(t, err)
+	now := time.Now()
+	for i, id := range []string{"first", "second"} {
+		created := now.Add(time.Duration(i) * time.Millisecond)
+		require.NoError(t, store.Put(ctx, outbox.Message{
+			ID: id, Kind: outbox.KindSMS, SMS: &dto.SMS{To: "+15550001", Message: id},
+			Status: outbox.StatusPending, CreatedAt: created, NextAttempt: created,
+		}))
+	}
+	// Damaged behind the store's back: only noticed once it is claimed.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "second.json"), []byte(`{"id":`), 0o600))
+
+	claimed, err := store.Claim(ctx, now.Add(time.Second), 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, "first", claimed[0].ID)
+	assert.Equal(t, "first", claimed[0].SMS.Message)
+	assert.FileExists(t, filepath.Join(dir, "second.json"))
+
+	claimed, err = store.Claim(ctx, now.Add(time.Second), 1, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+	assert.FileExists(t, filepath.Join(dir, "second.json.corrupt"))
+}
+
+func TestOutbox_FileStoreKeepsFailedMessagesApart(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	store, err := outbox.NewFileStore(dir)
+	require.NoError(t, err)
+	stub := &stubSMS{errs: []error{&sen.ProviderError{Provider: "stub", Kind: sen.ErrInvalidRecipient}}}
+	box, err := outbox.New(fastOutbox, store, outbox.Senders{SMS: stub}, newLogger(t))
+	require.NoError(t, err)
+
+	id, err := box.EnqueueSMS(ctx, dto.SMS{To: "not-a-number", Message: "hello"})
+	require.NoError(t, err)
+	require.NoError(t, box.Start())
+	require.NoError(t, box.Shutdown(ctx))
+
+	assert.NoFileExists(t, filepath.Join(dir, id+".json"))
+	assert.FileExists(t, filepath.Join(dir, "failed", id+".json"))
+	msg, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, outbox.StatusFailed, msg.Status)
+
+	// Putting it back as pending moves it out of the failed directory.
+	msg.Status = outbox.StatusPending
+	require.NoError(t, store.Put(ctx, msg))
+	assert.NoFileExists(t, filepath.Join(dir, "failed", id+".json"))
+	claimed, err := store.Claim(ctx, time.Now(), 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, id, claimed[0].ID)
+}
+
+// slowSMS blocks its first send until the context ends, then delivers.
+type slowSMS struct {
+	stubSMS
+	mu       sync.Mutex
+	inFlight int
+	overlap  bool
+	calls    int
+}
+
+func (s *slowSMS) SendWithResult(ctx context.Context, sms dto.SMS) (dto.SendResult, error) {
+	s.mu.Lock()
+	s.calls++
+	first := s.calls == 1
+	s.inFlight++
+	s.overlap = s.overlap || s.inFlight > 1
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+	if first {
+		<-ctx.Done()
+		return dto.SendResult{}, ctx.Err()
+	}
+	return s.stubSMS.SendWithResult(ctx, sms)
+}
+
+func TestOutbox_SendsEndBeforeTheLease(t *testing.T) {
+	stub := &slowSMS{}
+	cfg := fastOutbox
+	cfg.Lease = 50 * time.Millisecond
+	box, err := outbox.New(cfg, outbox.NewMemoryStore(), outbox.Senders{SMS: stub}, newLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, box.Start())
+	defer box.Shutdown(context.Background())
+
+	id, err := box.EnqueueSMS(context.Background(), dto.SMS{To: "+15550001", Message: "hello"})
+	require.NoError(t, err)
+
+	// The timed-out send is retried rather than failed, and never overlaps the retry.
+	assert.Eventually(t, func() bool { return stub.delivered() == 1 }, 2*time.Second, 5*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		_, err := box.Get(context.Background(), id)
+		return errors.Is(err, outbox.ErrNotFound)
+	}, time.Second, 5*time.Millisecond)
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	assert.False(t, stub.overlap)
+	assert.Equal(t, 2, stub.calls)
+}
+
+func TestOutbox_RejectsEnqueueAfterShutdown(t *testing.T) {
+	box, err := outbox.New(fastOutbox, outbox.NewMemoryStore(), outbox.Senders{SMS: &stubSMS{}}, newLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, box.Start())
+	require.NoError(t, box.Shutdown(context.Background()))
+
+	_, err = box.EnqueueSMS(context.Background(), dto.SMS{To: "+15550001", Message: "hello"})
+	assert.ErrorIs(t, err, outbox.ErrClosed)
+}
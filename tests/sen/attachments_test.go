@@ -0,0 +1,75 @@
+package sen_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockEmailService(t *testing.T, attachments config.AttachmentConfig) sen.EmailService {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	emailService, err := sen.NewEmailService(config.Config{
+		Adapter:     config.AdapterConfig{Email: config.EmailMock},
+		Attachments: attachments,
+	}, log)
+	require.NoError(t, err)
+	return emailService
+}
+
+func TestEmailService_Attachments(t *testing.T) {
+	emailService := newMockEmailService(t, config.AttachmentConfig{MaxSize: 8, MaxTotalSize: 12})
+	ctx := context.Background()
+	email := func(attachments ...dto.Attachment) dto.Email {
+		return dto.Email{To: []string{"alice@example.com"}, Subject: "Invoice", Body: "See attached", Attachments: attachments}
+	}
+
+	require.NoError(t, emailService.SendEmail(ctx, email(
+		dto.Attachment{Filename: "invoice.pdf", Content: []byte("%PDF-1.7")},
+		dto.Attachment{Filename: "logo.png", Reader: strings.NewReader("PNG"), Disposition: dto.DispositionInline, ContentID: "logo"},
+	)))
+
+	err := emailService.SendEmail(ctx, email(dto.Attachment{Filename: "big.bin", Reader: bytes.NewReader(make([]byte, 9))}))
+	assert.ErrorIs(t, err, sen.ErrAttachmentTooLarge)
+	assert.ErrorContains(t, err, "big.bin exceeds 8 bytes")
+
+	err = emailService.SendEmail(ctx, email(
+		dto.Attachment{Filename: "a.txt", Content: []byte("12345678")},
+		dto.Attachment{Filename: "b.txt", Content: []byte("12345")},
+	))
+	assert.ErrorIs(t, err, sen.ErrAttachmentTooLarge)
+	assert.ErrorContains(t, err, "in total")
+
+	err = emailService.SendEmail(ctx, email(dto.Attachment{Filename: "logo.png", Content: []byte("PNG"), Disposition: dto.DispositionInline}))
+	assert.ErrorContains(t, err, "must have a content ID")
+	err = emailService.SendEmail(ctx, email(dto.Attachment{Content: []byte("data")}))
+	assert.ErrorContains(t, err, "must have a filename")
+}
+
+func TestEmailService_AttachmentsUnsupported(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	emailService, err := sen.NewEmailService(config.Config{
+		Adapter: config.AdapterConfig{Email: config.EmailMailgun},
+		Mailgun: config.MailgunConfig{APIKey: "key-test", Domain: "mg.example.com", BaseURL: "http://127.0.0.1:0", FromEmail: "noreply@mg.example.com"},
+	}, log)
+	require.NoError(t, err)
+
+	// Rejected before any request, so a failover chain moves on to a provider that supports them.
+	err = emailService.SendEmail(context.Background(), dto.Email{
+		To:          []string{"alice@example.com"},
+		Subject:     "Invoice",
+		Body:        "See attached",
+		Attachments: []dto.Attachment{{Filename: "invoice.pdf", Content: []byte("%PDF-1.7")}},
+	})
+	assert.ErrorIs(t, err, sen.ErrUnsupported)
+	assert.ErrorContains(t, err, "attachments are not supported")
+}
@@ -0,0 +1,144 @@
+package sen_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fastBreaker = config.BreakerConfig{
+	FailureRatio: 0.5,
+	MinRequests:  2,
+	OpenTimeout:  30 * time.Millisecond,
+}
+
+func TestEmailCircuitBreaker(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	email := dto.Email{To: []string{"alice@example.com"}, Subject: "Hi", Body: "Hi"}
+	down := sen.ProviderError{Provider: "brevo", Kind: sen.ErrTransient}
+
+	t.Run("opens, fails fast, probes and closes", func(t *testing.T) {
+		stub := &stubEmailAdapter{errs: []error{&down, &down}}
+		breaker := sen.NewEmailCircuitBreaker(stub, "brevo", fastBreaker, log)
+		ctx := context.Background()
+
+		assert.Error(t, breaker.SendEmail(ctx, email))
+		assert.Equal(t, sen.CircuitClosed, breaker.State())
+		assert.Error(t, breaker.SendEmail(ctx, email))
+		assert.Equal(t, sen.CircuitOpen, breaker.State())
+
+		err := breaker.SendEmail(ctx, email)
+		assert.ErrorIs(t, err, sen.ErrCircuitOpen)
+		var openErr *sen.CircuitOpenError
+		require.ErrorAs(t, err, &openErr)
+		assert.Equal(t, "brevo", openErr.Provider)
+		assert.Positive(t, openErr.RetryAfter())
+		assert.Equal(t, 2, stub.calls)
+
+		time.Sleep(fastBreaker.OpenTimeout)
+		assert.Equal(t, sen.CircuitHalfOpen, breaker.State())
+		assert.NoError(t, breaker.SendEmail(ctx, email))
+		assert.Equal(t, sen.CircuitClosed, breaker.State())
+	})
+
+	t.Run("a failed probe reopens the circuit", func(t *testing.T) {
+		stub := &stubEmailAdapter{errs: []error{&down, &down, &down}}
+		breaker := sen.NewEmailCircuitBreaker(stub, "brevo", fastBreaker, log)
+		_ = breaker.SendEmail(context.Background(), email)
+		_ = breaker.SendEmail(context.Background(), email)
+
+		time.Sleep(fastBreaker.OpenTimeout)
+		assert.ErrorIs(t, breaker.SendEmail(context.Background(), email), sen.ErrTransient)
+		assert.Equal(t, sen.CircuitOpen, breaker.State())
+	})
+
+	t.Run("permanent errors do not count", func(t *testing.T) {
+		rejected := &sen.ProviderError{Provider: "brevo", Kind: sen.ErrInvalidRecipient}
+		stub := &stubEmailAdapter{errs: []error{rejected, rejected, rejected}}
+		breaker := sen.NewEmailCircuitBreaker(stub, "brevo", fastBreaker, log)
+		for range 3 {
+			assert.ErrorIs(t, breaker.SendEmail(context.Background(), email), sen.ErrInvalidRecipient)
+		}
+		assert.Equal(t, sen.CircuitClosed, breaker.State())
+	})
+
+	t.Run("abandoned sends do not count", func(t *testing.T) {
+		stub := &stubEmailAdapter{errs: []error{context.Canceled, context.DeadlineExceeded, context.Canceled, &down, &down}}
+		breaker := sen.NewEmailCircuitBreaker(stub, "brevo", fastBreaker, log)
+		for range 3 {
+			assert.Error(t, breaker.SendEmail(context.Background(), email))
+		}
+		assert.Equal(t, sen.CircuitClosed, breaker.State())
+		// Had the abandoned sends counted as successes, two failures in five would keep it closed.
+		assert.Error(t, breaker.SendEmail(context.Background(), email))
+		assert.Error(t, breaker.SendEmail(context.Background(), email))
+		assert.Equal(t, sen.CircuitOpen, breaker.State())
+	})
+
+	t.Run("an abandoned probe does not close the circuit", func(t *testing.T) {
+		stub := &stubEmailAdapter{errs: []error{&down, &down, context.Canceled}}
+		breaker := sen.NewEmailCircuitBreaker(stub, "brevo", fastBreaker, log)
+		_ = breaker.SendEmail(context.Background(), email)
+		_ = breaker.SendEmail(context.Background(), email)
+		time.Sleep(fastBreaker.OpenTimeout)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, breaker.SendEmail(ctx, email), context.Canceled)
+		assert.Equal(t, sen.CircuitHalfOpen, breaker.State())
+
+		// The probe slot was released for the next caller.
+		assert.NoError(t, breaker.SendEmail(context.Background(), email))
+		assert.Equal(t, sen.CircuitClosed, breaker.State())
+	})
+
+	t.Run("an open circuit fails over", func(t *testing.T) {
+		primary := &stubEmailAdapter{errs: []error{&down, &down}}
+		breaker := sen.NewEmailCircuitBreaker(primary, "brevo", fastBreaker, log)
+		secondary := &stubEmailAdapter{}
+		failover := sen.NewEmailFailover(log,
+			sen.Provider[sen.EmailAdapter]{Name: "brevo", Adapter: breaker},
+			sen.Provider[sen.EmailAdapter]{Name: "smtp", Adapter: secondary},
+		)
+		for range 3 {
+			result, err := failover.SendEmailWithResult(context.Background(), email)
+			require.NoError(t, err)
+			assert.Equal(t, "smtp", result.Provider)
+		}
+		assert.Equal(t, 2, primary.calls)
+		assert.Equal(t, 3, secondary.calls)
+	})
+}
+
+func TestNotifyService_CircuitStates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	notifyService, err := sen.NewNotifyService(config.Config{
+		Adapter: config.AdapterConfig{Notify: config.NotifySlack},
+		Slack:   config.SlackConfig{WebhookURL: server.URL},
+		Breaker: fastBreaker,
+	}, log)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]sen.CircuitState{"slack": sen.CircuitClosed}, notifyService.CircuitStates())
+
+	for range 3 {
+		_ = notifyService.Alert(context.Background(), "Down", "Payment worker crashed")
+	}
+	assert.Equal(t, sen.CircuitOpen, notifyService.CircuitStates()["slack"])
+	assert.Equal(t, "open", notifyService.CircuitStates()["slack"].String())
+}
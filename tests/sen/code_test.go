@@ -0,0 +1,18 @@
+package sen_test
+
+import (
+	"testing"
+	"time"
+
+	sen "github.com/lugondev/send-sen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTTL(t *testing.T) {
+	assert.Equal(t, "10 minutes", sen.FormatTTL(sen.DefaultCodeTTL))
+	assert.Equal(t, "1 minute", sen.FormatTTL(time.Minute))
+	assert.Equal(t, "45 seconds", sen.FormatTTL(45*time.Second))
+	assert.Equal(t, "1 hour 30 minutes", sen.FormatTTL(90*time.Minute))
+	assert.Equal(t, "2 days", sen.FormatTTL(48*time.Hour+10*time.Second))
+	assert.Equal(t, "1 second", sen.FormatTTL(0))
+}
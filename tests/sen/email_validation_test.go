@@ -0,0 +1,54 @@
+package sen_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailService_ValidatesMessageFields(t *testing.T) {
+	emailService := newMockEmailService(t, config.AttachmentConfig{})
+	ctx := context.Background()
+	email := func(edit func(*dto.Email)) dto.Email {
+		message := dto.Email{To: []string{"alice@example.com"}, Subject: "Your ticket", Body: "We are on it"}
+		edit(&message)
+		return message
+	}
+
+	require.NoError(t, emailService.SendEmail(ctx, email(func(e *dto.Email) {
+		e.From = "Support <support@example.com>"
+		e.ReplyTo = "tickets@example.com"
+		e.Headers = map[string]string{"X-Ticket-ID": "T-1"}
+		e.Tags = []string{"support"}
+		e.Metadata = map[string]string{"user_id": "42"}
+	})))
+
+	tests := []struct {
+		name string
+		edit func(*dto.Email)
+		want string
+	}{
+		{"from", func(e *dto.Email) { e.From = "not an address" }, "invalid From address"},
+		{"reply-to", func(e *dto.Email) { e.ReplyTo = "tickets" }, "invalid Reply-To address"},
+		{"header name", func(e *dto.Email) { e.Headers = map[string]string{"X Ticket": "T-1"} }, "invalid header name"},
+		{"reserved header", func(e *dto.Email) { e.Headers = map[string]string{"reply-to": "x@example.com"} }, "cannot be set as a custom header"},
+		{"header injection", func(e *dto.Email) { e.Headers = map[string]string{"X-Ticket-ID": "T-1\r\nBcc: eve@example.com"} }, "must not contain line breaks"},
+		{"empty tag", func(e *dto.Email) { e.Tags = []string{""} }, "invalid tag"},
+		{"too many tags", func(e *dto.Email) {
+			for i := range 11 {
+				e.Tags = append(e.Tags, fmt.Sprintf("tag-%d", i))
+			}
+		}, "at most 10 tags"},
+		{"metadata key", func(e *dto.Email) { e.Metadata = map[string]string{"": "42"} }, "invalid metadata key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, emailService.SendEmail(ctx, email(tt.edit)), tt.want)
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package sen_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/testkit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errProviderDown = errors.New("provider down")
+
+// stubEmailAdapter returns the queued errors in order, then succeeds.
+type stubEmailAdapter struct {
+	errs  []error
+	calls int
+}
+
+func (a *stubEmailAdapter) SendEmail(ctx context.Context, email dto.Email) error {
+	a.calls++
+	if len(a.errs) >= a.calls {
+		return a.errs[a.calls-1]
+	}
+	return nil
+}
+
+type stubSMSAdapter struct {
+	err   error
+	calls int
+}
+
+func (a *stubSMSAdapter) Send(ctx context.Context, sms dto.SMS) error {
+	a.calls++
+	return a.err
+}
+
+type stubNotifyAdapter struct {
+	err   error
+	calls int
+}
+
+func (a *stubNotifyAdapter) Send(ctx context.Context, content dto.Content) error {
+	a.calls++
+	return a.err
+}
+
+func TestEmailFailover(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	primary := &stubEmailAdapter{errs: []error{errProviderDown}}
+	secondary := &stubEmailAdapter{}
+	tertiary := &stubEmailAdapter{}
+	failover := sen.NewEmailFailover(log,
+		sen.Provider[sen.EmailAdapter]{Name: "sendgrid", Adapter: primary},
+		sen.Provider[sen.EmailAdapter]{Name: "brevo", Adapter: secondary},
+		sen.Provider[sen.EmailAdapter]{Name: "smtp", Adapter: tertiary},
+	)
+
+	err = failover.SendEmail(context.Background(), dto.Email{To: []string{"alice@example.com"}, Subject: "Hi", Body: "Hi"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, primary.calls)
+	assert.Equal(t, 1, secondary.calls)
+	assert.Equal(t, 0, tertiary.calls)
+}
+
+func TestEmailFailover_AllFail(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	failover := sen.NewEmailFailover(log,
+		sen.Provider[sen.EmailAdapter]{Name: "sendgrid", Adapter: &stubEmailAdapter{errs: []error{errProviderDown}}},
+		sen.Provider[sen.EmailAdapter]{Name: "brevo", Adapter: &stubEmailAdapter{errs: []error{errors.New("brevo timeout")}}},
+	)
+
+	err = failover.SendEmail(context.Background(), dto.Email{To: []string{"alice@example.com"}, Subject: "Hi", Body: "Hi"})
+	assert.ErrorIs(t, err, errProviderDown)
+	assert.ErrorContains(t, err, "all 2 email providers failed")
+	assert.ErrorContains(t, err, "sendgrid: provider down")
+	assert.ErrorContains(t, err, "brevo: brevo timeout")
+}
+
+func TestFailover_StopsWhenContextDone(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	primary := &stubSMSAdapter{err: context.Canceled}
+	secondary := &stubSMSAdapter{}
+	failover := sen.NewSMSFailover(log,
+		sen.Provider[sen.SMSAdapter]{Name: "twilio", Adapter: primary},
+		sen.Provider[sen.SMSAdapter]{Name: "vonage", Adapter: secondary},
+	)
+	err = failover.Send(ctx, dto.SMS{To: "+84909123456", Message: "hello"})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, secondary.calls)
+}
+
+func TestFailover_StopsOnInvalidRecipient(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	invalid := &sen.ProviderError{Provider: "twilio", Kind: sen.ErrInvalidRecipient, Code: "21211", Message: "Invalid 'To' Phone Number"}
+	primary := &stubSMSAdapter{err: invalid}
+	secondary := &stubSMSAdapter{}
+	failover := sen.NewSMSFailover(log,
+		sen.Provider[sen.SMSAdapter]{Name: "twilio", Adapter: primary},
+		sen.Provider[sen.SMSAdapter]{Name: "vonage", Adapter: secondary},
+	)
+	err = failover.Send(context.Background(), dto.SMS{To: "+1555", Message: "hello"})
+	assert.ErrorIs(t, err, sen.ErrInvalidRecipient)
+	assert.False(t, sen.IsTransient(err))
+	assert.Equal(t, 0, secondary.calls)
+}
+
+func TestFailover_StopsOnRejected(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	rejected := &sen.ProviderError{Provider: "sendgrid", Kind: sen.ErrRejected, StatusCode: 400, Message: "content blocked"}
+	primary := &stubEmailAdapter{errs: []error{rejected}}
+	secondary := &stubEmailAdapter{}
+	failover := sen.NewEmailFailover(log,
+		sen.Provider[sen.EmailAdapter]{Name: "sendgrid", Adapter: primary},
+		sen.Provider[sen.EmailAdapter]{Name: "brevo", Adapter: secondary},
+	)
+	err = failover.SendEmail(context.Background(), dto.Email{To: []string{"alice@example.com"}, Subject: "Hi", Body: "Hi"})
+	assert.ErrorIs(t, err, sen.ErrRejected)
+	assert.Equal(t, 0, secondary.calls)
+}
+
+func TestFailover_MovesOnForProviderSpecificErrors(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	for _, kind := range []error{sen.ErrTransient, sen.ErrRateLimited, sen.ErrAuthentication, sen.ErrUnsupported} {
+		t.Run(kind.Error(), func(t *testing.T) {
+			primary := &stubEmailAdapter{errs: []error{&sen.ProviderError{Provider: "ses", Kind: kind}}}
+			secondary := &stubEmailAdapter{}
+			failover := sen.NewEmailFailover(log,
+				sen.Provider[sen.EmailAdapter]{Name: "ses", Adapter: primary},
+				sen.Provider[sen.EmailAdapter]{Name: "sendgrid", Adapter: secondary},
+			)
+			err := failover.SendEmail(context.Background(), dto.Email{To: []string{"alice@example.com"}, Subject: "Hi", Body: "Hi"})
+			require.NoError(t, err)
+			assert.Equal(t, 1, secondary.calls)
+		})
+	}
+}
+
+func TestNotifyFailover(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	slack := &stubNotifyAdapter{err: errProviderDown}
+	telegram := &stubNotifyAdapter{}
+	failover := sen.NewNotifyFailover(log,
+		sen.Provider[sen.NotifyAdapter]{Name: "slack", Adapter: slack},
+		sen.Provider[sen.NotifyAdapter]{Name: "telegram", Adapter: telegram},
+	)
+	err = failover.Send(context.Background(), dto.Content{Message: "disk full", Level: dto.Error})
+	require.NoError(t, err)
+	assert.Equal(t, 1, telegram.calls)
+}
+
+func TestEmailService_ProviderChain(t *testing.T) {
+	mailgunCalls := 0
+	mailgun := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mailgunCalls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"message":"Service Unavailable"}`))
+	}))
+	defer mailgun.Close()
+	sesCalls := 0
+	ses := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sesCalls++
+		_, _ = w.Write([]byte(`{"MessageId":"ses-1"}`))
+	}))
+	defer ses.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	emailService, err := sen.NewEmailService(config.Config{
+		Adapter: config.AdapterConfig{
+			Email:      config.EmailSendGrid,
+			EmailChain: []config.EmailProvider{config.EmailMailgun, config.EmailSES},
+		},
+		Mailgun: config.MailgunConfig{APIKey: "key", Domain: "mg.example.com", BaseURL: mailgun.URL, FromEmail: "noreply@example.com"},
+		SES:     config.SESConfig{Region: "us-east-1", AccessKeyID: "AKID", SecretAccessKey: "secret", FromEmail: "noreply@example.com", Endpoint: ses.URL},
+	}, log)
+	require.NoError(t, err)
+	assert.Equal(t, "mailgun,ses", emailService.ServiceName())
+
+	err = emailService.SendVerificationCode(context.Background(), "alice@example.com", "123456")
+	require.NoError(t, err)
+	assert.Equal(t, 1, mailgunCalls)
+	assert.Equal(t, 1, sesCalls)
+}
+
+func TestServices_RejectUnusableProviders(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	// A provider that fails to build must not be replaced by the mock.
+	_, err = sen.NewEmailService(config.Config{
+		Adapter: config.AdapterConfig{EmailChain: []config.EmailProvider{config.EmailSendGrid, config.EmailMock}},
+	}, log)
+	assert.ErrorContains(t, err, "failed to create SendGrid email adapter")
+
+	_, err = sen.NewSMSService(config.Config{
+		Adapter: config.AdapterConfig{SMSChain: []config.SMSProvider{"twillio", config.SMSProviderMock}},
+	}, log)
+	assert.ErrorContains(t, err, `unsupported SMS provider "twillio"`)
+
+	_, err = sen.NewNotifyService(config.Config{
+		Adapter: config.AdapterConfig{Notify: "slak"},
+	}, log)
+	assert.ErrorContains(t, err, `unsupported notify channel "slak"`)
+
+	_, err = sen.NewEmailService(config.Config{}, log)
+	assert.ErrorContains(t, err, "no email provider configured")
+	_, err = sen.NewSMSService(config.Config{}, log)
+	assert.ErrorContains(t, err, "no SMS provider configured")
+
+	// The mock is still available when asked for.
+	emailService, err := sen.NewEmailService(config.Config{Adapter: config.AdapterConfig{Email: config.EmailMock}}, log)
+	require.NoError(t, err)
+	assert.Equal(t, "mock", emailService.ServiceName())
+}
+
+func TestEmailService_ProviderTimeoutFailsOver(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer slow.Close()
+	defer close(release)
+	ses := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"MessageId":"ses-1"}`))
+	}))
+	defer ses.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	emailService, err := sen.NewEmailService(config.Config{
+		Adapter: config.AdapterConfig{EmailChain: []config.EmailProvider{config.EmailMailgun, config.EmailSES}},
+		Mailgun: config.MailgunConfig{APIKey: "key", Domain: "mg.example.com", BaseURL: slow.URL, FromEmail: "noreply@example.com", Timeout: 50 * time.Millisecond},
+		SES:     config.SESConfig{Region: "us-east-1", AccessKeyID: "AKID", SecretAccessKey: "secret", FromEmail: "noreply@example.com", Endpoint: ses.URL},
+	}, log)
+	require.NoError(t, err)
+
+	result, err := emailService.SendEmailWithResult(context.Background(), dto.Email{To: []string{"alice@example.com"}, Subject: "Hi", Body: "Hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "ses", result.Provider)
+}
+
+func TestEmailService_FailsOverBetweenFakes(t *testing.T) {
+	sendGrid := testkit.NewSendGrid(t)
+	brevo := testkit.NewBrevo(t)
+	sendGrid.FailNext(testkit.Unavailable())
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	emailService, err := sen.NewEmailService(config.Config{
+		Adapter:  config.AdapterConfig{EmailChain: []config.EmailProvider{config.EmailSendGrid, config.EmailBrevo}},
+		SendGrid: sendGrid.Config(),
+		Brevo:    brevo.Config(),
+	}, log)
+	require.NoError(t, err)
+
+	err = emailService.SendVerificationCode(context.Background(), "alice@example.com", "123456")
+	require.NoError(t, err)
+	assert.Len(t, sendGrid.Requests(), 1)
+	assert.Empty(t, sendGrid.Messages())
+	emails := brevo.Emails()
+	require.Len(t, emails, 1)
+	assert.Equal(t, "alice@example.com", emails[0].To[0].Email)
+}
@@ -0,0 +1,57 @@
+package sen_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// markingTransport tags every request with its value so the stubs can tell
+// which injected client sent it.
+type markingTransport string
+
+func (m markingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("X-Injected-Client", string(m))
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestEmailService_WithHTTPClient(t *testing.T) {
+	var mailgunClient, sendGridClient string
+	mailgun := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mailgunClient = r.Header.Get("X-Injected-Client")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"message":"Service Unavailable"}`))
+	}))
+	defer mailgun.Close()
+	sendGrid := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sendGridClient = r.Header.Get("X-Injected-Client")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer sendGrid.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	emailService, err := sen.NewEmailService(config.Config{
+		Adapter:  config.AdapterConfig{EmailChain: []config.EmailProvider{config.EmailMailgun, config.EmailSendGrid}},
+		Mailgun:  config.MailgunConfig{APIKey: "key", Domain: "mg.example.com", BaseURL: mailgun.URL, FromEmail: "noreply@example.com"},
+		SendGrid: config.SendGridConfig{APIKey: "SG.test", FromEmail: "noreply@example.com", BaseURL: sendGrid.URL},
+	}, log,
+		sen.WithHTTPClient(&http.Client{Transport: markingTransport("default")}),
+		sen.WithHTTPClient(&http.Client{Transport: markingTransport("sendgrid")}, string(config.EmailSendGrid)),
+	)
+	require.NoError(t, err)
+
+	err = emailService.SendVerificationCode(context.Background(), "alice@example.com", "123456")
+	require.NoError(t, err)
+	assert.Equal(t, "default", mailgunClient)
+	assert.Equal(t, "sendgrid", sendGridClient)
+}
@@ -0,0 +1,152 @@
+package sen_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dedup"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCountingMessageBird starts a MessageBird API stub that counts sends and returns a new ID per send.
+func newCountingMessageBird(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		time.Sleep(10 * time.Millisecond) // Keep concurrent duplicates in flight together
+		_, _ = fmt.Fprintf(w, `{"id":"msg-%d","recipients":{"totalCount":1,"items":[{"recipient":15550001,"status":"sent"}]}}`, n)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newIdempotentSMSService(t *testing.T, serverURL string, idempotency config.IdempotencyConfig, opts ...sen.ServiceOption) sen.SMSService {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	smsService, err := sen.NewSMSService(config.Config{
+		Adapter:     config.AdapterConfig{SMS: config.SMSProviderMessageBird},
+		MessageBird: config.MessageBirdConfig{AccessKey: "test-key", Originator: "SendSen", BaseURL: serverURL},
+		Idempotency: idempotency,
+	}, log, opts...)
+	require.NoError(t, err)
+	return smsService
+}
+
+func TestSMSService_IdempotencyKey(t *testing.T) {
+	var calls atomic.Int32
+	server := newCountingMessageBird(t, &calls)
+	smsService := newIdempotentSMSService(t, server.URL, config.IdempotencyConfig{})
+	ctx := context.Background()
+
+	first, err := smsService.SendWithResult(ctx, dto.SMS{To: "+15550001", Message: "Your code is 1234", IdempotencyKey: "signup-42"})
+	require.NoError(t, err)
+	repeat, err := smsService.SendWithResult(ctx, dto.SMS{To: "+15550001", Message: "Your code is 1234", IdempotencyKey: "signup-42"})
+	require.NoError(t, err)
+	assert.Equal(t, first, repeat)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Without a key, or with another key, every call sends.
+	require.NoError(t, smsService.Send(ctx, dto.SMS{To: "+15550001", Message: "hello"}))
+	require.NoError(t, smsService.Send(ctx, dto.SMS{To: "+15550001", Message: "hello"}))
+	require.NoError(t, smsService.Send(ctx, dto.SMS{To: "+15550001", Message: "hello", IdempotencyKey: "signup-43"}))
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestSMSService_IdempotencyCoalescesConcurrentSends(t *testing.T) {
+	var calls atomic.Int32
+	server := newCountingMessageBird(t, &calls)
+	smsService := newIdempotentSMSService(t, server.URL, config.IdempotencyConfig{})
+
+	var wg sync.WaitGroup
+	results := make([]dto.SendResult, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := smsService.SendWithResult(context.Background(), dto.SMS{To: "+15550001", Message: "hi", IdempotencyKey: "k"})
+			assert.NoError(t, err)
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, result := range results {
+		assert.Equal(t, results[0].MessageID, result.MessageID)
+	}
+}
+
+func TestSMSService_IdempotencyWindowAndSharedStore(t *testing.T) {
+	var calls atomic.Int32
+	server := newCountingMessageBird(t, &calls)
+	store, err := dedup.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	window := config.IdempotencyConfig{Window: 50 * time.Millisecond}
+	msg := dto.SMS{To: "+15550001", Message: "hi", IdempotencyKey: "k"}
+
+	// A second service instance (e.g. after a restart) sees keys recorded by the first.
+	require.NoError(t, newIdempotentSMSService(t, server.URL, window, sen.WithDedupStore(store)).Send(context.Background(), msg))
+	second := newIdempotentSMSService(t, server.URL, window, sen.WithDedupStore(store))
+	require.NoError(t, second.Send(context.Background(), msg))
+	assert.Equal(t, int32(1), calls.Load())
+
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, second.Send(context.Background(), msg))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestSMSService_IdempotencyDoesNotRememberFailures(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"errors":[{"code":25,"description":"Not enough balance","parameter":null}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"msg-2","recipients":{"totalCount":1,"items":[{"recipient":15550001,"status":"sent"}]}}`))
+	}))
+	defer server.Close()
+	smsService := newIdempotentSMSService(t, server.URL, config.IdempotencyConfig{})
+	msg := dto.SMS{To: "+15550001", Message: "hi", IdempotencyKey: "k"}
+
+	assert.Error(t, smsService.Send(context.Background(), msg))
+	result, err := smsService.SendWithResult(context.Background(), msg)
+	require.NoError(t, err)
+	assert.Equal(t, "msg-2", result.MessageID)
+}
+
+func TestDedupFileStore_SweepsExpiredEntries(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	store, err := dedup.NewFileStore(dir)
+	require.NoError(t, err)
+	for i := range 3 {
+		require.NoError(t, store.Put(ctx, fmt.Sprintf("once-%d", i), dto.SendResult{MessageID: "m"}, 10*time.Millisecond))
+	}
+	require.NoError(t, store.Put(ctx, "kept", dto.SendResult{MessageID: "kept"}, time.Hour))
+	time.Sleep(20 * time.Millisecond)
+
+	// Unique keys are never read again; the next sweep removes them anyway.
+	store, err = dedup.NewFileStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, store.Put(ctx, "new", dto.SendResult{MessageID: "new"}, time.Hour))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	result, ok, err := store.Get(ctx, "kept")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "kept", result.MessageID)
+}
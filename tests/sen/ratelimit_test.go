@@ -0,0 +1,151 @@
+package sen_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMSService_RecipientRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	server := newCountingMessageBird(t, &calls)
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	smsService, err := sen.NewSMSService(config.Config{
+		Adapter:     config.AdapterConfig{SMS: config.SMSProviderMessageBird},
+		MessageBird: config.MessageBirdConfig{AccessKey: "test-key", Originator: "SendSen", BaseURL: server.URL},
+		RateLimit:   config.RateLimitConfig{Recipient: config.RateLimit{Limit: 2, Per: time.Hour}},
+	}, log)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, smsService.SendCode(ctx, "+15550001", "123456"))
+	require.NoError(t, smsService.SendCode(ctx, "+15550001", "123456"))
+	err = smsService.SendCode(ctx, "+15550001", "123456")
+	assert.ErrorIs(t, err, sen.ErrRateLimited)
+	var limitErr *sen.RateLimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, "recipient", limitErr.Scope)
+	assert.Equal(t, "+15550001", limitErr.Key)
+	assert.Greater(t, limitErr.RetryAfter(), 20*time.Minute)
+	assert.True(t, sen.IsTransient(err))
+	assert.Equal(t, int32(2), calls.Load())
+
+	// Other recipients have their own bucket.
+	require.NoError(t, smsService.SendCode(ctx, "+15550002", "123456"))
+}
+
+func TestNotifyService_GlobalRateLimit(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	store := ratelimit.NewMemoryStore()
+	cfg := config.Config{
+		Adapter:   config.AdapterConfig{Notify: config.NotifyMock},
+		RateLimit: config.RateLimitConfig{Global: config.RateLimit{Limit: 1, Per: time.Hour}},
+	}
+
+	// Instances sharing a store share the limit.
+	first, err := sen.NewNotifyService(cfg, log, sen.WithRateLimitStore(store))
+	require.NoError(t, err)
+	second, err := sen.NewNotifyService(cfg, log, sen.WithRateLimitStore(store))
+	require.NoError(t, err)
+
+	require.NoError(t, first.Info(context.Background(), "Deploy", "v1.2.3 is live"))
+	assert.ErrorIs(t, second.Info(context.Background(), "Deploy", "v1.2.4 is live"), sen.ErrRateLimited)
+}
+
+func TestProviderRateLimit_FailsOver(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	limiter := ratelimit.New(nil)
+	twilio, vonage := &stubSMSAdapter{}, &stubSMSAdapter{}
+
+	failover := sen.NewSMSFailover(log,
+		sen.Provider[sen.SMSAdapter]{Name: "twilio", Adapter: sen.NewSMSRateLimit(twilio, limiter, "twilio", config.RateLimit{Limit: 1, Per: time.Hour})},
+		sen.Provider[sen.SMSAdapter]{Name: "vonage", Adapter: vonage},
+	)
+	sms := dto.SMS{To: "+15550001", Message: "hi"}
+
+	result, err := failover.SendWithResult(context.Background(), sms)
+	require.NoError(t, err)
+	assert.Equal(t, "twilio", result.Provider)
+	result, err = failover.SendWithResult(context.Background(), sms)
+	require.NoError(t, err)
+	assert.Equal(t, "vonage", result.Provider)
+	assert.Equal(t, 1, twilio.calls)
+}
+
+func TestMemoryStore_Refills(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.NewMemoryStore())
+	limit := config.RateLimit{Limit: 2, Per: 40 * time.Millisecond}
+	ctx := context.Background()
+
+	require.NoError(t, limiter.Allow(ctx, "provider", "sendgrid", limit))
+	require.NoError(t, limiter.Allow(ctx, "provider", "sendgrid", limit))
+	err := limiter.Allow(ctx, "provider", "sendgrid", limit)
+	require.ErrorIs(t, err, sen.ErrRateLimited)
+	retryAfter, ok := sen.RetryAfter(err)
+	require.True(t, ok)
+	assert.LessOrEqual(t, retryAfter, 20*time.Millisecond)
+
+	time.Sleep(retryAfter)
+	assert.NoError(t, limiter.Allow(ctx, "provider", "sendgrid", limit))
+	// Disabled limits always allow.
+	assert.NoError(t, limiter.Allow(ctx, "provider", "sendgrid", config.RateLimit{}))
+}
+
+func TestSMSService_LimitedRecipientKeepsGlobalBucket(t *testing.T) {
+	var calls atomic.Int32
+	server := newCountingMessageBird(t, &calls)
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	smsService, err := sen.NewSMSService(config.Config{
+		Adapter:     config.AdapterConfig{SMS: config.SMSProviderMessageBird},
+		MessageBird: config.MessageBirdConfig{AccessKey: "test-key", Originator: "SendSen", BaseURL: server.URL},
+		RateLimit: config.RateLimitConfig{
+			Global:    config.RateLimit{Limit: 5, Per: time.Hour},
+			Recipient: config.RateLimit{Limit: 1, Per: time.Hour},
+		},
+	}, log)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, smsService.SendCode(ctx, "+15550001", "123456"))
+	for range 20 {
+		var limitErr *sen.RateLimitError
+		require.ErrorAs(t, smsService.SendCode(ctx, "+15550001", "123456"), &limitErr)
+		assert.Equal(t, "recipient", limitErr.Scope)
+	}
+
+	// The rejected sends did not take global tokens.
+	require.NoError(t, smsService.SendCode(ctx, "+15550002", "123456"))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestEmailService_RateLimitRefundsRecipients(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	emailService, err := sen.NewEmailService(config.Config{
+		Adapter:   config.AdapterConfig{Email: config.EmailMock},
+		RateLimit: config.RateLimitConfig{Recipient: config.RateLimit{Limit: 1, Per: time.Hour}},
+	}, log)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, emailService.SendEmail(ctx, dto.Email{To: []string{"bob@example.com"}, Subject: "Hi", Body: "Hi"}))
+	// Bob is limited, so Alice's token is given back.
+	err = emailService.SendEmail(ctx, dto.Email{To: []string{"alice@example.com", "bob@example.com"}, Subject: "Hi", Body: "Hi"})
+	assert.ErrorIs(t, err, sen.ErrRateLimited)
+	require.NoError(t, emailService.SendEmail(ctx, dto.Email{To: []string{"alice@example.com"}, Subject: "Hi", Body: "Hi"}))
+}
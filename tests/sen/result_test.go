@@ -0,0 +1,75 @@
+package sen_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailover_ResultNamesDeliveringProvider(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	// The stubs only implement the error-returning interface, so the result is synthesized.
+	failover := sen.NewEmailFailover(log,
+		sen.Provider[sen.EmailAdapter]{Name: "sendgrid", Adapter: &stubEmailAdapter{errs: []error{errProviderDown}}},
+		sen.Provider[sen.EmailAdapter]{Name: "smtp", Adapter: &stubEmailAdapter{}},
+	)
+	result, err := failover.SendEmailWithResult(context.Background(), dto.Email{
+		To:      []string{"alice@example.com"},
+		Bcc:     []string{"audit@example.com"},
+		Subject: "Hi",
+		Body:    "Hi",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "smtp", result.Provider)
+	assert.Empty(t, result.MessageID)
+	assert.Equal(t, []string{"alice@example.com", "audit@example.com"}, result.Accepted)
+	assert.False(t, result.Timestamp.IsZero())
+}
+
+func TestSMSService_SendWithResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"e8077d803532c0b5937c639b60216938","recipients":{"totalCount":1,"items":[{"recipient":31612345678,"status":"sent"}]}}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	smsService, err := sen.NewSMSService(config.Config{
+		Adapter:     config.AdapterConfig{SMS: config.SMSProviderMessageBird},
+		MessageBird: config.MessageBirdConfig{AccessKey: "test-key", Originator: "SendSen", BaseURL: server.URL},
+		Retry:       fastRetry,
+	}, log)
+	require.NoError(t, err)
+
+	result, err := smsService.SendWithResult(context.Background(), dto.SMS{To: "+31612345678", Message: "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "messagebird", result.Provider)
+	assert.Equal(t, "e8077d803532c0b5937c639b60216938", result.MessageID)
+	assert.Equal(t, "sent", result.Status)
+	assert.Equal(t, []string{"+31612345678"}, result.Accepted)
+}
+
+func TestNotifyService_MockResult(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	notifyService, err := sen.NewNotifyService(config.Config{
+		Adapter: config.AdapterConfig{Notify: config.NotifyMock},
+	}, log)
+	require.NoError(t, err)
+
+	result, err := notifyService.SendWithResult(context.Background(), dto.Content{Message: "disk full"})
+	require.NoError(t, err)
+	assert.Equal(t, "mock", result.Provider)
+}
@@ -0,0 +1,124 @@
+package sen_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// temporaryError is a transient failure, optionally carrying a provider requested delay.
+type temporaryError struct {
+	retryAfter time.Duration
+}
+
+func (e temporaryError) Error() string             { return "temporarily unavailable" }
+func (e temporaryError) Temporary() bool           { return true }
+func (e temporaryError) RetryAfter() time.Duration { return e.retryAfter }
+
+var fastRetry = config.RetryConfig{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+}
+
+func TestEmailRetry(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	email := dto.Email{To: []string{"alice@example.com"}, Subject: "Hi", Body: "Hi"}
+
+	t.Run("retries transient errors until success", func(t *testing.T) {
+		stub := &stubEmailAdapter{errs: []error{temporaryError{}, temporaryError{}}}
+		err := sen.NewEmailRetry(stub, fastRetry, log).SendEmail(context.Background(), email)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, stub.calls)
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		stub := &stubEmailAdapter{errs: []error{errProviderDown}}
+		err := sen.NewEmailRetry(stub, fastRetry, log).SendEmail(context.Background(), email)
+		assert.ErrorIs(t, err, errProviderDown)
+		assert.Equal(t, 1, stub.calls)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		stub := &stubEmailAdapter{errs: []error{temporaryError{}, temporaryError{}, temporaryError{}, temporaryError{}}}
+		err := sen.NewEmailRetry(stub, fastRetry, log).SendEmail(context.Background(), email)
+		assert.ErrorAs(t, err, &temporaryError{})
+		assert.ErrorContains(t, err, "giving up after 3 attempts")
+		assert.Equal(t, 3, stub.calls)
+	})
+
+	t.Run("honors retry-after", func(t *testing.T) {
+		stub := &stubEmailAdapter{errs: []error{temporaryError{retryAfter: 50 * time.Millisecond}}}
+		start := time.Now()
+		err := sen.NewEmailRetry(stub, fastRetry, log).SendEmail(context.Background(), email)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+
+	t.Run("does not wait past the context deadline", func(t *testing.T) {
+		stub := &stubEmailAdapter{errs: []error{temporaryError{retryAfter: time.Minute}}}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		start := time.Now()
+		err := sen.NewEmailRetry(stub, fastRetry, log).SendEmail(ctx, email)
+		assert.ErrorContains(t, err, "would exceed the deadline")
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Equal(t, 1, stub.calls)
+	})
+}
+
+func TestIsTransient(t *testing.T) {
+	assert.True(t, sen.IsTransient(temporaryError{}))
+	assert.True(t, sen.IsTransient(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.False(t, sen.IsTransient(errProviderDown))
+	assert.False(t, sen.IsTransient(context.Canceled))
+	assert.False(t, sen.IsTransient(nil))
+
+	retryAfter, ok := sen.RetryAfter(temporaryError{retryAfter: 2 * time.Second})
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, retryAfter)
+}
+
+func TestNotifyService_RetriesConnectionResets(t *testing.T) {
+	// A server that resets every connection produces a transient network error.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	var connections atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			connections.Add(1)
+			_ = conn.(*net.TCPConn).SetLinger(0)
+			_ = conn.Close()
+		}
+	}()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	notifyService, err := sen.NewNotifyService(config.Config{
+		Adapter: config.AdapterConfig{Notify: config.NotifySlack},
+		Slack:   config.SlackConfig{WebhookURL: "http://" + listener.Addr().String()},
+		Retry:   fastRetry,
+	}, log)
+	require.NoError(t, err)
+
+	err = notifyService.Alert(context.Background(), "Down", "Payment worker crashed")
+	assert.ErrorContains(t, err, "giving up after 3 attempts")
+	assert.Equal(t, int32(3), connections.Load())
+}
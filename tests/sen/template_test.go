@@ -0,0 +1,145 @@
+package sen_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/templates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailService_SendTemplate(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		form = r.MultipartForm.Value
+		_, _ = w.Write([]byte(`{"id":"<20240101.1@mg.example.com>","message":"Queued. Thank you."}`))
+	}))
+	defer server.Close()
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	cfg := config.Config{
+		Adapter:   config.AdapterConfig{Email: config.EmailMailgun},
+		Mailgun:   config.MailgunConfig{APIKey: "key-test", Domain: "mg.example.com", BaseURL: server.URL, FromEmail: "noreply@mg.example.com"},
+		Templates: config.TemplateConfig{ProductName: "Acme"},
+	}
+	registry, err := templates.Load(templates.Funcs(cfg.Templates), templates.Builtin(), fstest.MapFS{
+		"order_shipped.subject.tmpl": {Data: []byte("Order {{.Order}} has shipped")},
+		"order_shipped.html.tmpl":    {Data: []byte(`{{template "layout" .}}{{define "body"}}<p>Tracking: {{.Tracking}}</p>{{end}}`)},
+		"order_shipped.text.tmpl":    {Data: []byte("Tracking: {{.Tracking}}")},
+	})
+	require.NoError(t, err)
+	emailService, err := sen.NewEmailService(cfg, log, sen.WithTemplates(registry))
+	require.NoError(t, err)
+
+	err = emailService.SendTemplate(context.Background(), "order_shipped", "alice@example.com", map[string]string{"Order": "#42", "Tracking": "1Z999"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Order #42 has shipped"}, form["subject"])
+	assert.Equal(t, []string{"Tracking: 1Z999"}, form["text"])
+	assert.Contains(t, form["html"][0], "<p>Tracking: 1Z999</p>")
+	assert.Contains(t, form["html"][0], "Sent by Acme")
+
+	// Built-in helpers render through the same registry.
+	require.NoError(t, emailService.SendWelcome(context.Background(), "alice@example.com", "Alice"))
+	assert.Equal(t, []string{"Welcome to Acme!"}, form["subject"])
+
+	assert.ErrorContains(t, emailService.SendTemplate(context.Background(), "missing", "alice@example.com", nil), "not found")
+}
+
+func TestServices_Locale(t *testing.T) {
+	var form url.Values
+	mailgun := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		form = r.MultipartForm.Value
+		_, _ = w.Write([]byte(`{"id":"<20240101.1@mg.example.com>","message":"Queued. Thank you."}`))
+	}))
+	defer mailgun.Close()
+	var sms struct {
+		Body string `json:"body"`
+	}
+	messageBird := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&sms))
+		_, _ = w.Write([]byte(`{"id":"msg-1","recipients":{"totalCount":1,"items":[{"recipient":15550001,"status":"sent"}]}}`))
+	}))
+	defer messageBird.Close()
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	cfg := config.Config{
+		Adapter:     config.AdapterConfig{Email: config.EmailMailgun, SMS: config.SMSProviderMessageBird},
+		Mailgun:     config.MailgunConfig{APIKey: "key-test", Domain: "mg.example.com", BaseURL: mailgun.URL, FromEmail: "noreply@mg.example.com"},
+		MessageBird: config.MessageBirdConfig{AccessKey: "test-key", Originator: "SendSen", BaseURL: messageBird.URL},
+	}
+	emailService, err := sen.NewEmailService(cfg, log)
+	require.NoError(t, err)
+	smsService, err := sen.NewSMSService(cfg, log)
+	require.NoError(t, err)
+	ctx := sen.WithLocale(context.Background(), "vi-VN")
+
+	at := time.Date(2024, time.March, 5, 14, 30, 0, 0, time.UTC)
+	require.NoError(t, emailService.SendLoginWarning(ctx, "an@example.com", "Hà Nội", at))
+	assert.Equal(t, []string{"Cảnh báo bảo mật: Phát hiện đăng nhập mới"}, form["subject"])
+	assert.Contains(t, form["text"][0], "lúc 14:30 05/03/2024 (UTC)")
+
+	require.NoError(t, emailService.SendVerificationCodeWithTTL(ctx, "an@example.com", "123456", 5*time.Minute))
+	assert.Equal(t, []string{"Mã xác minh của bạn là: 123456. Mã sẽ hết hạn sau 5 phút."}, form["text"])
+
+	require.NoError(t, smsService.SendCode(ctx, "+15550001", "4242"))
+	assert.Equal(t, "Mã xác minh của bạn là: 4242. Mã sẽ hết hạn sau 10 phút.", sms.Body)
+
+	// Without a locale the texts stay in English.
+	require.NoError(t, smsService.SendCode(context.Background(), "+15550001", "4242"))
+	assert.Equal(t, "Your verification code is: 4242. This code will expire in 10 minutes.", sms.Body)
+}
+
+func TestEmailService_HTMLOnly(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		form = r.MultipartForm.Value
+		_, _ = w.Write([]byte(`{"id":"<20240101.1@mg.example.com>","message":"Queued. Thank you."}`))
+	}))
+	defer server.Close()
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	cfg := config.Config{
+		Adapter: config.AdapterConfig{Email: config.EmailMailgun},
+		Mailgun: config.MailgunConfig{APIKey: "key-test", Domain: "mg.example.com", BaseURL: server.URL, FromEmail: "noreply@mg.example.com"},
+	}
+	registry, err := templates.Load(templates.Funcs(cfg.Templates), templates.Builtin(), fstest.MapFS{
+		"comment.subject.tmpl": {Data: []byte("New comment from {{.Author}}")},
+		"comment.html.tmpl":    {Data: []byte(`<h1>{{.Author}} wrote</h1><blockquote>{{sanitize .Comment}}</blockquote><p><a href="https://acme.test/c/1">Reply</a></p>`)},
+	})
+	require.NoError(t, err)
+	emailService, err := sen.NewEmailService(cfg, log, sen.WithTemplates(registry))
+	require.NoError(t, err)
+
+	err = emailService.SendTemplate(context.Background(), "comment", "alice@example.com", map[string]string{
+		"Author":  "Bob",
+		"Comment": `<p onclick="x()">Looks <b>great</b><script>alert(1)</script></p>`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `<h1>Bob wrote</h1><blockquote><p>Looks <b>great</b></p></blockquote><p><a href="https://acme.test/c/1">Reply</a></p>`, form["html"][0])
+	assert.Equal(t, []string{"Bob wrote\n=========\n\n> Looks great\n\nReply [1]\n\n[1] https://acme.test/c/1"}, form["text"])
+
+	// Plain emails with only an HTML part are accepted too.
+	err = emailService.SendEmail(context.Background(), dto.Email{To: []string{"alice@example.com"}, Subject: "Hi", Html: "<p>Hello <i>there</i></p>"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Hello there"}, form["text"])
+
+	err = emailService.SendEmail(context.Background(), dto.Email{To: []string{"alice@example.com"}, Subject: "Hi"})
+	assert.ErrorContains(t, err, "message body cannot be empty")
+}
@@ -4,29 +4,29 @@ import (
 	"context"
 	"testing"
 
+	sen "github.com/lugondev/send-sen"
 	adapter "github.com/lugondev/send-sen/adapters/sms"
 	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/testkit"
 
 	logger "github.com/lugondev/go-log"
-	"github.com/lugondev/send-sen/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBrevoAdapter_SendSMS(t *testing.T) {
-	// Load config and create logger
-	cfg, err := config.LoadConfig("../../config")
-	assert.NoError(t, err, "Failed to load config")
+	fake := testkit.NewBrevo(t)
 
 	log, err := logger.NewLogger(&logger.Option{
-		Format:       cfg.Log.Format,
+		Format:       "console",
 		ScopeName:    "send-sen",
 		ScopeVersion: "v0.1.1",
 	})
-	assert.NoError(t, err, "Failed to create logger")
+	require.NoError(t, err, "Failed to create logger")
 
 	// Create adapter instance
-	brevoAdapter, err := adapter.NewBrevoAdapter(cfg.Brevo, log)
-	assert.NoError(t, err)
+	brevoAdapter, err := adapter.NewBrevoAdapter(fake.Config(), log)
+	require.NoError(t, err)
 
 	sms := dto.SMS{
 		To:      "+84909123456", // Example Vietnamese number
@@ -34,5 +34,16 @@ func TestBrevoAdapter_SendSMS(t *testing.T) {
 	}
 
 	err = brevoAdapter.Send(context.Background(), sms)
-	assert.NoError(t, err)
+	require.NoError(t, err)
+
+	messages := fake.SMS()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "+84909123456", messages[0].Recipient)
+	assert.Equal(t, "SendSen", messages[0].Sender)
+	assert.Equal(t, "Test SMS from Brevo 123123", messages[0].Content)
+
+	err = brevoAdapter.Send(context.Background(), dto.SMS{To: "not-a-number", Message: "Hello"})
+	assert.ErrorIs(t, err, sen.ErrInvalidRecipient)
+	fake.FailNext(testkit.Unavailable())
+	assert.ErrorIs(t, brevoAdapter.Send(context.Background(), sms), sen.ErrTransient)
 }
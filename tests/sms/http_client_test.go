@@ -0,0 +1,75 @@
+package sms_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	adapter "github.com/lugondev/send-sen/adapters/sms"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// markingTransport tags every request so the stub can tell the injected client was used.
+type markingTransport struct{}
+
+func (markingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("X-Injected-Client", "yes")
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestSMSAdapters_HTTPClientAndBaseURL(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "yes", r.Header.Get("X-Injected-Client"))
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/2010-04-01/Accounts/AC123/Messages.json":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"sid":"SM123","status":"queued"}`))
+		case "/transactionalSMS/sms":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"reference":"ab1cde2fgh3i4jklmno","messageId":1511882900176220}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	opts := []adapter.Option{
+		adapter.WithHTTPClient(&http.Client{Transport: markingTransport{}}),
+		adapter.WithBaseURL(server.URL),
+	}
+
+	adapters := []struct {
+		name string
+		new  func() (sen.SMSAdapter, error)
+		path string
+	}{
+		{"twilio", func() (sen.SMSAdapter, error) {
+			return adapter.NewTwilioAdapter(config.TwilioConfig{AccountSid: "AC123", AuthToken: "token", FromNumber: "+15005550006"}, log, opts...)
+		}, "/2010-04-01/Accounts/AC123/Messages.json"},
+		{"brevo", func() (sen.SMSAdapter, error) {
+			return adapter.NewBrevoAdapter(config.BrevoConfig{APIKey: "xkeysib-test", SMSSender: "SendSen", BaseURL: "https://api.brevo.invalid/v3"}, log, opts...)
+		}, "/transactionalSMS/sms"},
+	}
+	for _, a := range adapters {
+		t.Run(a.name, func(t *testing.T) {
+			paths = nil
+			smsAdapter, err := a.new()
+			require.NoError(t, err)
+			err = smsAdapter.Send(context.Background(), dto.SMS{To: "+84909123456", Message: "Hello"})
+			require.NoError(t, err)
+			assert.Equal(t, []string{a.path}, paths)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package sms_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	adapter "github.com/lugondev/send-sen/adapters/sms"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageBirdAdapter_SendSMS(t *testing.T) {
+	var received map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/messages", r.URL.Path)
+		assert.Equal(t, "AccessKey test-key", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"e8077d803532c0b5937c639b60216938","recipients":{"totalCount":1,"totalSentCount":1}}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	messageBirdAdapter, err := adapter.NewMessageBirdAdapter(config.MessageBirdConfig{
+		AccessKey:  "test-key",
+		Originator: "SendSen",
+		BaseURL:    server.URL,
+	}, log)
+	require.NoError(t, err)
+
+	result, err := messageBirdAdapter.SendWithResult(context.Background(), dto.SMS{
+		To:      "+31612345678",
+		Message: "Test SMS from MessageBird",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "e8077d803532c0b5937c639b60216938", result.MessageID)
+	assert.Equal(t, []any{"31612345678"}, received["recipients"])
+	assert.Equal(t, "SendSen", received["originator"])
+	assert.Equal(t, "Test SMS from MessageBird", received["body"])
+}
+
+func TestMessageBirdAdapter_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"errors":[{"code":9,"description":"no (correct) recipients found","parameter":"recipients"}]}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	smsService, err := sen.NewSMSService(config.Config{
+		Adapter: config.AdapterConfig{SMS: config.SMSProviderMessageBird},
+		MessageBird: config.MessageBirdConfig{
+			AccessKey:  "test-key",
+			Originator: "SendSen",
+			BaseURL:    server.URL,
+		},
+	}, log)
+	require.NoError(t, err)
+	assert.Equal(t, "messagebird", smsService.ServiceName())
+
+	err = smsService.SendCode(context.Background(), "+31600000000", "123456")
+	assert.ErrorContains(t, err, "no (correct) recipients found")
+	assert.ErrorContains(t, err, "code 9")
+	assert.ErrorIs(t, err, sen.ErrInvalidRecipient)
+
+	var providerErr *sen.ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, "messagebird", providerErr.Provider)
+	assert.Equal(t, http.StatusUnprocessableEntity, providerErr.StatusCode)
+}
+
+func TestMessageBirdAdapter_NotEnoughBalanceFailsOver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPaymentRequired)
+		_, _ = w.Write([]byte(`{"errors":[{"code":25,"description":"Not enough balance","parameter":null}]}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	cfg := config.MessageBirdConfig{AccessKey: "test-key", Originator: "SendSen", BaseURL: server.URL}
+	messageBirdAdapter, err := adapter.NewMessageBirdAdapter(cfg, log)
+	require.NoError(t, err)
+
+	// An empty account is not the message's fault.
+	err = messageBirdAdapter.Send(context.Background(), dto.SMS{To: "+31600000000", Message: "hello"})
+	assert.ErrorIs(t, err, sen.ErrAuthentication)
+	assert.ErrorContains(t, err, "Not enough balance")
+
+	smsService, err := sen.NewSMSService(config.Config{
+		Adapter:     config.AdapterConfig{SMSChain: []config.SMSProvider{config.SMSProviderMessageBird, config.SMSProviderMock}},
+		MessageBird: cfg,
+	}, log)
+	require.NoError(t, err)
+	result, err := smsService.SendWithResult(context.Background(), dto.SMS{To: "+31600000000", Message: "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "mock", result.Provider)
+}
@@ -0,0 +1,113 @@
+package sms_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	adapter "github.com/lugondev/send-sen/adapters/sms"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSlowServer returns a provider stub that never answers; requests end when
+// the client gives up.
+func newSlowServer(t *testing.T) *httptest.Server {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(func() {
+		close(release)
+		server.Close()
+	})
+	return server
+}
+
+func TestTwilioAdapter_BaseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/twilio/2010-04-01/Accounts/AC123/Messages.json", r.URL.Path)
+		user, password, _ := r.BasicAuth()
+		assert.Equal(t, "AC123", user)
+		assert.Equal(t, "token", password)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "+84909123456", r.PostForm.Get("To"))
+		assert.Equal(t, "+15005550006", r.PostForm.Get("From"))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"SM123","status":"queued"}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	twilioAdapter, err := adapter.NewTwilioAdapter(config.TwilioConfig{
+		AccountSid: "AC123",
+		AuthToken:  "token",
+		FromNumber: "+15005550006",
+		BaseURL:    server.URL + "/twilio",
+	}, log)
+	require.NoError(t, err)
+
+	result, err := twilioAdapter.SendWithResult(context.Background(), dto.SMS{To: "+84909123456", Message: "Hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "SM123", result.MessageID)
+	assert.Equal(t, "queued", result.Status)
+}
+
+func TestSMSAdapters_Cancellation(t *testing.T) {
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	adapters := []struct {
+		name string
+		new  func(t *testing.T, timeout time.Duration) (sen.SMSAdapter, error)
+	}{
+		{"twilio", func(t *testing.T, timeout time.Duration) (sen.SMSAdapter, error) {
+			return adapter.NewTwilioAdapter(config.TwilioConfig{AccountSid: "AC123", AuthToken: "token", FromNumber: "+15005550006", BaseURL: newSlowServer(t).URL, Timeout: timeout}, log)
+		}},
+		{"brevo", func(t *testing.T, timeout time.Duration) (sen.SMSAdapter, error) {
+			return adapter.NewBrevoAdapter(config.BrevoConfig{APIKey: "xkeysib-test", SMSSender: "SendSen", BaseURL: newSlowServer(t).URL, Timeout: timeout}, log)
+		}},
+		{"vonage", func(t *testing.T, timeout time.Duration) (sen.SMSAdapter, error) {
+			return adapter.NewVonageAdapter(config.VonageConfig{APIKey: "key", APISecret: "secret", From: "SendSen", BaseURL: newSlowServer(t).URL, Timeout: timeout}, log)
+		}},
+		{"messagebird", func(t *testing.T, timeout time.Duration) (sen.SMSAdapter, error) {
+			return adapter.NewMessageBirdAdapter(config.MessageBirdConfig{AccessKey: "live_test", Originator: "SendSen", BaseURL: newSlowServer(t).URL, Timeout: timeout}, log)
+		}},
+	}
+	message := dto.SMS{To: "+84909123456", Message: "Hello"}
+
+	for _, a := range adapters {
+		t.Run(a.name+"/cancelled", func(t *testing.T) {
+			smsAdapter, err := a.new(t, time.Minute)
+			require.NoError(t, err)
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+
+			start := time.Now()
+			err = smsAdapter.Send(ctx, message)
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Less(t, time.Since(start), 5*time.Second)
+		})
+		t.Run(a.name+"/timeout", func(t *testing.T) {
+			smsAdapter, err := a.new(t, 50*time.Millisecond)
+			require.NoError(t, err)
+
+			start := time.Now()
+			err = smsAdapter.Send(context.Background(), message)
+			// A provider timeout is transient, unlike the caller's own deadline.
+			assert.ErrorIs(t, err, sen.ErrTransient)
+			assert.NotErrorIs(t, err, context.DeadlineExceeded)
+			assert.Less(t, time.Since(start), 5*time.Second)
+		})
+	}
+}
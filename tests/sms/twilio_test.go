@@ -3,36 +3,68 @@ package sms_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
 	adapter "github.com/lugondev/send-sen/adapters/sms"
 	"github.com/lugondev/send-sen/dto"
+	"github.com/lugondev/send-sen/testkit"
 
-	"github.com/lugondev/send-sen/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTwilioAdapter_SendSMS(t *testing.T) {
-	// Load config and create logger
-	cfg, err := config.LoadConfig("../../config")
-	assert.NoError(t, err, "Failed to load config")
+	fake := testkit.NewTwilio(t)
 
 	log, err := logger.NewLogger(&logger.Option{
-		Format:       cfg.Log.Format,
+		Format:       "console",
 		ScopeName:    "send-sen",
 		ScopeVersion: "v0.1.1",
 	})
-	assert.NoError(t, err, "Failed to create logger")
+	require.NoError(t, err, "Failed to create logger")
 
 	// Create adapter instance
-	twilioAdapter, err := adapter.NewTwilioAdapter(cfg.Twilio, log)
-	assert.NoError(t, err)
+	twilioAdapter, err := adapter.NewTwilioAdapter(fake.Config(), log)
+	require.NoError(t, err)
 
 	sms := dto.SMS{
 		To:      "+18777804236", // Example number
 		Message: "Test SMS from Twilio 123123",
 	}
 
-	err = twilioAdapter.Send(context.Background(), sms)
-	assert.NoError(t, err)
+	result, err := twilioAdapter.SendWithResult(context.Background(), sms)
+	require.NoError(t, err)
+	assert.Equal(t, "queued", result.Status)
+	assert.NotEmpty(t, result.MessageID)
+
+	messages := fake.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "+18777804236", messages[0].Get("To"))
+	assert.Equal(t, "+15005550006", messages[0].Get("From"))
+	assert.Equal(t, "Test SMS from Twilio 123123", messages[0].Get("Body"))
+}
+
+func TestTwilioAdapter_FakeFaults(t *testing.T) {
+	fake := testkit.NewTwilio(t)
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	twilioAdapter, err := adapter.NewTwilioAdapter(fake.Config(), log)
+	require.NoError(t, err)
+	sms := dto.SMS{To: "+18777804236", Message: "Hello"}
+
+	fake.SetRateLimit(1, time.Second)
+	assert.NoError(t, twilioAdapter.Send(context.Background(), sms))
+	assert.ErrorIs(t, twilioAdapter.Send(context.Background(), sms), sen.ErrRateLimited)
+	fake.Reset()
+
+	assert.ErrorIs(t, twilioAdapter.Send(context.Background(), dto.SMS{To: "12", Message: "Hello"}), sen.ErrInvalidRecipient)
+
+	config := fake.Config()
+	config.AuthToken = "wrongtoken"
+	unauthorized, err := adapter.NewTwilioAdapter(config, log)
+	require.NoError(t, err)
+	assert.ErrorIs(t, unauthorized.Send(context.Background(), sms), sen.ErrAuthentication)
+	assert.Empty(t, fake.Messages())
 }
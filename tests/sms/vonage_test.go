@@ -0,0 +1,104 @@
+package sms_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	logger "github.com/lugondev/go-log"
+	sen "github.com/lugondev/send-sen"
+	adapter "github.com/lugondev/send-sen/adapters/sms"
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVonageAdapter_SendSMS(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sms/json", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		_, _ = w.Write([]byte(`{"message-count":"1","messages":[{"to":"84909123456","message-id":"0A0000000123ABCD1","status":"0","remaining-balance":"3.14","message-price":"0.03","network":"45201"}]}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	vonageAdapter, err := adapter.NewVonageAdapter(config.VonageConfig{
+		APIKey:    "key",
+		APISecret: "secret",
+		From:      "SendSen",
+		BaseURL:   server.URL,
+	}, log)
+	require.NoError(t, err)
+
+	result, err := vonageAdapter.SendWithResult(context.Background(), dto.SMS{
+		To:      "+84909123456",
+		Message: "Mã xác thực của bạn là 123456",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "0A0000000123ABCD1", result.MessageID)
+
+	assert.Equal(t, "key", received["api_key"])
+	assert.Equal(t, "secret", received["api_secret"])
+	assert.Equal(t, "SendSen", received["from"])
+	assert.Equal(t, "84909123456", received["to"])
+	assert.Equal(t, "unicode", received["type"])
+}
+
+func TestVonageAdapter_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Vonage reports per-message failures with HTTP 200
+		_, _ = w.Write([]byte(`{"message-count":"1","messages":[{"status":"4","error-text":"Bad Credentials"}]}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+
+	_, err = adapter.NewVonageAdapter(config.VonageConfig{APIKey: "key", APISecret: "secret"}, log)
+	assert.Error(t, err)
+
+	vonageAdapter, err := adapter.NewVonageAdapter(config.VonageConfig{
+		APIKey:    "key",
+		APISecret: "wrong",
+		From:      "SendSen",
+		BaseURL:   server.URL,
+	}, log)
+	require.NoError(t, err)
+
+	err = vonageAdapter.Send(context.Background(), dto.SMS{To: "+84909123456", Message: "hello"})
+	assert.ErrorContains(t, err, "Bad Credentials")
+	assert.ErrorContains(t, err, "code 4")
+	assert.ErrorIs(t, err, sen.ErrAuthentication)
+}
+
+func TestVonageAdapter_OutOfCreditFailsOver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"message-count":"1","messages":[{"status":"9","error-text":"Partner quota exceeded"}]}`))
+	}))
+	defer server.Close()
+
+	log, err := logger.NewLogger(&logger.Option{Format: "console"})
+	require.NoError(t, err)
+	vonageAdapter, err := adapter.NewVonageAdapter(config.VonageConfig{APIKey: "key", APISecret: "secret", From: "SendSen", BaseURL: server.URL}, log)
+	require.NoError(t, err)
+
+	// An empty account is not the message's fault.
+	err = vonageAdapter.Send(context.Background(), dto.SMS{To: "+84909123456", Message: "hello"})
+	assert.ErrorIs(t, err, sen.ErrAuthentication)
+	assert.ErrorContains(t, err, "Partner quota exceeded")
+
+	smsService, err := sen.NewSMSService(config.Config{
+		Adapter: config.AdapterConfig{SMSChain: []config.SMSProvider{config.SMSProviderVonage, config.SMSProviderMock}},
+		Vonage:  config.VonageConfig{APIKey: "key", APISecret: "secret", From: "SendSen", BaseURL: server.URL},
+	}, log)
+	require.NoError(t, err)
+	result, err := smsService.SendWithResult(context.Background(), dto.SMS{To: "+84909123456", Message: "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "mock", result.Provider)
+}
@@ -0,0 +1,92 @@
+package templates_test
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/templates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderLocale_FallbackChain(t *testing.T) {
+	registry, err := templates.New(config.TemplateConfig{ProductName: "Acme"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vi"}, registry.Locales())
+
+	// vi-VN has no translation of its own and falls back to vi.
+	rendered, err := registry.RenderLocale("vi_VN", templates.Welcome, map[string]string{"Name": "An"})
+	require.NoError(t, err)
+	assert.Equal(t, "Chào mừng bạn đến với Acme!", rendered.Subject)
+	assert.Contains(t, rendered.HTML, "<h2>Xin chào An,</h2>")
+	assert.Contains(t, rendered.HTML, "Được gửi bởi Acme")
+
+	// Unknown locales fall back to the root templates.
+	rendered, err = registry.RenderLocale("fr-FR", templates.Welcome, map[string]string{"Name": "Alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "Welcome to Acme!", rendered.Subject)
+}
+
+func TestRenderLocale_DefaultLocale(t *testing.T) {
+	registry, err := templates.New(config.TemplateConfig{Locale: "vi"})
+	require.NoError(t, err)
+
+	rendered, err := registry.Render(templates.VerificationCode, map[string]string{"Code": "123456", "Expiry": "5 phút"})
+	require.NoError(t, err)
+	assert.Equal(t, "Mã xác minh của bạn", rendered.Subject)
+
+	// A requested locale without a translation falls back to the default locale before the root.
+	rendered, err = registry.RenderLocale("fr", templates.VerificationCode, map[string]string{"Code": "123456", "Expiry": "5 phút"})
+	require.NoError(t, err)
+	assert.Equal(t, "Mã xác minh của bạn là: 123456. Mã sẽ hết hạn sau 5 phút.", rendered.Text)
+}
+
+func TestLoad_LocaleOverrides(t *testing.T) {
+	app := fstest.MapFS{
+		// A regional subject only; the bodies come from vi.
+		"locales/vi-VN/messages.json": {Data: []byte(`{"welcome.subject": "Chào {{.Name}}!"}`)},
+		// A new language reusing the root layout.
+		"locales/de/messages.json":        {Data: []byte(`{"welcome.subject": "Willkommen bei {{product}}!"}`)},
+		"locales/de/welcome.html.tmpl":    {Data: []byte(`{{template "layout" .}}{{define "body"}}<p>Hallo {{.Name}}</p>{{end}}`)},
+		"locales/de/welcome.subject.tmpl": {Data: []byte("Hallo {{.Name}}")},
+	}
+	registry, err := templates.Load(templates.Funcs(config.TemplateConfig{}), templates.Builtin(), app)
+	require.NoError(t, err)
+
+	rendered, err := registry.RenderLocale("vi-VN", templates.Welcome, map[string]string{"Name": "An"})
+	require.NoError(t, err)
+	assert.Equal(t, "Chào An!", rendered.Subject)
+	assert.Contains(t, rendered.HTML, "Xin chào An")
+
+	// A subject file wins over the catalog.
+	rendered, err = registry.RenderLocale("de", templates.Welcome, map[string]string{"Name": "Alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "Hallo Alice", rendered.Subject)
+	assert.Contains(t, rendered.HTML, "<p>Hallo Alice</p>")
+	assert.Contains(t, rendered.HTML, "Sent by MyService")
+	assert.Empty(t, rendered.Text)
+
+	_, err = templates.Load(nil, fstest.MapFS{"locales/vi/messages.json": {Data: []byte(`{"welcome.subject": 1}`)}})
+	assert.ErrorContains(t, err, "locales/vi/messages.json")
+}
+
+func TestMessagesAndFormatting(t *testing.T) {
+	registry, err := templates.New(config.TemplateConfig{})
+	require.NoError(t, err)
+
+	text, err := registry.Message("vi", templates.SMSVerificationCode, map[string]string{"Code": "4242", "Expiry": "10 phút"})
+	require.NoError(t, err)
+	assert.Equal(t, "Mã xác minh của bạn là: 4242. Mã sẽ hết hạn sau 10 phút.", text)
+	_, err = registry.Message("vi", "missing", nil)
+	assert.ErrorContains(t, err, `message "missing" not found`)
+
+	at := time.Date(2024, time.March, 5, 14, 30, 0, 0, time.UTC)
+	assert.Equal(t, "March 5, 2024 at 14:30 UTC", registry.FormatTime("", at))
+	assert.Equal(t, "14:30 05/03/2024 (UTC)", registry.FormatTime("vi-VN", at))
+
+	assert.Equal(t, "1 hour 30 minutes", registry.FormatDuration("en", 90*time.Minute))
+	assert.Equal(t, "1 giờ 30 phút", registry.FormatDuration("vi", 90*time.Minute))
+	assert.Equal(t, "1 second", templates.FormatDuration(0))
+}
@@ -0,0 +1,97 @@
+package templates_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/lugondev/send-sen/config"
+	"github.com/lugondev/send-sen/templates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinTemplates(t *testing.T) {
+	registry, err := templates.New(config.TemplateConfig{ProductName: "Acme"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"login_warning", "password_reset", "verification_code", "welcome"}, registry.Names())
+
+	rendered, err := registry.Render(templates.Welcome, map[string]string{"Name": "<Alice>"})
+	require.NoError(t, err)
+	assert.Equal(t, "Welcome to Acme!", rendered.Subject)
+	assert.Contains(t, rendered.HTML, "<h2>Hello &lt;Alice&gt;,</h2>")
+	assert.Contains(t, rendered.HTML, "Sent by Acme") // From the shared layout
+	assert.Equal(t, "Hello <Alice>, Welcome to Acme! Explore our amazing features right now.", rendered.Text)
+
+	rendered, err = registry.Render(templates.VerificationCode, map[string]string{"Code": "123456", "Expiry": "5 minutes"})
+	require.NoError(t, err)
+	assert.Equal(t, "Your verification code is: 123456. This code will expire in 5 minutes.", rendered.Text)
+	assert.Contains(t, rendered.HTML, "<strong>123456</strong>")
+}
+
+func TestLoad_Overrides(t *testing.T) {
+	app := fstest.MapFS{
+		// Replaces the built-in layout and welcome subject; the welcome bodies are kept.
+		"_layout.html.tmpl":    {Data: []byte(`{{define "layout"}}<main>{{template "body" .}}</main>{{end}}`)},
+		"welcome.subject.tmpl": {Data: []byte("Hi {{.Name}}, welcome aboard\n")},
+		// A new template with a shared text partial.
+		"_signature.text.tmpl":         {Data: []byte(`{{define "signature"}}-- The {{product}} team{{end}}`)},
+		"billing/invoice.subject.tmpl": {Data: []byte("Invoice {{.Number}}")},
+		"billing/invoice.text.tmpl":    {Data: []byte("Amount due: {{.Amount}}\n{{template \"signature\"}}")},
+	}
+	registry, err := templates.Load(templates.Funcs(config.TemplateConfig{}), templates.Builtin(), app)
+	require.NoError(t, err)
+
+	rendered, err := registry.Render(templates.Welcome, map[string]string{"Name": "Alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "Hi Alice, welcome aboard", rendered.Subject)
+	assert.True(t, strings.HasPrefix(rendered.HTML, "<main>"), rendered.HTML)
+	assert.Contains(t, rendered.HTML, "Welcome to <b>MyService</b>")
+
+	rendered, err = registry.Render("billing/invoice", map[string]string{"Number": "INV-7", "Amount": "$12.00"})
+	require.NoError(t, err)
+	assert.Equal(t, "Invoice INV-7", rendered.Subject)
+	assert.Empty(t, rendered.HTML)
+	assert.Equal(t, "Amount due: $12.00\n-- The MyService team", rendered.Text)
+
+	_, err = registry.Render("missing", nil)
+	assert.ErrorContains(t, err, `template "missing" not found`)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	_, err := templates.Load(nil, fstest.MapFS{"welcome.html.tmpl": {Data: []byte("<p>Hi</p>")}})
+	assert.ErrorContains(t, err, "missing welcome.subject.tmpl")
+
+	_, err = templates.Load(nil, fstest.MapFS{"welcome.subject.tmpl": {Data: []byte("Hi")}})
+	assert.ErrorContains(t, err, "missing welcome.html.tmpl or welcome.text.tmpl")
+
+	_, err = templates.Load(nil, fstest.MapFS{"welcome.tmpl": {Data: []byte("Hi")}})
+	assert.ErrorContains(t, err, "file name must end in")
+
+	_, err = templates.Load(nil, fstest.MapFS{
+		"welcome.subject.tmpl": {Data: []byte("Hi")},
+		"welcome.text.tmpl":    {Data: []byte("{{.Name")},
+	})
+	assert.ErrorContains(t, err, "template welcome")
+}
+
+func TestLoad_OverrideReplacesEveryPart(t *testing.T) {
+	app := fstest.MapFS{
+		// Only the HTML is overridden; the built-in text must not be mixed in.
+		"welcome.html.tmpl": {Data: []byte(`{{template "layout" .}}{{define "body"}}<p>Hi {{.Name}}</p>{{end}}`)},
+		// Only the text is overridden; the built-in HTML is dropped.
+		"password_reset.text.tmpl": {Data: []byte("Reset link: {{.Link}}")},
+	}
+	registry, err := templates.Load(templates.Funcs(config.TemplateConfig{}), templates.Builtin(), app)
+	require.NoError(t, err)
+
+	rendered, err := registry.Render(templates.Welcome, map[string]string{"Name": "Alice"})
+	require.NoError(t, err)
+	assert.Contains(t, rendered.HTML, "<p>Hi Alice</p>")
+	assert.Empty(t, rendered.Text)
+
+	rendered, err = registry.Render(templates.PasswordReset, map[string]string{"Link": "https://example.com/r"})
+	require.NoError(t, err)
+	assert.Empty(t, rendered.HTML)
+	assert.Equal(t, "Reset link: https://example.com/r", rendered.Text)
+}